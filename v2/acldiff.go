@@ -0,0 +1,201 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ACLDiff is the semantic difference between two policy files, as computed by [DiffACL].
+// Rules are compared by content, so reordering rules or reformatting the policy file does not
+// produce any difference.
+type ACLDiff struct {
+	ACLsAdded     []ACLEntry
+	ACLsRemoved   []ACLEntry
+	GrantsAdded   []ACLGrant
+	GrantsRemoved []ACLGrant
+	SSHAdded      []ACLSSH
+	SSHRemoved    []ACLSSH
+	// Groups and TagOwners describe the changed members of groups and owners of tags, ordered by name.
+	Groups    []ACLMembershipChange
+	TagOwners []ACLMembershipChange
+	// Hosts describes the changed host aliases, ordered by name.
+	Hosts []ACLHostChange
+	// Other lists the JSON names of the other top-level sections that changed, such as "nodeAttrs".
+	Other []string
+}
+
+// ACLMembershipChange describes the changes to the members of a group, or to the owners of a tag.
+type ACLMembershipChange struct {
+	Name    string
+	Added   []string
+	Removed []string
+}
+
+// ACLHostChange describes the change of a host alias. Old is empty for added hosts, and New is
+// empty for removed hosts.
+type ACLHostChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// Empty reports whether the policy files are semantically equal.
+func (d ACLDiff) Empty() bool {
+	return reflect.ValueOf(d).IsZero()
+}
+
+// String formats the difference for humans, with one change per line, prefixed with "+" for
+// additions, "-" for removals and "~" for modifications.
+func (d ACLDiff) String() string {
+	var b strings.Builder
+	line := func(prefix, section string, v any) {
+		j, _ := json.Marshal(v)
+		fmt.Fprintf(&b, "%s %s %s\n", prefix, section, j)
+	}
+	for _, rule := range d.ACLsRemoved {
+		line("-", "acl", rule)
+	}
+	for _, rule := range d.ACLsAdded {
+		line("+", "acl", rule)
+	}
+	for _, rule := range d.GrantsRemoved {
+		line("-", "grant", rule)
+	}
+	for _, rule := range d.GrantsAdded {
+		line("+", "grant", rule)
+	}
+	for _, rule := range d.SSHRemoved {
+		line("-", "ssh", rule)
+	}
+	for _, rule := range d.SSHAdded {
+		line("+", "ssh", rule)
+	}
+	membership := func(section string, changes []ACLMembershipChange) {
+		for _, c := range changes {
+			for _, m := range c.Removed {
+				fmt.Fprintf(&b, "- %s %s %s\n", section, c.Name, m)
+			}
+			for _, m := range c.Added {
+				fmt.Fprintf(&b, "+ %s %s %s\n", section, c.Name, m)
+			}
+		}
+	}
+	membership("group", d.Groups)
+	membership("tagOwner", d.TagOwners)
+	for _, h := range d.Hosts {
+		switch {
+		case h.Old == "":
+			fmt.Fprintf(&b, "+ host %s %s\n", h.Name, h.New)
+		case h.New == "":
+			fmt.Fprintf(&b, "- host %s %s\n", h.Name, h.Old)
+		default:
+			fmt.Fprintf(&b, "~ host %s %s -> %s\n", h.Name, h.Old, h.New)
+		}
+	}
+	for _, section := range d.Other {
+		fmt.Fprintf(&b, "~ %s\n", section)
+	}
+	return b.String()
+}
+
+// DiffACL computes the semantic difference from policy file a to policy file b.
+func DiffACL(a, b ACL) ACLDiff {
+	var d ACLDiff
+	d.ACLsRemoved, d.ACLsAdded = diffRules(a.ACLs, b.ACLs)
+	d.GrantsRemoved, d.GrantsAdded = diffRules(a.Grants, b.Grants)
+	d.SSHRemoved, d.SSHAdded = diffRules(a.SSH, b.SSH)
+	d.Groups = diffMemberships(a.Groups, b.Groups)
+	d.TagOwners = diffMemberships(a.TagOwners, b.TagOwners)
+
+	for _, name := range sortedKeys(a.Hosts, b.Hosts) {
+		if a.Hosts[name] != b.Hosts[name] {
+			d.Hosts = append(d.Hosts, ACLHostChange{Name: name, Old: a.Hosts[name], New: b.Hosts[name]})
+		}
+	}
+
+	handled := []string{"acls", "grants", "ssh", "groups", "tagOwners", "hosts"}
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range av.NumField() {
+		name, _, _ := strings.Cut(av.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || slices.Contains(handled, name) {
+			continue
+		}
+		aj, _ := json.Marshal(av.Field(i).Interface())
+		bj, _ := json.Marshal(bv.Field(i).Interface())
+		if string(aj) != string(bj) {
+			d.Other = append(d.Other, name)
+		}
+	}
+	for _, name := range sortedKeys(a.Unknown, b.Unknown) {
+		if string(a.Unknown[name]) != string(b.Unknown[name]) {
+			d.Other = append(d.Other, name)
+		}
+	}
+	return d
+}
+
+// diffRules returns the rules of a that are not in b, and the rules of b that are not in a,
+// comparing rules by their JSON encoding and accounting for duplicates.
+func diffRules[T any](a, b []T) (removed, added []T) {
+	key := func(v T) string {
+		j, _ := json.Marshal(v)
+		return string(j)
+	}
+	remaining := make(map[string]int)
+	for _, rule := range b {
+		remaining[key(rule)]++
+	}
+	for _, rule := range a {
+		if k := key(rule); remaining[k] > 0 {
+			remaining[k]--
+		} else {
+			removed = append(removed, rule)
+		}
+	}
+	for _, rule := range b {
+		if k := key(rule); remaining[k] > 0 {
+			remaining[k]--
+			added = append(added, rule)
+		}
+	}
+	return removed, added
+}
+
+func diffMemberships(a, b map[string][]string) []ACLMembershipChange {
+	var changes []ACLMembershipChange
+	for _, name := range sortedKeys(a, b) {
+		change := ACLMembershipChange{Name: name}
+		for _, m := range a[name] {
+			if !slices.Contains(b[name], m) {
+				change.Removed = append(change.Removed, m)
+			}
+		}
+		for _, m := range b[name] {
+			if !slices.Contains(a[name], m) {
+				change.Added = append(change.Added, m)
+			}
+		}
+		if change.Added != nil || change.Removed != nil {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// sortedKeys returns the keys of every map of ms, sorted and without duplicates.
+func sortedKeys[V any](ms ...map[string]V) []string {
+	var keys []string
+	for _, m := range ms {
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestDiffACL(t *testing.T) {
+	t.Parallel()
+
+	devAccess := tsclient.ACLEntry{Action: "accept", Source: []string{"group:dev"}, Destination: []string{"tag:dev:*"}}
+	prodAccess := tsclient.ACLEntry{Action: "accept", Source: []string{"group:ops"}, Destination: []string{"tag:prod:*"}}
+	a := tsclient.ACL{
+		ACLs:      []tsclient.ACLEntry{devAccess, prodAccess},
+		Groups:    map[string][]string{"group:dev": {"alice@example.com", "bob@example.com"}, "group:ops": {"carl@example.com"}},
+		TagOwners: map[string][]string{"tag:dev": {"group:dev"}},
+		Hosts:     map[string]string{"db": "10.0.0.1", "old": "10.0.0.2"},
+		ETag:      "a",
+	}
+	b := tsclient.ACL{
+		ACLs:      []tsclient.ACLEntry{prodAccess},
+		Grants:    []tsclient.ACLGrant{{Source: []string{"group:dev"}, Destination: []string{"tag:dev"}, IP: []string{"*"}}},
+		Groups:    map[string][]string{"group:dev": {"bob@example.com", "dave@example.com"}, "group:ops": {"carl@example.com"}},
+		TagOwners: map[string][]string{"tag:dev": {"group:dev"}, "tag:prod": {"group:ops"}},
+		Hosts:     map[string]string{"db": "10.0.0.3", "new": "10.0.0.4"},
+		NodeAttrs: []tsclient.NodeAttrGrant{{Target: []string{"*"}, Attr: []string{"funnel"}}},
+		ETag:      "b",
+	}
+
+	diff := tsclient.DiffACL(a, b)
+	assert.Equal(t, tsclient.ACLDiff{
+		ACLsRemoved: []tsclient.ACLEntry{devAccess},
+		GrantsAdded: b.Grants,
+		Groups:      []tsclient.ACLMembershipChange{{Name: "group:dev", Added: []string{"dave@example.com"}, Removed: []string{"alice@example.com"}}},
+		TagOwners:   []tsclient.ACLMembershipChange{{Name: "tag:prod", Added: []string{"group:ops"}}},
+		Hosts: []tsclient.ACLHostChange{
+			{Name: "db", Old: "10.0.0.1", New: "10.0.0.3"},
+			{Name: "new", New: "10.0.0.4"},
+			{Name: "old", Old: "10.0.0.2"},
+		},
+		Other: []string{"nodeAttrs"},
+	}, diff)
+	assert.Equal(t, `- acl {"action":"accept","src":["group:dev"],"dst":["tag:dev:*"]}
++ grant {"src":["group:dev"],"dst":["tag:dev"],"ip":["*"]}
+- group group:dev alice@example.com
++ group group:dev dave@example.com
++ tagOwner tag:prod group:ops
+~ host db 10.0.0.1 -> 10.0.0.3
++ host new 10.0.0.4
+- host old 10.0.0.2
+~ nodeAttrs
+`, diff.String())
+
+	reordered := a
+	reordered.ACLs = []tsclient.ACLEntry{prodAccess, devAccess}
+	assert.True(t, tsclient.DiffACL(a, reordered).Empty())
+}
@@ -0,0 +1,134 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrNoAsyncStatus is returned by [AsyncOperation.Wait] when the completion of an operation cannot
+// be determined, because the API does not expose its status and no status function was given.
+var ErrNoAsyncStatus = errors.New("the API does not expose the status of this operation")
+
+// ResponseInfo describes the HTTP response to a request made by the [Client].
+type ResponseInfo struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// Accepted reports whether the API accepted the request for asynchronous processing, meaning that
+// the requested operation may not have completed yet.
+func (ri *ResponseInfo) Accepted() bool {
+	return ri.StatusCode == http.StatusAccepted
+}
+
+type responseInfoKey struct{}
+
+// WithResponseInfo returns a copy of ctx that causes the [Client] to record the [ResponseInfo] of
+// requests made with it into info. If several requests are made with the returned context, info
+// describes the most recent one.
+func WithResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
+	return context.WithValue(ctx, responseInfoKey{}, info)
+}
+
+func recordResponseInfo(ctx context.Context, res *http.Response) {
+	if info, ok := ctx.Value(responseInfoKey{}).(*ResponseInfo); ok {
+		info.StatusCode = res.StatusCode
+		info.Header = res.Header
+	}
+}
+
+// Poll calls condition every interval until it reports that it is done or returns an error,
+// or until ctx is done. It is intended for waiting on the completion of asynchronous operations.
+// condition is called immediately, without waiting for the first interval to elapse.
+func Poll(ctx context.Context, interval time.Duration, condition func(ctx context.Context) (done bool, err error)) error {
+	for {
+		done, err := condition(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// AsyncOperation is a handle to an operation that the API may complete asynchronously, as indicated
+// by a 202 Accepted response. Create one using [Client.StartAsync].
+type AsyncOperation struct {
+	// Response describes the response to the request that started the operation.
+	Response ResponseInfo
+
+	client *Client
+}
+
+// StartAsync calls call with a context that records the response to the request it makes, and
+// returns a handle to the resulting operation. call should make a single request using the
+// [Client], for example:
+//
+//	op, err := client.StartAsync(ctx, func(ctx context.Context) error {
+//		return client.Webhooks().Test(ctx, endpointID)
+//	})
+func (c *Client) StartAsync(ctx context.Context, call func(ctx context.Context) error) (*AsyncOperation, error) {
+	c.init()
+	op := &AsyncOperation{client: c}
+	if err := call(WithResponseInfo(ctx, &op.Response)); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// Done reports whether the operation had already completed when the API responded, meaning that
+// the response was not 202 Accepted.
+func (op *AsyncOperation) Done() bool {
+	return !op.Response.Accepted()
+}
+
+// Wait waits for the operation to complete, calling status every interval to check whether it has.
+// If status is nil and the API returned a Location header, Wait polls that URL until it no longer
+// responds with 202 Accepted. Locations that are not on the API server are rejected. If the API
+// exposes no status, Wait returns [ErrNoAsyncStatus]. Wait returns immediately if the operation is
+// already [AsyncOperation.Done].
+func (op *AsyncOperation) Wait(ctx context.Context, interval time.Duration, status func(ctx context.Context) (done bool, err error)) error {
+	if op.Done() {
+		return nil
+	}
+	if status == nil {
+		location := op.Response.Header.Get("Location")
+		if location == "" {
+			return ErrNoAsyncStatus
+		}
+		status = op.locationStatus(location)
+	}
+	return Poll(ctx, interval, status)
+}
+
+func (op *AsyncOperation) locationStatus(location string) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		u, err := op.client.BaseURL.Parse(location)
+		if err != nil {
+			return false, err
+		}
+		// The request carries the credentials of the client, so only send it to the API server.
+		if u.Scheme != op.client.BaseURL.Scheme || u.Host != op.client.BaseURL.Host {
+			return false, fmt.Errorf("refusing to poll operation status at %q, which is not on the API server", location)
+		}
+		var info ResponseInfo
+		req, err := op.client.buildRequest(WithResponseInfo(ctx, &info), http.MethodGet, u)
+		if err != nil {
+			return false, err
+		}
+		if err := op.client.do(req, nil); err != nil {
+			return false, err
+		}
+		return !info.Accepted(), nil
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestWithResponseInfo(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseHeader.Add("X-Test", "value")
+	server.ResponseBody = []byte(`{"id":"test"}`)
+
+	var info tsclient.ResponseInfo
+	_, err := client.Devices().Get(tsclient.WithResponseInfo(context.Background(), &info), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, info.StatusCode)
+	assert.Equal(t, "value", info.Header.Get("X-Test"))
+	assert.False(t, info.Accepted())
+}
+
+func TestPoll(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := tsclient.Poll(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	expectedErr := errors.New("failed")
+	err = tsclient.Poll(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+		return false, expectedErr
+	})
+	assert.ErrorIs(t, err, expectedErr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = tsclient.Poll(ctx, time.Millisecond, func(ctx context.Context) (bool, error) {
+		return false, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_StartAsync(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusAccepted
+	server.ResponseHeader.Set("Location", "/api/v2/operations/1")
+
+	op, err := client.StartAsync(context.Background(), func(ctx context.Context) error {
+		return client.Webhooks().Test(ctx, "54321")
+	})
+	assert.NoError(t, err)
+	assert.False(t, op.Done())
+	assert.Equal(t, "/api/v2/webhooks/54321/test", server.Path)
+
+	// The operation completes once its status stops responding with 202 Accepted.
+	server.ResponseCode = http.StatusOK
+	assert.NoError(t, op.Wait(context.Background(), time.Millisecond, nil))
+	assert.Equal(t, http.MethodGet, server.Method)
+	assert.Equal(t, "/api/v2/operations/1", server.Path)
+}
+
+func TestAsyncOperation_WaitForeignLocation(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusAccepted
+	server.ResponseHeader.Set("Location", "https://attacker.example.net/api/v2/operations/1")
+
+	op, err := client.StartAsync(context.Background(), func(ctx context.Context) error {
+		return client.Webhooks().Test(ctx, "54321")
+	})
+	require.NoError(t, err)
+	assert.ErrorContains(t, op.Wait(context.Background(), time.Millisecond, nil), "not on the API server")
+	assert.Equal(t, "/api/v2/webhooks/54321/test", server.Path)
+}
+
+func TestAsyncOperation_Wait(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusAccepted
+
+	op, err := client.StartAsync(context.Background(), func(ctx context.Context) error {
+		return client.Webhooks().Test(ctx, "54321")
+	})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, op.Wait(context.Background(), time.Millisecond, nil), tsclient.ErrNoAsyncStatus)
+
+	calls := 0
+	err = op.Wait(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+		calls++
+		return calls == 2, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	server.ResponseCode = http.StatusOK
+	op, err = client.StartAsync(context.Background(), func(ctx context.Context) error {
+		return client.Webhooks().Test(ctx, "54321")
+	})
+	assert.NoError(t, err)
+	assert.True(t, op.Done())
+	assert.NoError(t, op.Wait(context.Background(), time.Millisecond, nil))
+}
@@ -0,0 +1,166 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultBatchConcurrency = 10
+const defaultBatchRetryBackoff = time.Second
+const batchMaxRetries = 3
+
+// BatchOptions configures the behavior of [Batch].
+type BatchOptions struct {
+	// Concurrency is the maximum number of calls in flight at any time. Defaults to 10.
+	Concurrency int
+	// Interval is the minimum time between the start of two calls, shared across all
+	// concurrent calls. Zero means that calls are only limited by Concurrency.
+	Interval time.Duration
+	// RetryBackoff is the time to wait before retrying a call that was rejected because of
+	// rate limiting. It is doubled after every retry. Defaults to 1 second.
+	RetryBackoff time.Duration
+}
+
+// BatchError is returned by [Batch] when one or more calls failed.
+type BatchError struct {
+	// Errors maps the index of each failed item to the error returned for it.
+	Errors map[int]error
+}
+
+func (err BatchError) Error() string {
+	indexes := err.indexes()
+	if len(indexes) == 0 {
+		return "batch failed"
+	}
+	first := indexes[0]
+	return fmt.Sprintf("%d batch operation(s) failed; item %d: %v", len(indexes), first, err.Errors[first])
+}
+
+// Unwrap returns the errors of the failed items, ordered by item index.
+func (err BatchError) Unwrap() []error {
+	indexes := err.indexes()
+	errs := make([]error, 0, len(indexes))
+	for _, i := range indexes {
+		errs = append(errs, err.Errors[i])
+	}
+	return errs
+}
+
+func (err BatchError) indexes() []int {
+	indexes := make([]int, 0, len(err.Errors))
+	for i := range err.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// Batch calls fn for every item with bounded concurrency, and returns a [BatchError] describing
+// every item for which fn failed, or nil if all calls succeeded.
+//
+// Calls that fail because the API rate limited them (see [IsRateLimited]) are retried up to 3
+// times with exponential backoff. If ctx is done, items that have not been started yet fail with
+// the context's error.
+func Batch[T any](ctx context.Context, items []T, opts BatchOptions, fn func(ctx context.Context, item T) error) error {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultBatchRetryBackoff
+	}
+	limiter := &batchLimiter{interval: opts.Interval}
+
+	var mu sync.Mutex
+	errs := make(map[int]error)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := batchCall(ctx, limiter, backoff, items[i], fn); err != nil {
+					mu.Lock()
+					errs[i] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return BatchError{Errors: errs}
+	}
+	return nil
+}
+
+func batchCall[T any](ctx context.Context, limiter *batchLimiter, backoff time.Duration, item T, fn func(ctx context.Context, item T) error) error {
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+		err := fn(ctx, item)
+		if err == nil || !IsRateLimited(err) || attempt == batchMaxRetries {
+			return err
+		}
+		if err := sleep(ctx, backoff<<attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// batchLimiter spaces out calls so that at most one starts per interval.
+type batchLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *batchLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, start.Sub(now))
+}
+
+// sleep waits for the given duration, returning early with the context's error if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	var inFlight, maxInFlight atomic.Int32
+	err := tsclient.Batch(context.Background(), items, tsclient.BatchOptions{Concurrency: 3}, func(ctx context.Context, item int) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if item%2 == 1 {
+			return errors.New("odd")
+		}
+		return nil
+	})
+
+	var batchErr tsclient.BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 5)
+	for i := range items {
+		_, failed := batchErr.Errors[i]
+		assert.Equal(t, i%2 == 1, failed)
+	}
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.EqualError(t, err, "5 batch operation(s) failed; item 1: odd")
+}
+
+func TestBatch_Success(t *testing.T) {
+	t.Parallel()
+
+	err := tsclient.Batch(context.Background(), []string{"a", "b"}, tsclient.BatchOptions{Interval: time.Millisecond}, func(ctx context.Context, item string) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestBatch_RetriesRateLimited(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusTooManyRequests
+	server.ResponseBody = tsclient.APIError{Message: "rate limited"}
+
+	var calls atomic.Int32
+	err := tsclient.Batch(context.Background(), []string{"test"}, tsclient.BatchOptions{RetryBackoff: time.Millisecond}, func(ctx context.Context, id string) error {
+		calls.Add(1)
+		return client.Devices().Delete(ctx, id)
+	})
+	assert.True(t, tsclient.IsRateLimited(err))
+	assert.EqualValues(t, 4, calls.Load())
+}
@@ -0,0 +1,83 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// BulkResult maps the ID of every device or key of a bulk operation to the error of the operation
+// on it, or to nil if it succeeded.
+type BulkResult map[string]error
+
+// Failed returns the IDs for which the operation failed, in ascending order.
+func (r BulkResult) Failed() []string {
+	var failed []string
+	for id, err := range r {
+		if err != nil {
+			failed = append(failed, id)
+		}
+	}
+	slices.Sort(failed)
+	return failed
+}
+
+// BulkSetAuthorized authorizes or deauthorizes every device in deviceIDs. Devices are updated
+// concurrently as configured by opts; see [Batch].
+func (dr *DevicesResource) BulkSetAuthorized(ctx context.Context, deviceIDs []string, authorized bool, opts BatchOptions) BulkResult {
+	return bulk(ctx, deviceIDs, opts, func(ctx context.Context, deviceID string) error {
+		return dr.SetAuthorized(ctx, deviceID, authorized)
+	})
+}
+
+// BulkSetTags sets the tags of every device in tags, which maps device IDs to their new tags.
+// Devices are updated concurrently as configured by opts; see [Batch].
+func (dr *DevicesResource) BulkSetTags(ctx context.Context, tags map[string][]string, opts BatchOptions) BulkResult {
+	deviceIDs := make([]string, 0, len(tags))
+	for deviceID := range tags {
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+	slices.Sort(deviceIDs)
+	return bulk(ctx, deviceIDs, opts, func(ctx context.Context, deviceID string) error {
+		return dr.SetTags(ctx, deviceID, tags[deviceID])
+	})
+}
+
+// BulkDelete deletes every device in deviceIDs, once per distinct ID. Devices are deleted
+// concurrently as configured by opts; see [Batch].
+func (dr *DevicesResource) BulkDelete(ctx context.Context, deviceIDs []string, opts BatchOptions) BulkResult {
+	return bulk(ctx, deviceIDs, opts, func(ctx context.Context, deviceID string) error {
+		return dr.Delete(ctx, deviceID)
+	})
+}
+
+// DeleteMany deletes every key in ids, once per distinct ID. Keys are deleted concurrently as
+// configured by opts; see [Batch].
+func (kr *KeysResource) DeleteMany(ctx context.Context, ids []string, opts BatchOptions) BulkResult {
+	return bulk(ctx, ids, opts, func(ctx context.Context, id string) error {
+		return kr.Delete(ctx, id)
+	})
+}
+
+// bulk calls fn for every distinct ID in ids using [Batch], and returns the result keyed by ID.
+func bulk(ctx context.Context, ids []string, opts BatchOptions, fn func(ctx context.Context, id string) error) BulkResult {
+	seen := make(map[string]bool, len(ids))
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool {
+		duplicate := seen[id]
+		seen[id] = true
+		return duplicate
+	})
+
+	err := Batch(ctx, ids, opts, fn)
+	var batchErr BatchError
+	errors.As(err, &batchErr)
+
+	result := make(BulkResult, len(ids))
+	for i, id := range ids {
+		result[id] = batchErr.Errors[i]
+	}
+	return result
+}
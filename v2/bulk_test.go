@@ -0,0 +1,66 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Devices_Bulk(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	ctx := context.Background()
+
+	result := client.Devices().BulkSetAuthorized(ctx, []string{"1", "2"}, true, tsclient.BatchOptions{})
+	assert.Equal(t, tsclient.BulkResult{"1": nil, "2": nil}, result)
+	assert.Empty(t, result.Failed())
+	assert.Equal(t, http.MethodPost, server.Method)
+
+	result = client.Devices().BulkSetTags(ctx, map[string][]string{"1": {"tag:a"}}, tsclient.BatchOptions{})
+	assert.Empty(t, result.Failed())
+	assert.Equal(t, "/api/v2/device/1/tags", server.Path)
+	assert.JSONEq(t, `{"tags":["tag:a"]}`, server.Body.String())
+
+	server.ResponseCode = http.StatusNotFound
+	server.ResponseBody = map[string]string{"message": "not found"}
+	result = client.Devices().BulkDelete(ctx, []string{"1", "2"}, tsclient.BatchOptions{})
+	assert.Equal(t, []string{"1", "2"}, result.Failed())
+	assert.True(t, tsclient.IsNotFound(result["1"]))
+	assert.Equal(t, http.MethodDelete, server.Method)
+}
+
+func TestClient_Keys_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	client, server := newKeyServer(t, tsclient.Key{ID: "a"}, tsclient.Key{ID: "b"}, tsclient.Key{ID: "c"})
+
+	result := client.Keys().DeleteMany(context.Background(), []string{"a", "b", "missing"}, tsclient.BatchOptions{Concurrency: 2})
+	assert.Equal(t, []string{"missing"}, result.Failed())
+	assert.NoError(t, result["a"])
+	assert.True(t, tsclient.IsNotFound(result["missing"]))
+
+	_, ok := server.key("a")
+	assert.False(t, ok)
+	_, ok = server.key("c")
+	assert.True(t, ok)
+}
+
+func TestClient_Keys_DeleteManyDuplicates(t *testing.T) {
+	t.Parallel()
+
+	client, server := newKeyServer(t, tsclient.Key{ID: "a"})
+
+	result := client.Keys().DeleteMany(context.Background(), []string{"a", "a"}, tsclient.BatchOptions{})
+	assert.Equal(t, tsclient.BulkResult{"a": nil}, result)
+	_, ok := server.key("a")
+	assert.False(t, ok)
+}
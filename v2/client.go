@@ -8,6 +8,7 @@ package tsclient
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -15,6 +16,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,25 +30,49 @@ type Client struct {
 	BaseURL *url.URL
 	// UserAgent configures the User-Agent HTTP header for requests. Defaults to "tailscale-client-go".
 	UserAgent string
+	// UserAgentSuffix is optionally appended to UserAgent, separated by a space, allowing applications
+	// to identify themselves (e.g. "myapp/1.2") while keeping the default User-Agent.
+	UserAgentSuffix string
 	// APIKey allows specifying an APIKey to use for authentication.
 	// To use OAuth Client credentials, construct an [http.Client] using [OAuthConfig] and specify that below.
 	APIKey string
 	// Tailnet allows specifying a specific Tailnet by name, to which this Client will connect by default.
+	// Defaults to "-", which the API resolves to the tailnet that owns the API key or OAuth client.
+	//
+	// API keys and OAuth clients are always scoped to a single tailnet, so "-" is sufficient to
+	// address it without knowing its name. Use [Client.ResolveTailnet] to learn it.
 	Tailnet string
 
 	// HTTP is the [http.Client] to use for requests to the API server.
-	// If not specified, a new [http.Client] with a Timeout of 1 minute will be used.
+	// If not specified, a new [http.Client] will be used.
 	HTTP *http.Client
 
+	// Timeouts configures the timeouts of requests to the API server, optionally per resource.
+	// If Timeouts is set, or HTTP is not, requests time out after 1 minute by default. Otherwise
+	// requests are only limited by the Timeout of HTTP.
+	Timeouts Timeouts
+
+	// MutationGuard is an optional [MutationGuard] evaluated before every mutating request,
+	// for example to prevent automation from accidentally modifying a production tailnet.
+	MutationGuard MutationGuard
+
+	// RequirePolicyETag makes [PolicyFileResource.Set] refuse to set the policy file without an
+	// ETag, unless [WithForce] is passed, so that automation does not overwrite concurrent edits.
+	RequirePolicyETag bool
+
 	initOnce sync.Once
+	// ownsHTTP is true if HTTP was created by the Client rather than the caller.
+	ownsHTTP bool
 
 	// Specific resources
 	contacts        *ContactsResource
+	deviceInvites   *DeviceInvitesResource
 	devicePosture   *DevicePostureResource
 	devices         *DevicesResource
 	dns             *DNSResource
 	keys            *KeysResource
 	logging         *LoggingResource
+	oauthClients    *OAuthClientsResource
 	policyFile      *PolicyFileResource
 	tailnetSettings *TailnetSettingsResource
 	users           *UsersResource
@@ -65,8 +92,13 @@ type APIErrorData struct {
 	Errors []string `json:"errors"`
 }
 
+// ErrNotModified is returned when the API responds to a conditional request with 304 Not Modified,
+// meaning that the requested resource has not changed since the version known to the caller.
+var ErrNotModified = errors.New("resource not modified")
+
 const defaultContentType = "application/json"
 const defaultHttpClientTimeout = time.Minute
+const defaultTailnet = "-"
 const defaultUserAgent = "tailscale-client-go"
 
 var defaultBaseURL *url.URL
@@ -90,14 +122,18 @@ func (c *Client) init() {
 			c.UserAgent = defaultUserAgent
 		}
 		if c.HTTP == nil {
-			c.HTTP = &http.Client{Timeout: defaultHttpClientTimeout}
+			// Timeouts are applied per request, see Timeouts.
+			c.HTTP = &http.Client{}
+			c.ownsHTTP = true
 		}
 		c.contacts = &ContactsResource{c}
+		c.deviceInvites = &DeviceInvitesResource{c}
 		c.devicePosture = &DevicePostureResource{c}
 		c.devices = &DevicesResource{c}
 		c.dns = &DNSResource{c}
 		c.keys = &KeysResource{c}
 		c.logging = &LoggingResource{c}
+		c.oauthClients = &OAuthClientsResource{c}
 		c.policyFile = &PolicyFileResource{c}
 		c.tailnetSettings = &TailnetSettingsResource{c}
 		c.users = &UsersResource{c}
@@ -111,6 +147,12 @@ func (c *Client) Contacts() *ContactsResource {
 	return c.contacts
 }
 
+// DeviceInvites provides access to https://tailscale.com/api#tag/deviceinvites.
+func (c *Client) DeviceInvites() *DeviceInvitesResource {
+	c.init()
+	return c.deviceInvites
+}
+
 // DevicePosture provides access to https://tailscale.com/api#tag/deviceposture.
 func (c *Client) DevicePosture() *DevicePostureResource {
 	c.init()
@@ -141,6 +183,12 @@ func (c *Client) Logging() *LoggingResource {
 	return c.logging
 }
 
+// OAuthClients provides access to the OAuth clients of the tailnet, see [OAuthClientsResource].
+func (c *Client) OAuthClients() *OAuthClientsResource {
+	c.init()
+	return c.oauthClients
+}
+
 // PolicyFile provides access to https://tailscale.com/api#tag/policyfile.
 func (c *Client) PolicyFile() *PolicyFileResource {
 	c.init()
@@ -165,8 +213,29 @@ func (c *Client) Webhooks() *WebhooksResource {
 	return c.webhooks
 }
 
+// ForTailnet returns a shallow copy of the Client that operates on the given tailnet.
+// The returned Client shares its [http.Client], and thus its transport and any OAuth
+// token source, with the original, making it cheap to manage many tailnets from one process.
+func (c *Client) ForTailnet(tailnet string) *Client {
+	c.init()
+	return &Client{
+		BaseURL:           c.BaseURL,
+		UserAgent:         c.UserAgent,
+		UserAgentSuffix:   c.UserAgentSuffix,
+		APIKey:            c.APIKey,
+		Tailnet:           tailnet,
+		HTTP:              c.HTTP,
+		ownsHTTP:          c.ownsHTTP,
+		Timeouts:          c.Timeouts,
+		MutationGuard:     c.MutationGuard,
+		RequirePolicyETag: c.RequirePolicyETag,
+	}
+}
+
 type requestParams struct {
+	ctx         context.Context
 	headers     map[string]string
+	query       url.Values
 	body        any
 	contentType string
 }
@@ -185,6 +254,15 @@ func requestHeaders(headers map[string]string) requestOption {
 	}
 }
 
+func requestQuery(key, value string) requestOption {
+	return func(rof *requestParams) {
+		if rof.query == nil {
+			rof.query = make(url.Values)
+		}
+		rof.query.Set(key, value)
+	}
+}
+
 func requestContentType(ct string) requestOption {
 	return func(rof *requestParams) {
 		rof.contentType = ct
@@ -208,17 +286,54 @@ func (c *Client) buildTailnetURL(pathElements ...any) *url.URL {
 	allElements := make([]any, 2, len(pathElements)+2)
 	allElements[0] = "tailnet"
 	allElements[1] = c.Tailnet
+	if c.Tailnet == "" {
+		allElements[1] = defaultTailnet
+	}
 	allElements = append(allElements, pathElements...)
 	return c.buildURL(allElements...)
 }
 
+// BuildURL builds a URL to /api/v2/... using the given path elements, escaping each of them.
+// Together with [Client.NewRequest] and [Client.Do], it allows calling endpoints that are not
+// supported by the Client yet.
+func (c *Client) BuildURL(pathElements ...any) *url.URL {
+	c.init()
+	return c.buildURL(pathElements...)
+}
+
+// BuildTailnetURL builds a URL to /api/v2/tailnet/<tailnet>/... for the tailnet of the Client
+// using the given path elements, escaping each of them.
+func (c *Client) BuildTailnetURL(pathElements ...any) *url.URL {
+	c.init()
+	return c.buildTailnetURL(pathElements...)
+}
+
+// NewRequest builds a request to uri, authenticated and configured like the requests made by the
+// resources of the Client. If body is not nil, it is sent as JSON.
+func (c *Client) NewRequest(ctx context.Context, method string, uri *url.URL, body any) (*http.Request, error) {
+	c.init()
+	if body == nil {
+		return c.buildRequest(ctx, method, uri)
+	}
+	return c.buildRequest(ctx, method, uri, requestBody(body))
+}
+
+// Do sends a request built by [Client.NewRequest], decoding the response body into out unless it
+// is nil. Like for the resources of the Client, error responses are returned as an [APIError].
+func (c *Client) Do(req *http.Request, out any) error {
+	c.init()
+	return c.do(req, out)
+}
+
 func (c *Client) buildRequest(ctx context.Context, method string, uri *url.URL, opts ...requestOption) (*http.Request, error) {
 	rof := &requestParams{
+		ctx:         ctx,
 		contentType: defaultContentType,
 	}
 	for _, opt := range opts {
 		opt(rof)
 	}
+	ctx = rof.ctx
 
 	var bodyBytes []byte
 	if rof.body != nil {
@@ -236,13 +351,27 @@ func (c *Client) buildRequest(ctx context.Context, method string, uri *url.URL,
 		}
 	}
 
+	if len(rof.query) > 0 {
+		u := *uri
+		q := u.Query()
+		for k, v := range rof.query {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+		uri = &u
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, uri.String(), bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		return nil, err
 	}
 
-	if c.UserAgent != "" {
-		req.Header.Set("User-Agent", c.UserAgent)
+	if ua := strings.TrimSpace(c.UserAgent + " " + c.UserAgentSuffix); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+
+	if id := CorrelationID(ctx); id != "" {
+		req.Header.Set(CorrelationIDHeader, id)
 	}
 
 	for k, v := range rof.headers {
@@ -279,6 +408,34 @@ func body[T any](resource doer, req *http.Request) (*T, error) {
 	return t, err
 }
 
+// sortByID sorts the items of a list response by the identifier returned by id. The API does not
+// guarantee the order of list responses, so lists are sorted to keep results deterministic.
+// Numeric identifiers are ordered by value and before all others, which are ordered as strings.
+func sortByID[T any](items []T, id func(T) string) []T {
+	slices.SortStableFunc(items, func(a, b T) int { return compareIDs(id(a), id(b)) })
+	return items
+}
+
+// compareIDs compares the identifiers a and b in the order described for [sortByID].
+func compareIDs(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	switch {
+	case aNumeric && !bNumeric:
+		return -1
+	case !aNumeric && bNumeric:
+		return 1
+	case aNumeric && bNumeric:
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumeric(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+}
+
 // bodyWithResponseHeader is like [body] but also returns the response header.
 func bodyWithResponseHeader[T any](resource doer, req *http.Request) (*T, http.Header, error) {
 	var v T
@@ -295,7 +452,18 @@ func (c *Client) do(req *http.Request, out any) error {
 }
 
 func (c *Client) doWithResponseHeaders(req *http.Request, out any) (http.Header, error) {
-	res, err := c.HTTP.Do(req)
+	if err := c.checkMutationGuard(req); err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	if timeout := c.timeoutFor(req.URL); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res, err := c.HTTP.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -305,8 +473,11 @@ func (c *Client) doWithResponseHeaders(req *http.Request, out any) (http.Header,
 	if err != nil {
 		return nil, err
 	}
+	recordResponseInfo(req.Context(), res)
 
 	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		recordRawResponse(req.Context(), body)
+
 		// If we don't care about the response body, leave. This check is required as some
 		// API responses have empty bodies, so we don't want to try and standardize them for
 		// parsing.
@@ -322,15 +493,22 @@ func (c *Client) doWithResponseHeaders(req *http.Request, out any) (http.Header,
 
 		// If we've got hujson back, convert it to JSON, so we can natively parse it.
 		if !json.Valid(body) {
+			if strictJSON(req.Context()) {
+				return res.Header, ResponseContentError{ContentType: res.Header.Get("Content-Type"), Err: errors.New("response is not valid JSON")}
+			}
 			body, err = hujson.Standardize(body)
 			if err != nil {
-				return res.Header, err
+				return res.Header, ResponseContentError{ContentType: res.Header.Get("Content-Type"), Err: err}
 			}
 		}
 
 		return res.Header, json.Unmarshal(body, out)
 	}
 
+	if res.StatusCode == http.StatusNotModified {
+		return res.Header, ErrNotModified
+	}
+
 	if res.StatusCode >= http.StatusBadRequest {
 		var apiErr APIError
 		if err := json.Unmarshal(body, &apiErr); err != nil {
@@ -344,12 +522,33 @@ func (c *Client) doWithResponseHeaders(req *http.Request, out any) (http.Header,
 	return res.Header, nil
 }
 
+// ResponseContentError is returned when a successful response could not be parsed as JSON or HuJSON,
+// or as JSON alone when using [WithStrictJSON].
+type ResponseContentError struct {
+	// ContentType is the Content-Type header of the response.
+	ContentType string
+	Err         error
+}
+
+func (err ResponseContentError) Error() string {
+	return fmt.Sprintf("unexpected response content (Content-Type %q): %v", err.ContentType, err.Err)
+}
+
+func (err ResponseContentError) Unwrap() error {
+	return err.Err
+}
+
 func (err APIError) Error() string {
 	return fmt.Sprintf("%s (%v)", err.Message, err.status)
 }
 
-// IsNotFound returns true if the provided error implementation is an APIError with a status of 404.
+// IsNotFound returns true if the provided error implementation is an APIError with a status of 404,
+// or is [ErrDeviceNotFound].
 func IsNotFound(err error) bool {
+	if errors.Is(err, ErrDeviceNotFound) {
+		return true
+	}
+
 	var apiErr APIError
 	if errors.As(err, &apiErr) {
 		return apiErr.status == http.StatusNotFound
@@ -358,6 +557,27 @@ func IsNotFound(err error) bool {
 	return false
 }
 
+// IsRateLimited returns true if the provided error implementation is an APIError with a status of 429.
+func IsRateLimited(err error) bool {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.status == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// IsPreconditionFailed returns true if the provided error implementation is an APIError with a status of 412,
+// as returned when the ETag given to a conditional update no longer matches the resource.
+func IsPreconditionFailed(err error) bool {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.status == http.StatusPreconditionFailed
+	}
+
+	return false
+}
+
 // ErrorData returns the contents of the [APIError].Data field from the provided error if it is of type [APIError].
 // Returns a nil slice if the given error is not of type [APIError].
 func ErrorData(err error) []APIErrorData {
@@ -374,6 +594,9 @@ func ErrorData(err error) []APIErrorData {
 type Duration time.Duration
 
 func (d Duration) String() string {
+	if d == SSHCheckPeriodAlways {
+		return "always"
+	}
 	return time.Duration(d).String()
 }
 
@@ -386,6 +609,10 @@ func (d *Duration) UnmarshalText(b []byte) error {
 	if text == "" {
 		text = "0s"
 	}
+	if text == "always" {
+		*d = SSHCheckPeriodAlways
+		return nil
+	}
 	pd, err := time.ParseDuration(text)
 	if err != nil {
 		return err
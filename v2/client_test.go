@@ -4,10 +4,15 @@
 package tsclient
 
 import (
+	"context"
 	_ "embed"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,3 +57,164 @@ func Test_BuildTailnetURL(t *testing.T) {
 	require.NoError(t, err)
 	assert.EqualValues(t, expected.String(), actual.String())
 }
+
+func Test_BuildTailnetURLDefault(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("http://example.com")
+	require.NoError(t, err)
+
+	c := &Client{
+		BaseURL: base,
+	}
+	actual := c.buildTailnetURL("devices")
+	expected, err := url.Parse("http://example.com/api/v2/tailnet/-/devices")
+	require.NoError(t, err)
+	assert.EqualValues(t, expected.String(), actual.String())
+}
+
+func TestClient_ForTailnet(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("http://example.com")
+	require.NoError(t, err)
+
+	c := &Client{
+		BaseURL:           base,
+		APIKey:            "key",
+		UserAgent:         "agent",
+		Tailnet:           "tailnet1",
+		RequirePolicyETag: true,
+	}
+	other := c.ForTailnet("tailnet2")
+	assert.Equal(t, "tailnet1", c.Tailnet)
+	assert.Equal(t, "tailnet2", other.Tailnet)
+	assert.Equal(t, "key", other.APIKey)
+	assert.Equal(t, "agent", other.UserAgent)
+	assert.True(t, other.RequirePolicyETag)
+	assert.Same(t, c.HTTP, other.HTTP)
+	assert.Equal(t, "http://example.com/api/v2/tailnet/tailnet2/devices", other.Devices().buildTailnetURL("devices").String())
+}
+
+func TestTimeouts_ForURL(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("http://example.com")
+	require.NoError(t, err)
+	c := &Client{BaseURL: base, Tailnet: "example.com"}
+
+	timeouts := Timeouts{
+		Default:    time.Second,
+		Devices:    2 * time.Second,
+		PolicyFile: 3 * time.Second,
+	}
+	assert.Equal(t, 2*time.Second, timeouts.forURL(c.buildTailnetURL("devices")))
+	assert.Equal(t, 2*time.Second, timeouts.forURL(c.buildURL("device", "id", "routes")))
+	assert.Equal(t, 3*time.Second, timeouts.forURL(c.buildTailnetURL("acl", "validate")))
+	assert.Equal(t, time.Second, timeouts.forURL(c.buildTailnetURL("users")))
+	assert.Zero(t, Timeouts{}.forURL(c.buildTailnetURL("users")))
+}
+
+func TestClient_Timeouts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	t.Cleanup(server.Close)
+
+	base, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	c := &Client{
+		BaseURL: base,
+		Timeouts: Timeouts{
+			Devices: 10 * time.Millisecond,
+		},
+	}
+
+	assert.ErrorIs(t, c.Devices().Delete(context.Background(), "test"), context.DeadlineExceeded)
+	assert.NoError(t, c.Keys().Delete(context.Background(), "test"))
+}
+
+// deadlineTransport records the time remaining until the deadline of the last request.
+type deadlineTransport struct {
+	remaining time.Duration
+}
+
+func (dt *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dt.remaining = 0
+	if deadline, ok := req.Context().Deadline(); ok {
+		dt.remaining = time.Until(deadline)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(http.NoBody), Request: req}, nil
+}
+
+func TestClient_TimeoutsWithHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("http://example.com")
+	require.NoError(t, err)
+	transport := &deadlineTransport{}
+
+	c := &Client{BaseURL: base, HTTP: &http.Client{Transport: transport}}
+	require.NoError(t, c.Devices().Delete(context.Background(), "test"))
+	assert.Zero(t, transport.remaining, "default timeout applied to the caller's HTTP client")
+
+	c = &Client{BaseURL: base, HTTP: &http.Client{Transport: transport}, Timeouts: Timeouts{Devices: 5 * time.Minute}}
+	require.NoError(t, c.Devices().Delete(context.Background(), "test"))
+	assert.Greater(t, transport.remaining, 4*time.Minute)
+	require.NoError(t, c.Keys().Delete(context.Background(), "test"))
+	assert.InDelta(t, defaultHttpClientTimeout, transport.remaining, float64(time.Second))
+
+	c = &Client{BaseURL: base}
+	c.init()
+	c.HTTP.Transport = transport
+	require.NoError(t, c.ForTailnet("other").Devices().Delete(context.Background(), "test"))
+	assert.InDelta(t, defaultHttpClientTimeout, transport.remaining, float64(time.Second))
+}
+
+// oauthDeadlineTransport serves OAuth tokens, and records the time remaining until the deadline of
+// the last other request like [deadlineTransport].
+type oauthDeadlineTransport struct {
+	deadlineTransport
+}
+
+func (ot *oauthDeadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/api/v2/oauth/token" {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`)),
+			Request:    req,
+		}, nil
+	}
+	return ot.deadlineTransport.RoundTrip(req)
+}
+
+func TestClient_TimeoutsWithOAuth(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("http://example.com")
+	require.NoError(t, err)
+	transport := &oauthDeadlineTransport{}
+
+	c := &Client{BaseURL: base, HTTP: OAuthConfig{BaseURL: base.String(), Transport: transport}.HTTPClient()}
+	require.NoError(t, c.Devices().Delete(context.Background(), "test"))
+	assert.InDelta(t, defaultHttpClientTimeout, transport.remaining, float64(time.Second))
+}
+
+func TestSortByID(t *testing.T) {
+	t.Parallel()
+
+	devices := []Device{{ID: "b", Name: "first"}, {ID: "a"}, {ID: "b", Name: "second"}}
+	sortByID(devices, func(d Device) string { return d.ID })
+	assert.Equal(t, []Device{{ID: "a"}, {ID: "b", Name: "first"}, {ID: "b", Name: "second"}}, devices)
+}
+
+func TestSortByIDNumeric(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"10", "b", "9", "100", "a", "11"}
+	sortByID(ids, func(id string) string { return id })
+	assert.Equal(t, []string{"9", "10", "11", "100", "a", "b"}, ids)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Command acllint checks policy files for likely mistakes using [tsclient.ACL.Lint], such as
+// unused groups and hosts, references to undefined groups and tags, and shadowed rules. It prints
+// one line per finding and exits with a non-zero status if there are any, so that it can be run
+// in CI against policy files checked into a repository.
+//
+// Usage:
+//
+//	go run github.com/tailscale/tailscale-client-go/v2/cmd/acllint [-ignore rule,...] policy.hujson...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func main() {
+	ignore := flag.String("ignore", "", "comma-separated names of lint rules to ignore, such as unused-host")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: acllint [-ignore rule,...] policy.hujson...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ignored := strings.Split(*ignore, ",")
+
+	failed := false
+	for _, file := range flag.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		acl, err := tsclient.ParseACL(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			os.Exit(2)
+		}
+		for _, finding := range acl.Lint() {
+			if slices.Contains(ignored, finding.Rule) {
+				continue
+			}
+			fmt.Printf("%s: %s\n", file, finding)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
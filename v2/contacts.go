@@ -44,8 +44,8 @@ type UpdateContactRequest struct {
 }
 
 // Get retieves the [Contacts] for the tailnet.
-func (cr *ContactsResource) Get(ctx context.Context) (*Contacts, error) {
-	req, err := cr.buildRequest(ctx, http.MethodGet, cr.buildTailnetURL("contacts"))
+func (cr *ContactsResource) Get(ctx context.Context, opts ...GetOption) (*Contacts, error) {
+	req, err := cr.buildRequest(ctx, http.MethodGet, cr.buildTailnetURL("contacts"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +55,8 @@ func (cr *ContactsResource) Get(ctx context.Context) (*Contacts, error) {
 
 // Update updates the email for the specified [ContactType] within the tailnet.
 // If the email address changes, the system will send a verification email to confirm the change.
-func (cr *ContactsResource) Update(ctx context.Context, contactType ContactType, contact UpdateContactRequest) error {
-	req, err := cr.buildRequest(ctx, http.MethodPatch, cr.buildTailnetURL("contacts", contactType), requestBody(contact))
+func (cr *ContactsResource) Update(ctx context.Context, contactType ContactType, contact UpdateContactRequest, opts ...WriteOption) error {
+	req, err := cr.buildRequest(ctx, http.MethodPatch, cr.buildTailnetURL("contacts", contactType), requestBody(contact), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,27 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import "context"
+
+// CorrelationIDHeader is the HTTP request header in which the [Client] sends the correlation ID
+// set using [WithCorrelationID].
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying the given correlation ID. The [Client] sends it
+// in the [CorrelationIDHeader] header of every request made with the returned context, which makes
+// it possible to trace multi-step workflows across many API calls. Custom [http.RoundTripper]
+// implementations used for logging or metrics can retrieve it using [CorrelationID].
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID set on ctx using [WithCorrelationID], or an empty
+// string if there is none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
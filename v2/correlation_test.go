@@ -0,0 +1,29 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	ctx := tsclient.WithCorrelationID(context.Background(), "offboarding-1234")
+	assert.Equal(t, "offboarding-1234", tsclient.CorrelationID(ctx))
+
+	assert.NoError(t, client.Devices().Delete(ctx, "test"))
+	assert.Equal(t, "offboarding-1234", server.Header.Get(tsclient.CorrelationIDHeader))
+
+	assert.NoError(t, client.Devices().Delete(context.Background(), "test"))
+	assert.Empty(t, server.Header.Get(tsclient.CorrelationIDHeader))
+}
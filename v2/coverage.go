@@ -0,0 +1,40 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:generate go run ./internal/gencoverage
+
+//go:embed coverage.json
+var coverageJSON []byte
+
+// CoverageEntry describes a single method of a resource of the [Client].
+type CoverageEntry struct {
+	// Resource is the name of the resource, matching the name of the [Client] method returning it,
+	// such as "Devices".
+	Resource string `json:"resource"`
+	// Method is the name of the method on the resource, such as "List".
+	Method string `json:"method"`
+	// HTTPMethod and Path identify the API endpoint called by the method, such as GET and
+	// /api/v2/tailnet/{tailnet}/devices. They are empty for methods that call several endpoints.
+	HTTPMethod string `json:"httpMethod,omitempty"`
+	Path       string `json:"path,omitempty"`
+	// Since is the version of this module in which the method was introduced, or "unreleased".
+	Since string `json:"since"`
+}
+
+// Coverage returns the API coverage of this version of the client, ordered by resource and method.
+// It allows tools to detect whether the client supports a capability at runtime, instead of
+// comparing module versions.
+func Coverage() []CoverageEntry {
+	var entries []CoverageEntry
+	if err := json.Unmarshal(coverageJSON, &entries); err != nil {
+		panic(err)
+	}
+	return entries
+}
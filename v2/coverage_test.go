@@ -0,0 +1,45 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestCoverage(t *testing.T) {
+	t.Parallel()
+
+	var covered []string
+	for _, entry := range tsclient.Coverage() {
+		assert.NotEmpty(t, entry.Since, "%s.%s", entry.Resource, entry.Method)
+		covered = append(covered, entry.Resource+"."+entry.Method)
+	}
+
+	// Every method of every resource, excluding those promoted from the embedded Client, must be
+	// listed. Run go generate to update the manifest.
+	client := reflect.TypeOf(&tsclient.Client{})
+	var methods []string
+	for i := range client.NumMethod() {
+		accessor := client.Method(i)
+		resource := accessor.Type.NumOut() == 1 && strings.HasSuffix(accessor.Type.Out(0).String(), "Resource")
+		if !resource || accessor.Type.NumIn() != 1 {
+			continue
+		}
+		typ := accessor.Type.Out(0)
+		for j := range typ.NumMethod() {
+			name := typ.Method(j).Name
+			if _, ok := client.MethodByName(name); !ok {
+				methods = append(methods, accessor.Name+"."+name)
+			}
+		}
+	}
+	sort.Strings(methods)
+	assert.Equal(t, methods, covered)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// DeviceInvitesResource provides access to https://tailscale.com/api#tag/deviceinvites.
+type DeviceInvitesResource struct {
+	*Client
+}
+
+// AcceptDeviceInviteResponse describes the device shared by an accepted device invite.
+type AcceptDeviceInviteResponse struct {
+	Device     SharedDevice `json:"device"`
+	Sharer     InviteUser   `json:"sharer"`
+	AcceptedBy *InviteUser  `json:"acceptedBy,omitempty"`
+}
+
+// SharedDevice describes a device shared into a tailnet by a device invite.
+type SharedDevice struct {
+	ID              string `json:"id"`
+	OS              string `json:"os"`
+	Name            string `json:"name"`
+	FQDN            string `json:"fqdn"`
+	IPv4            string `json:"ipv4"`
+	IPv6            string `json:"ipv6"`
+	IncludeExitNode bool   `json:"includeExitNode"`
+}
+
+// InviteUser describes a user involved in a device invite.
+type InviteUser struct {
+	ID            string `json:"id"`
+	DisplayName   string `json:"displayName"`
+	LoginName     string `json:"loginName"`
+	ProfilePicURL string `json:"profilePicUrl"`
+}
+
+// Accept accepts the device invite identified by invite, which is either the invite code or the
+// full invite URL, sharing the invited device into the tailnet of the authenticated user.
+func (dir *DeviceInvitesResource) Accept(ctx context.Context, invite string, opts ...WriteOption) (*AcceptDeviceInviteResponse, error) {
+	req, err := dir.buildRequest(ctx, http.MethodPost, dir.buildURL("device-invites", "-", "accept"), requestBody(map[string]string{
+		"invite": invite,
+	}), writeOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return body[AcceptDeviceInviteResponse](dir, req)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_DeviceInvites_Accept(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	expected := &tsclient.AcceptDeviceInviteResponse{
+		Device: tsclient.SharedDevice{
+			ID:   "12345",
+			OS:   "linux",
+			Name: "server",
+			FQDN: "server.example.ts.net",
+			IPv4: "100.64.0.1",
+		},
+		Sharer: tsclient.InviteUser{ID: "1", LoginName: "alice@example.com"},
+	}
+	server.ResponseBody = expected
+
+	actual, err := client.DeviceInvites().Accept(context.Background(), "abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/device-invites/-/accept", server.Path)
+	assert.Equal(t, expected, actual)
+
+	body := make(map[string]string)
+	assert.NoError(t, json.Unmarshal(server.Body.Bytes(), &body))
+	assert.Equal(t, "abc123", body["invite"])
+}
@@ -5,6 +5,8 @@ package tsclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 )
 
@@ -52,9 +54,9 @@ type UpdatePostureIntegrationRequest struct {
 	ClientSecret *string `json:"clientSecret,omitempty"`
 }
 
-// List lists every configured [PostureIntegration].
-func (pr *DevicePostureResource) ListIntegrations(ctx context.Context) ([]PostureIntegration, error) {
-	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildTailnetURL("posture", "integrations"))
+// List lists every configured [PostureIntegration], ordered by ID.
+func (pr *DevicePostureResource) ListIntegrations(ctx context.Context, opts ...ListOption) ([]PostureIntegration, error) {
+	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildTailnetURL("posture", "integrations"), listOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -65,12 +67,12 @@ func (pr *DevicePostureResource) ListIntegrations(ctx context.Context) ([]Postur
 		return nil, err
 	}
 
-	return m["integrations"], nil
+	return sortByID(m["integrations"], func(i PostureIntegration) string { return i.ID }), nil
 }
 
 // CreateIntegration creates a new posture integration, returning the resulting [PostureIntegration].
-func (pr *DevicePostureResource) CreateIntegration(ctx context.Context, intg CreatePostureIntegrationRequest) (*PostureIntegration, error) {
-	req, err := pr.buildRequest(ctx, http.MethodPost, pr.buildTailnetURL("posture", "integrations"), requestBody(intg))
+func (pr *DevicePostureResource) CreateIntegration(ctx context.Context, intg CreatePostureIntegrationRequest, opts ...WriteOption) (*PostureIntegration, error) {
+	req, err := pr.buildRequest(ctx, http.MethodPost, pr.buildTailnetURL("posture", "integrations"), requestBody(intg), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -79,8 +81,8 @@ func (pr *DevicePostureResource) CreateIntegration(ctx context.Context, intg Cre
 }
 
 // UpdateIntegration updates the existing posture integration identified by id, returning the resulting [PostureIntegration].
-func (pr *DevicePostureResource) UpdateIntegration(ctx context.Context, id string, intg UpdatePostureIntegrationRequest) (*PostureIntegration, error) {
-	req, err := pr.buildRequest(ctx, http.MethodPatch, pr.buildURL("posture", "integrations", id), requestBody(intg))
+func (pr *DevicePostureResource) UpdateIntegration(ctx context.Context, id string, intg UpdatePostureIntegrationRequest, opts ...WriteOption) (*PostureIntegration, error) {
+	req, err := pr.buildRequest(ctx, http.MethodPatch, pr.buildURL("posture", "integrations", id), requestBody(intg), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -89,8 +91,8 @@ func (pr *DevicePostureResource) UpdateIntegration(ctx context.Context, id strin
 }
 
 // DeleteIntegration deletes the posture integration identified by id.
-func (pr *DevicePostureResource) DeleteIntegration(ctx context.Context, id string) error {
-	req, err := pr.buildRequest(ctx, http.MethodDelete, pr.buildURL("posture", "integrations", id))
+func (pr *DevicePostureResource) DeleteIntegration(ctx context.Context, id string, opts ...WriteOption) error {
+	req, err := pr.buildRequest(ctx, http.MethodDelete, pr.buildURL("posture", "integrations", id), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -99,11 +101,37 @@ func (pr *DevicePostureResource) DeleteIntegration(ctx context.Context, id strin
 }
 
 // GetIntegration gets the posture integration identified by id.
-func (pr *DevicePostureResource) GetIntegration(ctx context.Context, id string) (*PostureIntegration, error) {
-	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildURL("posture", "integrations", id))
+func (pr *DevicePostureResource) GetIntegration(ctx context.Context, id string, opts ...GetOption) (*PostureIntegration, error) {
+	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildURL("posture", "integrations", id), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
 
 	return body[PostureIntegration](pr, req)
 }
+
+// RotateSecret replaces the client secret of the posture integration identified by id with
+// newSecret, leaving every other field of the integration unchanged. Once the secret has been
+// updated, the integration is read back to confirm that its other fields were preserved.
+func (pr *DevicePostureResource) RotateSecret(ctx context.Context, id, newSecret string, opts ...WriteOption) (*PostureIntegration, error) {
+	if newSecret == "" {
+		return nil, errors.New("new client secret must not be empty")
+	}
+
+	before, err := pr.GetIntegration(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Only set ClientSecret, so that the other fields are omitted from the request and preserved.
+	if _, err := pr.UpdateIntegration(ctx, id, UpdatePostureIntegrationRequest{ClientSecret: &newSecret}, opts...); err != nil {
+		return nil, err
+	}
+	after, err := pr.GetIntegration(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if *after != *before {
+		return after, fmt.Errorf("posture integration %s changed unexpectedly while rotating its client secret", id)
+	}
+	return after, nil
+}
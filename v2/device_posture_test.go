@@ -83,6 +83,32 @@ func TestClient_DevicePosture_UpdateIntegration(t *testing.T) {
 	assert.Equal(t, req, actualRequest)
 }
 
+func TestClient_DevicePosture_RotateSecret(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	resp := &tsclient.PostureIntegration{
+		ID:       "1",
+		Provider: tsclient.PostureIntegrationProviderIntune,
+		CloudID:  "cloudid",
+		ClientID: "clientid",
+		TenantID: "tenantid",
+	}
+	server.ResponseBody = resp
+
+	actualResp, err := client.DevicePosture().RotateSecret(context.Background(), "1", "newsecret")
+	assert.NoError(t, err)
+	// The last request reads back the integration to confirm that it was preserved.
+	assert.Equal(t, http.MethodGet, server.Method)
+	assert.Equal(t, "/api/v2/posture/integrations/1", server.Path)
+	assert.Equal(t, resp, actualResp)
+
+	_, err = client.DevicePosture().RotateSecret(context.Background(), "1", "")
+	assert.Error(t, err)
+}
+
 func TestClient_DevicePosture_DeleteIntegration(t *testing.T) {
 	t.Parallel()
 
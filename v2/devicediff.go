@@ -0,0 +1,98 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DeviceDiff is the difference between two snapshots of the devices of a tailnet, as computed
+// by [DiffDevices]. Devices are matched by ID, and every list is ordered by ID.
+type DeviceDiff struct {
+	Added   []Device
+	Removed []Device
+	Changed []DeviceChange
+}
+
+// Empty reports whether the snapshots contain the same devices with the same fields.
+func (d DeviceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DeviceChange describes how a device differs between two snapshots.
+type DeviceChange struct {
+	Old Device
+	New Device
+	// Fields are the changed fields of the device, in the order in which they are declared in [Device].
+	Fields []DeviceFieldChange
+}
+
+// DeviceFieldChange describes the change of a field of a [Device].
+type DeviceFieldChange struct {
+	// Field is the JSON name of the field, such as "tags".
+	Field string
+	Old   any
+	New   any
+}
+
+// DiffDevices computes the difference between the devices in before and after. Fields whose JSON
+// names are in ignoreFields are not compared, which is useful to ignore frequently changing
+// fields such as "lastSeen". Nil and empty lists are considered equal.
+func DiffDevices(before, after []Device, ignoreFields ...string) DeviceDiff {
+	oldByID := make(map[string]Device, len(before))
+	for _, d := range before {
+		oldByID[d.ID] = d
+	}
+	newIDs := make(map[string]bool, len(after))
+
+	var diff DeviceDiff
+	for _, d := range sortByID(slices.Clone(after), func(d Device) string { return d.ID }) {
+		newIDs[d.ID] = true
+		prev, ok := oldByID[d.ID]
+		if !ok {
+			diff.Added = append(diff.Added, d)
+			continue
+		}
+		if fields := deviceFieldChanges(prev, d, ignoreFields); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, DeviceChange{Old: prev, New: d, Fields: fields})
+		}
+	}
+	for _, d := range sortByID(slices.Clone(before), func(d Device) string { return d.ID }) {
+		if !newIDs[d.ID] {
+			diff.Removed = append(diff.Removed, d)
+		}
+	}
+	return diff
+}
+
+var timeType = reflect.TypeFor[Time]()
+
+func deviceFieldChanges(before, after Device, ignoreFields []string) []DeviceFieldChange {
+	var changes []DeviceFieldChange
+	ov, nv := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := range ov.NumField() {
+		field := ov.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || slices.Contains(ignoreFields, name) {
+			continue
+		}
+		of, nf := ov.Field(i), nv.Field(i)
+		if !deviceFieldEqual(of, nf) {
+			changes = append(changes, DeviceFieldChange{Field: name, Old: of.Interface(), New: nf.Interface()})
+		}
+	}
+	return changes
+}
+
+func deviceFieldEqual(a, b reflect.Value) bool {
+	switch {
+	case a.Type() == timeType:
+		return a.Interface().(Time).Equal(b.Interface().(Time).Time)
+	case a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0:
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
@@ -0,0 +1,47 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestDiffDevices(t *testing.T) {
+	t.Parallel()
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := []tsclient.Device{
+		{ID: "2", Name: "b", Tags: []string{"tag:a"}, LastSeen: tsclient.Time{Time: seen}},
+		{ID: "1", Name: "a", Addresses: nil},
+		{ID: "3", Name: "c"},
+	}
+	after := []tsclient.Device{
+		{ID: "1", Name: "a", Addresses: []string{}},
+		{ID: "2", Name: "b", Tags: []string{"tag:b"}, Authorized: true, LastSeen: tsclient.Time{Time: seen.Add(time.Minute)}},
+		{ID: "4", Name: "d"},
+	}
+
+	diff := tsclient.DiffDevices(before, after, "lastSeen")
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []tsclient.Device{after[2]}, diff.Added)
+	assert.Equal(t, []tsclient.Device{before[2]}, diff.Removed)
+	assert.Equal(t, []tsclient.DeviceChange{{
+		Old: before[0],
+		New: after[1],
+		Fields: []tsclient.DeviceFieldChange{
+			{Field: "authorized", Old: false, New: true},
+			{Field: "tags", Old: []string{"tag:a"}, New: []string{"tag:b"}},
+		},
+	}}, diff.Changed)
+
+	diff = tsclient.DiffDevices(before, after)
+	assert.Equal(t, "lastSeen", diff.Changed[0].Fields[2].Field)
+
+	assert.True(t, tsclient.DiffDevices(before, before).Empty())
+}
@@ -0,0 +1,28 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// History retrieves the configuration audit log entries that affected the device identified by
+// nodeID during the window ending now, such as its authorization, tag changes and deletion,
+// ordered by time with the oldest first. Audit logs identify devices by their [NodeID], so a
+// legacy [DeviceID] matches nothing.
+func (dr *DevicesResource) History(ctx context.Context, nodeID NodeID, window time.Duration, opts ...ListOption) ([]ConfigurationLog, error) {
+	end := time.Now()
+	logs, err := dr.Logging().ConfigurationLogs(ctx, end.Add(-window), end, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	logs = slices.DeleteFunc(logs, func(log ConfigurationLog) bool {
+		return log.Target.Type != "NODE" || log.Target.ID != string(nodeID)
+	})
+	slices.SortStableFunc(logs, func(a, b ConfigurationLog) int { return a.EventTime.Compare(b.EventTime) })
+	return logs, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeviceID is the legacy numeric ID of a device, as found in [Device].ID.
+type DeviceID string
+
+// NodeID is the stable node ID of a device, such as "nRWT3Pe6Tm11CNTRL", as found in [Device].NodeID.
+// Prefer it over [DeviceID] to identify devices.
+//
+// Methods of [DevicesResource] accept either ID. Convert one with string(id).
+type NodeID string
+
+// ParseDeviceID parses s as a legacy numeric device ID.
+func ParseDeviceID(s string) (DeviceID, error) {
+	id := DeviceID(s)
+	return id, id.Validate()
+}
+
+// ParseNodeID parses s as a stable node ID.
+func ParseNodeID(s string) (NodeID, error) {
+	id := NodeID(s)
+	return id, id.Validate()
+}
+
+// Validate reports an error if id is not a numeric device ID. In particular, it reports an error
+// for a [NodeID].
+func (id DeviceID) Validate() error {
+	if id == "" || strings.ContainsFunc(string(id), func(r rune) bool { return r < '0' || r > '9' }) {
+		return fmt.Errorf("invalid device ID %q: must be numeric", string(id))
+	}
+	return nil
+}
+
+// Validate reports an error if id is not a stable node ID, which consists of an "n" followed by
+// letters and digits. In particular, it reports an error for a numeric [DeviceID].
+func (id NodeID) Validate() error {
+	rest, ok := strings.CutPrefix(string(id), "n")
+	if !ok || rest == "" || strings.ContainsFunc(rest, func(r rune) bool {
+		return (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+	}) {
+		return fmt.Errorf("invalid node ID %q: must be \"n\" followed by letters and digits", string(id))
+	}
+	return nil
+}
+
+// DeviceID returns the legacy numeric ID of the device.
+func (d *Device) DeviceID() DeviceID {
+	return DeviceID(d.ID)
+}
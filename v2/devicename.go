@@ -0,0 +1,87 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxDeviceNameLabelLength = 63
+	maxDeviceNameLength      = 253
+)
+
+// DeviceNameError is returned by [ValidateDeviceName] and [DevicesResource.SetName] when a device
+// name is invalid.
+type DeviceNameError struct {
+	Name   string
+	Reason string
+}
+
+func (err DeviceNameError) Error() string {
+	return fmt.Sprintf("invalid device name %q: %s", err.Name, err.Reason)
+}
+
+// ValidateDeviceName reports a [DeviceNameError] if name cannot be used as the name of a device.
+// name may be a machine name, such as "my-laptop", or a fully qualified MagicDNS name with or
+// without a trailing dot, such as "my-laptop.tail1234.ts.net", in which case only its first label
+// becomes the machine name. Labels consist of 1 to 63 letters, digits and hyphens, and cannot
+// start or end with a hyphen.
+func ValidateDeviceName(name string) error {
+	fqdn := strings.TrimSuffix(name, ".")
+	if fqdn == "" {
+		return DeviceNameError{name, "must not be empty"}
+	}
+	if len(fqdn) > maxDeviceNameLength {
+		return DeviceNameError{name, fmt.Sprintf("must be at most %d characters long", maxDeviceNameLength)}
+	}
+	for _, label := range strings.Split(fqdn, ".") {
+		switch {
+		case label == "":
+			return DeviceNameError{name, "must not contain empty labels"}
+		case len(label) > maxDeviceNameLabelLength:
+			return DeviceNameError{name, fmt.Sprintf("label %q must be at most %d characters long", label, maxDeviceNameLabelLength)}
+		case label[0] == '-' || label[len(label)-1] == '-':
+			return DeviceNameError{name, fmt.Sprintf("label %q must not start or end with a hyphen", label)}
+		}
+		for _, r := range label {
+			if !isDNSLabelRune(r) {
+				return DeviceNameError{name, fmt.Sprintf("label %q contains %q, only letters, digits and hyphens are allowed", label, r)}
+			}
+		}
+	}
+	return nil
+}
+
+// DNSSafeName derives a valid machine name from displayName, such as "alices-macbook-pro" from
+// "Alice's MacBook Pro". Letters are lowercased, apostrophes are dropped, other runs of characters
+// that are not allowed are replaced with a single hyphen, and the result is truncated to 63
+// characters. It returns an empty string if displayName contains no letters or digits.
+func DNSSafeName(displayName string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(displayName) {
+		switch {
+		case r == '\'' || r == '’':
+		case isDNSLabelRune(r) && r != '-':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+	name := b.String()
+	if len(name) > maxDeviceNameLabelLength {
+		name = strings.TrimRight(name[:maxDeviceNameLabelLength], "-")
+	}
+	return name
+}
+
+func isDNSLabelRune(r rune) bool {
+	return r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
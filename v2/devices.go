@@ -4,12 +4,24 @@
 package tsclient
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
 	"time"
 )
 
+const defaultOnlineThreshold = 5 * time.Minute
+
+// ErrDeviceNotFound is returned by the methods of [DevicesResource] that search the devices of the
+// tailnet, such as [DevicesResource.FindByHostname], when no device matches.
+var ErrDeviceNotFound = errors.New("device not found")
+
 // DevicesResource provides access to https://tailscale.com/api#tag/devices.
 type DevicesResource struct {
 	*Client
@@ -20,6 +32,16 @@ type DeviceRoutes struct {
 	Enabled    []string `json:"enabledRoutes"`
 }
 
+// AdvertisedPrefixes returns the advertised routes, parsed as IP prefixes.
+func (r *DeviceRoutes) AdvertisedPrefixes() ([]netip.Prefix, error) {
+	return parseAll(r.Advertised, netip.ParsePrefix)
+}
+
+// EnabledPrefixes returns the enabled routes, parsed as IP prefixes.
+func (r *DeviceRoutes) EnabledPrefixes() ([]netip.Prefix, error) {
+	return parseAll(r.Enabled, netip.ParsePrefix)
+}
+
 // Time wraps a time and allows for unmarshalling timestamps that represent an empty time as an empty string (e.g "")
 // this is used by the tailscale API when it returns devices that have no created date, such as its hello service.
 type Time struct {
@@ -48,6 +70,7 @@ type Device struct {
 	Addresses                 []string `json:"addresses"`
 	Name                      string   `json:"name"`
 	ID                        string   `json:"id"`
+	NodeID                    NodeID   `json:"nodeId"`
 	Authorized                bool     `json:"authorized"`
 	User                      string   `json:"user"`
 	Tags                      []string `json:"tags"`
@@ -65,6 +88,103 @@ type Device struct {
 	TailnetLockError          string   `json:"tailnetLockError"`
 	TailnetLockKey            string   `json:"tailnetLockKey"`
 	UpdateAvailable           bool     `json:"updateAvailable"`
+
+	// The following fields are only populated when requested using [WithAllFields].
+	AdvertisedRoutes []string               `json:"advertisedRoutes,omitempty"`
+	EnabledRoutes    []string               `json:"enabledRoutes,omitempty"`
+	PostureIdentity  *DevicePostureIdentity `json:"postureIdentity,omitempty"`
+	// ClientConnectivity describes the network conditions of the device, as last reported by it.
+	ClientConnectivity *ClientConnectivity `json:"clientConnectivity,omitempty"`
+}
+
+// ClientConnectivity describes the network conditions of a device, as used for debugging
+// connectivity between devices.
+type ClientConnectivity struct {
+	// Endpoints are the IP:port pairs at which the device may be reachable by other devices.
+	Endpoints []string `json:"endpoints"`
+	// DERP is the DERP region that the device uses as its home, if reported.
+	DERP string `json:"derp,omitempty"`
+	// MappingVariesByDestIP is true if the NAT of the device maps its ports differently
+	// depending on the destination, which makes direct connections harder to establish.
+	MappingVariesByDestIP bool `json:"mappingVariesByDestIP"`
+	// Latency is the latency from the device to each DERP region, keyed by region name.
+	Latency        map[string]DERPRegionLatency `json:"latency"`
+	ClientSupports ClientSupports               `json:"clientSupports"`
+}
+
+// DERPRegionLatency is the latency from a device to a DERP region.
+type DERPRegionLatency struct {
+	LatencyMilliseconds float64 `json:"latencyMs"`
+	// Preferred is true for the region that the device prefers, which is its home region.
+	Preferred bool `json:"preferred,omitempty"`
+}
+
+// Latency returns the latency as a [time.Duration].
+func (l DERPRegionLatency) Latency() time.Duration {
+	return time.Duration(l.LatencyMilliseconds * float64(time.Millisecond))
+}
+
+// ClientSupports reports the network features that a device found to be available.
+type ClientSupports struct {
+	HairPinning *bool `json:"hairPinning"`
+	IPv6        bool  `json:"ipv6"`
+	PCP         bool  `json:"pcp"`
+	PMP         bool  `json:"pmp"`
+	UDP         bool  `json:"udp"`
+	UPnP        bool  `json:"upnp"`
+}
+
+// PreferredDERPRegion returns the name of the DERP region preferred by the device, and
+// whether there is one.
+func (cc *ClientConnectivity) PreferredDERPRegion() (string, bool) {
+	for name, latency := range cc.Latency {
+		if latency.Preferred {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// IPAddresses returns the Tailscale IP addresses of the device, parsed from Addresses.
+// An error is returned if any of them is not a valid IP address.
+func (d *Device) IPAddresses() ([]netip.Addr, error) {
+	return parseAll(d.Addresses, netip.ParseAddr)
+}
+
+// AdvertisedPrefixes returns the subnet routes advertised by the device, parsed from
+// AdvertisedRoutes. An error is returned if any of them is not a valid IP prefix.
+func (d *Device) AdvertisedPrefixes() ([]netip.Prefix, error) {
+	return parseAll(d.AdvertisedRoutes, netip.ParsePrefix)
+}
+
+// EnabledPrefixes returns the subnet routes enabled for the device, parsed from EnabledRoutes.
+// An error is returned if any of them is not a valid IP prefix.
+func (d *Device) EnabledPrefixes() ([]netip.Prefix, error) {
+	return parseAll(d.EnabledRoutes, netip.ParsePrefix)
+}
+
+// parseAll parses every string of values using parse, returning nil if values is empty.
+func parseAll[T any](values []string, parse func(string) (T, error)) ([]T, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	parsed := make([]T, len(values))
+	for i, v := range values {
+		p, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = p
+	}
+	return parsed, nil
+}
+
+// DevicePostureIdentity contains identifying hardware information about a device, collected
+// when posture identity collection is enabled for the tailnet.
+type DevicePostureIdentity struct {
+	SerialNumbers []string `json:"serialNumbers,omitempty"`
+	// Disabled is true if the device has disabled the collection of posture identity information.
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 type DevicePostureAttributes struct {
@@ -78,9 +198,30 @@ type DevicePostureAttributeRequest struct {
 	Comment string `json:"comment"`
 }
 
+// ExpiresIn returns a copy of r that expires ttl from now.
+func (r DevicePostureAttributeRequest) ExpiresIn(ttl time.Duration) DevicePostureAttributeRequest {
+	r.Expiry = Time{time.Now().Add(ttl).UTC()}
+	return r
+}
+
+// DeviceFieldsOption is returned by [WithAllFields].
+type DeviceFieldsOption struct {
+	fields string
+}
+
+func (o DeviceFieldsOption) applyListOption(rp *requestParams) { requestQuery("fields", o.fields)(rp) }
+func (o DeviceFieldsOption) applyGetOption(rp *requestParams)  { requestQuery("fields", o.fields)(rp) }
+
+// WithAllFields returns an option for [DevicesResource.Get] and [DevicesResource.List] that requests
+// every field of devices, including the extended fields which are omitted by default, such as
+// enabled and advertised routes.
+func WithAllFields() DeviceFieldsOption {
+	return DeviceFieldsOption{fields: "all"}
+}
+
 // Get gets the [Device] identified by deviceID.
-func (dr *DevicesResource) Get(ctx context.Context, deviceID string) (*Device, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildURL("device", deviceID))
+func (dr *DevicesResource) Get(ctx context.Context, deviceID string, opts ...GetOption) (*Device, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildURL("device", deviceID), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -89,8 +230,8 @@ func (dr *DevicesResource) Get(ctx context.Context, deviceID string) (*Device, e
 }
 
 // GetPostureAttributes retrieves the posture attributes of the device identified by deviceID.
-func (dr *DevicesResource) GetPostureAttributes(ctx context.Context, deviceID string) (*DevicePostureAttributes, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildURL("device", deviceID, "attributes"))
+func (dr *DevicesResource) GetPostureAttributes(ctx context.Context, deviceID string, opts ...GetOption) (*DevicePostureAttributes, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildURL("device", deviceID, "attributes"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -99,8 +240,8 @@ func (dr *DevicesResource) GetPostureAttributes(ctx context.Context, deviceID st
 }
 
 // SetPostureAttribute sets the posture attribute of the device identified by deviceID.
-func (dr *DevicesResource) SetPostureAttribute(ctx context.Context, deviceID, attributeKey string, request DevicePostureAttributeRequest) error {
-	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "attributes", attributeKey), requestBody(request))
+func (dr *DevicesResource) SetPostureAttribute(ctx context.Context, deviceID, attributeKey string, request DevicePostureAttributeRequest, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "attributes", attributeKey), requestBody(request), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -108,9 +249,40 @@ func (dr *DevicesResource) SetPostureAttribute(ctx context.Context, deviceID, at
 	return dr.do(req, nil)
 }
 
-// List lists every [Device] in the tailnet.
-func (dr *DevicesResource) List(ctx context.Context) ([]Device, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("devices"))
+// SetPostureAttributes sets several posture attributes of the device identified by deviceID, keyed
+// by attribute key. Since the API sets one attribute per request, attributes are set one after
+// the other in key order. Failing attributes do not prevent setting the others, and the returned
+// error joins the errors for every failing attribute.
+func (dr *DevicesResource) SetPostureAttributes(ctx context.Context, deviceID string, attributes map[string]DevicePostureAttributeRequest, opts ...WriteOption) error {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if err := dr.SetPostureAttribute(ctx, deviceID, key, attributes[key], opts...); err != nil {
+			errs = append(errs, fmt.Errorf("setting posture attribute %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DeletePostureAttribute deletes the posture attribute identified by attributeKey from the device
+// identified by deviceID.
+func (dr *DevicesResource) DeletePostureAttribute(ctx context.Context, deviceID, attributeKey string, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodDelete, dr.buildURL("device", deviceID, "attributes", attributeKey), writeOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	return dr.do(req, nil)
+}
+
+// List lists every [Device] in the tailnet, ordered by ID.
+func (dr *DevicesResource) List(ctx context.Context, opts ...ListOption) ([]Device, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("devices"), listOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -121,14 +293,174 @@ func (dr *DevicesResource) List(ctx context.Context) ([]Device, error) {
 		return nil, err
 	}
 
-	return m["devices"], nil
+	return sortByID(m["devices"], func(d Device) string { return d.ID }), nil
+}
+
+// DeviceListSnapshot is the result of [DevicesResource.ListIfModified].
+type DeviceListSnapshot struct {
+	// Devices are the devices of the tailnet, ordered by ID.
+	Devices []Device
+	// NotModified is true if the API reported that the devices have not changed since the
+	// previous snapshot, in which case Devices are those of the previous snapshot.
+	NotModified bool
+	// ETag and LastModified are the validators returned by the API, if any.
+	ETag         string
+	LastModified string
+}
+
+// ListIfModified is like [DevicesResource.List], but makes a conditional request using the
+// validators of previous, if not nil, so that the device list is not downloaded again if it has
+// not changed. If the API does not return validators, every request downloads the full list.
+func (dr *DevicesResource) ListIfModified(ctx context.Context, previous *DeviceListSnapshot, opts ...ListOption) (*DeviceListSnapshot, error) {
+	headers := make(map[string]string)
+	if previous != nil && previous.ETag != "" {
+		headers["If-None-Match"] = previous.ETag
+	}
+	if previous != nil && previous.LastModified != "" {
+		headers["If-Modified-Since"] = previous.LastModified
+	}
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("devices"), requestHeaders(headers), listOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string][]Device)
+	header, err := dr.doWithResponseHeaders(req, &m)
+	switch {
+	case errors.Is(err, ErrNotModified) && previous != nil:
+		return &DeviceListSnapshot{
+			Devices:      previous.Devices,
+			NotModified:  true,
+			ETag:         cmp.Or(header.Get("ETag"), previous.ETag),
+			LastModified: cmp.Or(header.Get("Last-Modified"), previous.LastModified),
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return &DeviceListSnapshot{
+		Devices:      sortByID(m["devices"], func(d Device) string { return d.ID }),
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}, nil
+}
+
+// DeviceListOptions filters the devices returned by [DevicesResource.ListMatching]. Zero values
+// match every device. Since the API does not support filtering devices, filters are applied
+// by the client.
+type DeviceListOptions struct {
+	// Tags only matches devices that have every one of the given tags.
+	Tags []string
+	// User only matches devices owned by the user with the given login name.
+	User string
+	// OS only matches devices running the given operating system, compared case-insensitively.
+	OS string
+	// Online only matches devices that are online if true, or offline if false. A device is
+	// considered online if it was last seen within OnlineThreshold.
+	Online *bool
+	// OnlineThreshold is the maximum time since a device was last seen for it to be considered
+	// online. Defaults to 5 minutes.
+	OnlineThreshold time.Duration
+}
+
+// Match reports whether device matches the options.
+func (o DeviceListOptions) Match(device *Device) bool {
+	for _, tag := range o.Tags {
+		if !slices.Contains(device.Tags, tag) {
+			return false
+		}
+	}
+	if o.User != "" && device.User != o.User {
+		return false
+	}
+	if o.OS != "" && !strings.EqualFold(device.OS, o.OS) {
+		return false
+	}
+	if o.Online != nil && deviceOnline(device, o.OnlineThreshold) != *o.Online {
+		return false
+	}
+	return true
+}
+
+// deviceOnline reports whether device was last seen within threshold, or within 5 minutes if
+// threshold is zero.
+func deviceOnline(device *Device, threshold time.Duration) bool {
+	if threshold == 0 {
+		threshold = defaultOnlineThreshold
+	}
+	return !device.LastSeen.IsZero() && time.Since(device.LastSeen.Time) <= threshold
+}
+
+// ListMatching lists every [Device] in the tailnet that matches filter.
+func (dr *DevicesResource) ListMatching(ctx context.Context, filter DeviceListOptions, opts ...ListOption) ([]Device, error) {
+	devices, err := dr.List(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(devices, func(d Device) bool { return !filter.Match(&d) }), nil
+}
+
+// FindByHostname finds the device with the given hostname or MagicDNS name. name may be a fully
+// qualified MagicDNS name, with or without a trailing dot, or just the machine name. Matching is
+// case-insensitive, and MagicDNS names take precedence over the hostnames reported by devices.
+// If no device matches, [ErrDeviceNotFound] is returned.
+func (dr *DevicesResource) FindByHostname(ctx context.Context, name string, opts ...ListOption) (*Device, error) {
+	name = strings.TrimSuffix(name, ".")
+	return dr.find(ctx, opts,
+		func(d *Device) bool { return strings.EqualFold(strings.TrimSuffix(d.Name, "."), name) },
+		func(d *Device) bool {
+			machineName, _, _ := strings.Cut(d.Name, ".")
+			return strings.EqualFold(machineName, name)
+		},
+		func(d *Device) bool { return strings.EqualFold(d.Hostname, name) },
+	)
+}
+
+// FindByIP finds the device with the given Tailscale IP address.
+// If no device matches, [ErrDeviceNotFound] is returned.
+func (dr *DevicesResource) FindByIP(ctx context.Context, ip netip.Addr, opts ...ListOption) (*Device, error) {
+	ip = ip.Unmap()
+	return dr.find(ctx, opts, func(d *Device) bool {
+		return slices.ContainsFunc(d.Addresses, func(address string) bool {
+			addr, err := netip.ParseAddr(address)
+			return err == nil && addr == ip
+		})
+	})
+}
+
+// FindByNodeKey finds the device with the given node key, with or without the "nodekey:" prefix.
+// If no device matches, [ErrDeviceNotFound] is returned.
+func (dr *DevicesResource) FindByNodeKey(ctx context.Context, nodeKey string, opts ...ListOption) (*Device, error) {
+	nodeKey = strings.TrimPrefix(nodeKey, "nodekey:")
+	return dr.find(ctx, opts, func(d *Device) bool {
+		return d.NodeKey != "" && strings.TrimPrefix(d.NodeKey, "nodekey:") == nodeKey
+	})
+}
+
+// find lists devices and returns the first device matching the first of matchers for which any
+// device matches.
+func (dr *DevicesResource) find(ctx context.Context, opts []ListOption, matchers ...func(*Device) bool) (*Device, error) {
+	devices, err := dr.List(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, match := range matchers {
+		for i := range devices {
+			if match(&devices[i]) {
+				return &devices[i], nil
+			}
+		}
+	}
+	return nil, ErrDeviceNotFound
 }
 
 // SetAuthorized marks the specified device as authorized or not.
-func (dr *DevicesResource) SetAuthorized(ctx context.Context, deviceID string, authorized bool) error {
+func (dr *DevicesResource) SetAuthorized(ctx context.Context, deviceID string, authorized bool, opts ...WriteOption) error {
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "authorized"), requestBody(map[string]bool{
 		"authorized": authorized,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -137,8 +469,8 @@ func (dr *DevicesResource) SetAuthorized(ctx context.Context, deviceID string, a
 }
 
 // Delete deletes the device identified by deviceID.
-func (dr *DevicesResource) Delete(ctx context.Context, deviceID string) error {
-	req, err := dr.buildRequest(ctx, http.MethodDelete, dr.buildURL("device", deviceID))
+func (dr *DevicesResource) Delete(ctx context.Context, deviceID string, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodDelete, dr.buildURL("device", deviceID), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -146,11 +478,27 @@ func (dr *DevicesResource) Delete(ctx context.Context, deviceID string) error {
 	return dr.do(req, nil)
 }
 
-// SetName updates the name of the device identified by deviceID.
-func (dr *DevicesResource) SetName(ctx context.Context, deviceID, name string) error {
+// Expire expires the key of the device identified by deviceID, forcing the device to
+// re-authenticate before it can reconnect to the tailnet.
+func (dr *DevicesResource) Expire(ctx context.Context, deviceID string, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "expire"), writeOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	return dr.do(req, nil)
+}
+
+// SetName updates the name of the device identified by deviceID. name is validated using
+// [ValidateDeviceName] before making any request.
+func (dr *DevicesResource) SetName(ctx context.Context, deviceID, name string, opts ...WriteOption) error {
+	if err := ValidateDeviceName(name); err != nil {
+		return err
+	}
+
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "name"), requestBody(map[string]string{
 		"name": name,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -158,11 +506,21 @@ func (dr *DevicesResource) SetName(ctx context.Context, deviceID, name string) e
 	return dr.do(req, nil)
 }
 
+// SetDisplayName updates the name of the device identified by deviceID to a name derived from
+// displayName using [DNSSafeName], and returns that name.
+func (dr *DevicesResource) SetDisplayName(ctx context.Context, deviceID, displayName string, opts ...WriteOption) (string, error) {
+	name := DNSSafeName(displayName)
+	if name == "" {
+		return "", DeviceNameError{displayName, "must contain at least one letter or digit"}
+	}
+	return name, dr.SetName(ctx, deviceID, name, opts...)
+}
+
 // SetTags updates the tags of the device identified by deviceID.
-func (dr *DevicesResource) SetTags(ctx context.Context, deviceID string, tags []string) error {
+func (dr *DevicesResource) SetTags(ctx context.Context, deviceID string, tags []string, opts ...WriteOption) error {
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "tags"), requestBody(map[string][]string{
 		"tags": tags,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -170,6 +528,49 @@ func (dr *DevicesResource) SetTags(ctx context.Context, deviceID string, tags []
 	return dr.do(req, nil)
 }
 
+// AddTags adds the given tags to the device identified by deviceID, keeping its existing tags, and
+// returns the resulting tags. The device's tags are read, merged with the given tags, and written
+// back, so concurrent tag updates by other writers may be lost.
+func (dr *DevicesResource) AddTags(ctx context.Context, deviceID string, tags []string, opts ...WriteOption) ([]string, error) {
+	return dr.updateTags(ctx, deviceID, tags, opts, func(current []string) []string {
+		for _, tag := range tags {
+			if !slices.Contains(current, tag) {
+				current = append(current, tag)
+			}
+		}
+		return current
+	})
+}
+
+// RemoveTags removes the given tags from the device identified by deviceID, keeping its other tags,
+// and returns the resulting tags. See [DevicesResource.AddTags] for details.
+func (dr *DevicesResource) RemoveTags(ctx context.Context, deviceID string, tags []string, opts ...WriteOption) ([]string, error) {
+	return dr.updateTags(ctx, deviceID, tags, opts, func(current []string) []string {
+		return slices.DeleteFunc(current, func(tag string) bool { return slices.Contains(tags, tag) })
+	})
+}
+
+func (dr *DevicesResource) updateTags(ctx context.Context, deviceID string, tags []string, opts []WriteOption, modify func(current []string) []string) ([]string, error) {
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, "tag:") || len(tag) == len("tag:") {
+			return nil, fmt.Errorf("invalid tag %q, tags must start with \"tag:\"", tag)
+		}
+	}
+
+	device, err := dr.Get(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	updated := modify(slices.Clone(device.Tags))
+	if slices.Equal(updated, device.Tags) {
+		return updated, nil
+	}
+	if err := dr.SetTags(ctx, deviceID, updated, opts...); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
 // DeviceKey type represents the properties of the key of an individual device within
 // the tailnet.
 type DeviceKey struct {
@@ -177,8 +578,8 @@ type DeviceKey struct {
 }
 
 // SetKey updates the properties of a device's key.
-func (dr *DevicesResource) SetKey(ctx context.Context, deviceID string, key DeviceKey) error {
-	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "key"), requestBody(key))
+func (dr *DevicesResource) SetKey(ctx context.Context, deviceID string, key DeviceKey, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "key"), requestBody(key), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -187,10 +588,43 @@ func (dr *DevicesResource) SetKey(ctx context.Context, deviceID string, key Devi
 }
 
 // SetDeviceIPv4Address sets the Tailscale IPv4 address of the device.
-func (dr *DevicesResource) SetIPv4Address(ctx context.Context, deviceID string, ipv4Address string) error {
+func (dr *DevicesResource) SetIPv4Address(ctx context.Context, deviceID string, ipv4Address string, opts ...WriteOption) error {
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "ip"), requestBody(map[string]string{
 		"ipv4": ipv4Address,
-	}))
+	}), writeOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	return dr.do(req, nil)
+}
+
+var (
+	tailscaleIPv4Range = netip.MustParsePrefix("100.64.0.0/10")
+	tailscaleIPv6Range = netip.MustParsePrefix("fd7a:115c:a1e0::/48")
+)
+
+// SetIP sets the Tailscale IPv4 or IPv6 address of the device, depending on the family of addr.
+// IPv4 addresses must be within 100.64.0.0/10, and IPv6 addresses within fd7a:115c:a1e0::/48.
+// Setting IPv6 addresses is only possible where the API supports it; otherwise the API rejects
+// the request.
+func (dr *DevicesResource) SetIP(ctx context.Context, deviceID string, addr netip.Addr, opts ...WriteOption) error {
+	addr = addr.Unmap()
+	var family string
+	switch {
+	case !addr.IsValid():
+		return errors.New("invalid IP address")
+	case addr.Is4() && tailscaleIPv4Range.Contains(addr):
+		family = "ipv4"
+	case addr.Is6() && tailscaleIPv6Range.Contains(addr):
+		family = "ipv6"
+	default:
+		return fmt.Errorf("%v is not within the Tailscale address ranges %v and %v", addr, tailscaleIPv4Range, tailscaleIPv6Range)
+	}
+
+	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "ip"), requestBody(map[string]string{
+		family: addr.String(),
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -200,10 +634,14 @@ func (dr *DevicesResource) SetIPv4Address(ctx context.Context, deviceID string,
 
 // SetSubnetRoutes sets which subnet routes are enabled to be routed by a device by replacing the existing list
 // of subnet routes with the supplied routes. Routes can be enabled without a device advertising them (e.g. for preauth).
-func (dr *DevicesResource) SetSubnetRoutes(ctx context.Context, deviceID string, routes []string) error {
+// Routes must be valid IP prefixes without bits set beyond their length, which is checked before making any request.
+func (dr *DevicesResource) SetSubnetRoutes(ctx context.Context, deviceID string, routes []string, opts ...WriteOption) error {
+	if err := validateRoutes(routes); err != nil {
+		return err
+	}
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildURL("device", deviceID, "routes"), requestBody(map[string][]string{
 		"routes": routes,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -211,14 +649,147 @@ func (dr *DevicesResource) SetSubnetRoutes(ctx context.Context, deviceID string,
 	return dr.do(req, nil)
 }
 
+// SetSubnetPrefixes is like [DevicesResource.SetSubnetRoutes], but takes typed prefixes.
+func (dr *DevicesResource) SetSubnetPrefixes(ctx context.Context, deviceID string, prefixes []netip.Prefix, opts ...WriteOption) error {
+	routes := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		routes[i] = prefix.String()
+	}
+	return dr.SetSubnetRoutes(ctx, deviceID, routes, opts...)
+}
+
+// EnableRoutes enables the given subnet routes for the device identified by deviceID, in addition
+// to the routes that are already enabled, and returns the resulting routes.
+//
+// Since the API only supports replacing the list of enabled routes, EnableRoutes reads the current
+// routes, merges the change, and writes them back. The routes are then read again to detect
+// concurrent writers, in which case the update is retried up to 3 times.
+func (dr *DevicesResource) EnableRoutes(ctx context.Context, deviceID string, routes []string, opts ...WriteOption) (*DeviceRoutes, error) {
+	if err := validateRoutes(routes); err != nil {
+		return nil, err
+	}
+	return dr.updateRoutes(ctx, deviceID, opts, func(enabled []string) []string {
+		for _, route := range routes {
+			if !slices.Contains(enabled, route) {
+				enabled = append(enabled, route)
+			}
+		}
+		return enabled
+	})
+}
+
+// DisableRoutes disables the given subnet routes for the device identified by deviceID, leaving
+// other enabled routes unchanged, and returns the resulting routes. Like [DevicesResource.EnableRoutes],
+// it retries the update if it detects concurrent writers.
+func (dr *DevicesResource) DisableRoutes(ctx context.Context, deviceID string, routes []string, opts ...WriteOption) (*DeviceRoutes, error) {
+	if err := validateRoutes(routes); err != nil {
+		return nil, err
+	}
+	return dr.updateRoutes(ctx, deviceID, opts, func(enabled []string) []string {
+		return slices.DeleteFunc(enabled, func(route string) bool { return slices.Contains(routes, route) })
+	})
+}
+
+const routeUpdateAttempts = 3
+
+// updateRoutes performs a read-modify-write of the enabled routes of a device, verifying the
+// result and retrying when another writer changed the routes concurrently.
+func (dr *DevicesResource) updateRoutes(ctx context.Context, deviceID string, opts []WriteOption, modify func(enabled []string) []string) (*DeviceRoutes, error) {
+	current, err := dr.SubnetRoutes(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	for range routeUpdateAttempts {
+		want := modify(slices.Clone(current.Enabled))
+		if sameSet(want, current.Enabled) {
+			return current, nil
+		}
+		if err := dr.SetSubnetRoutes(ctx, deviceID, want, opts...); err != nil {
+			return nil, err
+		}
+		if current, err = dr.SubnetRoutes(ctx, deviceID); err != nil {
+			return nil, err
+		}
+		if sameSet(want, current.Enabled) {
+			return current, nil
+		}
+	}
+	return nil, fmt.Errorf("routes of device %s were modified concurrently, giving up after %d attempts", deviceID, routeUpdateAttempts)
+}
+
+// sameSet reports whether a and b contain the same elements, in any order.
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// validateRoutes reports an error if any of routes is not a valid IP prefix, or has bits set
+// beyond its prefix length, such as "10.0.0.1/24".
+func validateRoutes(routes []string) error {
+	for _, route := range routes {
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			return fmt.Errorf("invalid route %q: %w", route, err)
+		}
+		if masked := prefix.Masked(); masked != prefix {
+			return fmt.Errorf("invalid route %q: has non-address bits set, expected %q", route, masked)
+		}
+	}
+	return nil
+}
+
 // SubnetRoutes Retrieves the list of subnet routes that a device is advertising, as well as those that are
 // enabled for it. Enabled routes are not necessarily advertised (e.g. for pre-enabling), and likewise, advertised
 // routes are not necessarily enabled.
-func (dr *DevicesResource) SubnetRoutes(ctx context.Context, deviceID string) (*DeviceRoutes, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildURL("device", deviceID, "routes"))
+func (dr *DevicesResource) SubnetRoutes(ctx context.Context, deviceID string, opts ...GetOption) (*DeviceRoutes, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildURL("device", deviceID, "routes"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
 
 	return body[DeviceRoutes](dr, req)
 }
+
+// OSUpdateReport summarizes the update posture of the devices running an operating system.
+type OSUpdateReport struct {
+	OS string
+	// Devices is the number of devices running OS.
+	Devices int
+	// UpdateAvailable is the number of devices for which a Tailscale client update is available.
+	UpdateAvailable int
+	// ClientVersions maps each Tailscale client version to the number of devices running it.
+	ClientVersions map[string]int
+}
+
+// UpdateReport summarizes the update posture of every device in the tailnet, per operating system,
+// ordered by operating system. The API does not report whether auto-updates are enabled on
+// individual devices; see [TailnetSettings].DevicesAutoUpdatesOn for the tailnet-wide default.
+func (dr *DevicesResource) UpdateReport(ctx context.Context, opts ...ListOption) ([]OSUpdateReport, error) {
+	devices, err := dr.List(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make(map[string]*OSUpdateReport)
+	for _, d := range devices {
+		report, ok := reports[d.OS]
+		if !ok {
+			report = &OSUpdateReport{OS: d.OS, ClientVersions: make(map[string]int)}
+			reports[d.OS] = report
+		}
+		report.Devices++
+		if d.UpdateAvailable {
+			report.UpdateAvailable++
+		}
+		report.ClientVersions[d.ClientVersion]++
+	}
+
+	result := make([]OSUpdateReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, *report)
+	}
+	slices.SortFunc(result, func(a, b OSUpdateReport) int { return strings.Compare(a.OS, b.OS) })
+	return result, nil
+}
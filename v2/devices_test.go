@@ -8,11 +8,17 @@ import (
 	_ "embed"
 	"encoding/json"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
 )
 
 var (
@@ -24,10 +30,11 @@ func TestClient_SetDeviceSubnetRoutes(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	const deviceID = "test"
-	routes := []string{"127.0.0.1"}
+	routes := []string{"127.0.0.1/32"}
 
 	assert.NoError(t, client.Devices().SetSubnetRoutes(context.Background(), deviceID, routes))
 	assert.Equal(t, http.MethodPost, server.Method)
@@ -36,6 +43,20 @@ func TestClient_SetDeviceSubnetRoutes(t *testing.T) {
 	body := make(map[string][]string)
 	assert.NoError(t, json.Unmarshal(server.Body.Bytes(), &body))
 	assert.EqualValues(t, routes, body["routes"])
+
+	assert.NoError(t, client.Devices().SetSubnetPrefixes(context.Background(), deviceID, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}))
+	assert.JSONEq(t, `{"routes":["10.0.0.0/8"]}`, server.Body.String())
+}
+
+func TestClient_SetDeviceSubnetRoutes_Invalid(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	assert.ErrorContains(t, client.Devices().SetSubnetRoutes(context.Background(), "test", []string{"127.0.0.1"}), `invalid route "127.0.0.1"`)
+	assert.ErrorContains(t, client.Devices().SetSubnetRoutes(context.Background(), "test", []string{"10.0.0.1/8"}), `expected "10.0.0.0/8"`)
+	assert.Empty(t, server.Method)
 }
 
 func TestClient_Devices_Get(t *testing.T) {
@@ -67,6 +88,7 @@ func TestClient_Devices_Get(t *testing.T) {
 	}
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 	server.ResponseBody = expectedDevice
 
@@ -95,6 +117,7 @@ func TestClient_Devices_GetPostureAttributes(t *testing.T) {
 	}
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 	server.ResponseBody = expectedAttributes
 
@@ -137,6 +160,7 @@ func TestClient_Devices_List(t *testing.T) {
 	}
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 	server.ResponseBody = expectedDevices
 
@@ -147,6 +171,34 @@ func TestClient_Devices_List(t *testing.T) {
 	assert.EqualValues(t, expectedDevices["devices"], actualDevices)
 }
 
+func TestClient_Devices_ListIfModified(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	server.ResponseHeader.Set("ETag", `"v1"`)
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {{ID: "2"}, {ID: "1"}}}
+
+	snapshot, err := client.Devices().ListIfModified(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, snapshot.NotModified)
+	assert.Equal(t, `"v1"`, snapshot.ETag)
+	assert.Equal(t, []tsclient.Device{{ID: "1"}, {ID: "2"}}, snapshot.Devices)
+	assert.Empty(t, server.Header.Get("If-None-Match"))
+
+	server.ResponseCode = http.StatusNotModified
+	server.ResponseBody = nil
+	unchanged, err := client.Devices().ListIfModified(context.Background(), snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, server.Header.Get("If-None-Match"))
+	assert.True(t, unchanged.NotModified)
+	assert.Equal(t, snapshot.Devices, unchanged.Devices)
+
+	_, err = client.Devices().List(context.Background())
+	assert.ErrorIs(t, err, tsclient.ErrNotModified)
+}
+
 func TestDevices_Unmarshal(t *testing.T) {
 	t.Parallel()
 
@@ -197,17 +249,34 @@ func TestDevices_Unmarshal(t *testing.T) {
 					},
 					Hostname:          "foo",
 					ID:                "50053",
+					NodeID:            "nRWT3Pe6Tm11CNTRL",
 					IsExternal:        false,
 					KeyExpiryDisabled: true,
 					LastSeen: tsclient.Time{
 						time.Date(2022, 4, 15, 13, 25, 21, 0, time.UTC),
 					},
-					MachineKey:      "mkey:30dc3c061ac8b33fdc6d88a4a67b053b01b56930d78cae0cf7a164411d424c0d",
-					Name:            "foo.example.com",
-					NodeKey:         "nodekey:30dc3c061ac8b33fdc6d88a4a67b053b01b56930d78cae0cf7a164411d424c0d",
-					OS:              "linux",
-					UpdateAvailable: false,
-					User:            "foo@example.com",
+					MachineKey:       "mkey:30dc3c061ac8b33fdc6d88a4a67b053b01b56930d78cae0cf7a164411d424c0d",
+					Name:             "foo.example.com",
+					NodeKey:          "nodekey:30dc3c061ac8b33fdc6d88a4a67b053b01b56930d78cae0cf7a164411d424c0d",
+					OS:               "linux",
+					UpdateAvailable:  false,
+					User:             "foo@example.com",
+					AdvertisedRoutes: []string{"10.0.0.0/16", "192.168.1.0/24"},
+					EnabledRoutes:    []string{"10.0.0.0/16"},
+					PostureIdentity: &tsclient.DevicePostureIdentity{
+						SerialNumbers: []string{"CP74LFQJXM"},
+					},
+					ClientConnectivity: &tsclient.ClientConnectivity{
+						Endpoints: []string{"199.9.14.201:59128", "192.68.0.21:59128"},
+						Latency: map[string]tsclient.DERPRegionLatency{
+							"Dallas":        {LatencyMilliseconds: 60.463043},
+							"New York City": {LatencyMilliseconds: 31.323811, Preferred: true},
+						},
+						ClientSupports: tsclient.ClientSupports{
+							HairPinning: tsclient.PointerTo(false),
+							UDP:         true,
+						},
+					},
 				},
 			},
 		},
@@ -223,10 +292,70 @@ func TestDevices_Unmarshal(t *testing.T) {
 	}
 }
 
+func TestDevice_ParsedAddresses(t *testing.T) {
+	t.Parallel()
+
+	device := tsclient.Device{
+		Addresses:        []string{"100.101.102.103", "fd7a:115c:a1e0:ab12:4843:cd96:6265:6667"},
+		AdvertisedRoutes: []string{"10.0.0.0/16", "192.168.1.0/24"},
+		EnabledRoutes:    []string{"10.0.0.0/16"},
+	}
+
+	addrs, err := device.IPAddresses()
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("100.101.102.103"), netip.MustParseAddr("fd7a:115c:a1e0:ab12:4843:cd96:6265:6667")}, addrs)
+	advertised, err := device.AdvertisedPrefixes()
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16"), netip.MustParsePrefix("192.168.1.0/24")}, advertised)
+	enabled, err := device.EnabledPrefixes()
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}, enabled)
+
+	routes := tsclient.DeviceRoutes{Advertised: device.AdvertisedRoutes, Enabled: device.EnabledRoutes}
+	advertised, err = routes.AdvertisedPrefixes()
+	assert.NoError(t, err)
+	assert.Len(t, advertised, 2)
+	enabled, err = routes.EnabledPrefixes()
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16")}, enabled)
+
+	connectivity := tsclient.ClientConnectivity{Latency: map[string]tsclient.DERPRegionLatency{
+		"Dallas":        {LatencyMilliseconds: 60.5},
+		"New York City": {LatencyMilliseconds: 31.5, Preferred: true},
+	}}
+	region, ok := connectivity.PreferredDERPRegion()
+	assert.True(t, ok)
+	assert.Equal(t, "New York City", region)
+	assert.Equal(t, 31500*time.Microsecond, connectivity.Latency[region].Latency())
+
+	device.Addresses = append(device.Addresses, "not an address")
+	_, err = device.IPAddresses()
+	assert.Error(t, err)
+}
+
+func TestDeviceID_Validate(t *testing.T) {
+	t.Parallel()
+
+	deviceID, err := tsclient.ParseDeviceID("50053")
+	assert.NoError(t, err)
+	assert.Equal(t, tsclient.DeviceID("50053"), deviceID)
+	_, err = tsclient.ParseDeviceID("nRWT3Pe6Tm11CNTRL")
+	assert.Error(t, err)
+
+	nodeID, err := tsclient.ParseNodeID("nRWT3Pe6Tm11CNTRL")
+	assert.NoError(t, err)
+	assert.Equal(t, tsclient.NodeID("nRWT3Pe6Tm11CNTRL"), nodeID)
+	for _, invalid := range []string{"", "n", "50053", "nRWT3-CNTRL"} {
+		_, err = tsclient.ParseNodeID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
 func TestClient_DeleteDevice(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 	ctx := context.Background()
 
@@ -236,10 +365,25 @@ func TestClient_DeleteDevice(t *testing.T) {
 	assert.Equal(t, "/api/v2/device/deviceTestId", server.Path)
 }
 
+func TestClient_ExpireDevice(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	ctx := context.Background()
+
+	deviceID := "deviceTestId"
+	assert.NoError(t, client.Devices().Expire(ctx, deviceID))
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/device/deviceTestId/expire", server.Path)
+}
+
 func TestClient_DeviceSubnetRoutes(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 	server.ResponseBody = &tsclient.DeviceRoutes{
 		Advertised: []string{"127.0.0.1"},
@@ -255,10 +399,57 @@ func TestClient_DeviceSubnetRoutes(t *testing.T) {
 	assert.Equal(t, server.ResponseBody, routes)
 }
 
+// newRoutesServer returns a client for a server storing the enabled routes of a single device.
+// If ignoreWrites is true, the server discards updates, as if another writer immediately
+// restored the previous routes.
+func newRoutesServer(t *testing.T, enabled []string, ignoreWrites bool) *tsclient.Client {
+	var mu sync.Mutex
+	return apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "/api/v2/device/test/routes", r.URL.Path)
+		if r.Method == http.MethodPost {
+			var body map[string][]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if !ignoreWrites {
+				enabled = body["routes"]
+			}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(tsclient.DeviceRoutes{Enabled: enabled}))
+	}))
+}
+
+func TestClient_Devices_EnableDisableRoutes(t *testing.T) {
+	t.Parallel()
+
+	client := newRoutesServer(t, []string{"10.0.0.0/24"}, false)
+	ctx := context.Background()
+
+	routes, err := client.Devices().EnableRoutes(ctx, "test", []string{"10.1.0.0/24", "10.0.0.0/24"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24", "10.1.0.0/24"}, routes.Enabled)
+
+	routes, err = client.Devices().DisableRoutes(ctx, "test", []string{"10.0.0.0/24"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.1.0.0/24"}, routes.Enabled)
+
+	_, err = client.Devices().EnableRoutes(ctx, "test", []string{"not-a-route"})
+	assert.Error(t, err)
+}
+
+func TestClient_Devices_EnableRoutes_Conflict(t *testing.T) {
+	t.Parallel()
+
+	client := newRoutesServer(t, []string{"10.0.0.0/24"}, true)
+	_, err := client.Devices().EnableRoutes(context.Background(), "test", []string{"10.1.0.0/24"})
+	assert.ErrorContains(t, err, "modified concurrently")
+}
+
 func TestClient_SetDeviceAuthorized(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	const deviceID = "test"
@@ -278,6 +469,7 @@ func TestClient_SetDeviceName(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	const deviceID = "test"
@@ -292,10 +484,42 @@ func TestClient_SetDeviceName(t *testing.T) {
 	assert.EqualValues(t, name, body["name"])
 }
 
+func TestClient_SetDeviceName_Invalid(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	for _, name := range []string{"", "-test", "test_name", "test..example.com", strings.Repeat("a", 64)} {
+		err := client.Devices().SetName(context.Background(), "test", name)
+		var nameErr tsclient.DeviceNameError
+		assert.ErrorAs(t, err, &nameErr, name)
+	}
+	assert.Empty(t, server.Method)
+
+	assert.NoError(t, client.Devices().SetName(context.Background(), "test", "test.example.ts.net."))
+}
+
+func TestClient_SetDeviceDisplayName(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	name, err := client.Devices().SetDisplayName(context.Background(), "test", "Alice's MacBook Pro (2023)")
+	assert.NoError(t, err)
+	assert.Equal(t, "alices-macbook-pro-2023", name)
+	assert.JSONEq(t, `{"name":"alices-macbook-pro-2023"}`, server.Body.String())
+
+	_, err = client.Devices().SetDisplayName(context.Background(), "test", "!!!")
+	assert.Error(t, err)
+}
+
 func TestClient_SetDeviceTags(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	const deviceID = "test"
@@ -310,10 +534,48 @@ func TestClient_SetDeviceTags(t *testing.T) {
 	assert.EqualValues(t, tags, body["tags"])
 }
 
+func TestClient_DeleteDevicePostureAttribute(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+
+	assert.NoError(t, client.Devices().DeletePostureAttribute(context.Background(), "test", "custom:test"))
+	assert.Equal(t, http.MethodDelete, server.Method)
+	assert.Equal(t, "/api/v2/device/test/attributes/custom:test", server.Path)
+}
+
+func TestClient_Devices_AddRemoveTags(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = tsclient.Device{ID: "test", Tags: []string{"tag:a", "tag:b"}}
+	ctx := context.Background()
+
+	tags, err := client.Devices().AddTags(ctx, "test", []string{"tag:b", "tag:c"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tag:a", "tag:b", "tag:c"}, tags)
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/device/test/tags", server.Path)
+	assert.JSONEq(t, `{"tags":["tag:a","tag:b","tag:c"]}`, server.Body.String())
+
+	tags, err = client.Devices().RemoveTags(ctx, "test", []string{"tag:a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tag:b"}, tags)
+	assert.JSONEq(t, `{"tags":["tag:b"]}`, server.Body.String())
+
+	_, err = client.Devices().AddTags(ctx, "test", []string{"server"})
+	assert.Error(t, err)
+}
+
 func TestClient_SetDevicePostureAttributes(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 	server.ResponseBody = nil
 
@@ -336,10 +598,44 @@ func TestClient_SetDevicePostureAttributes(t *testing.T) {
 	assert.EqualValues(t, setRequest, receivedRequest)
 }
 
+func TestClient_SetDevicePostureAttributes_Bulk(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var paths []string
+	var expiry tsclient.Time
+	client := apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		var request tsclient.DevicePostureAttributeRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		expiry = request.Expiry
+		if strings.HasSuffix(r.URL.Path, "custom:b") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid value"}`))
+		}
+	}))
+
+	err := client.Devices().SetPostureAttributes(context.Background(), "test", map[string]tsclient.DevicePostureAttributeRequest{
+		"custom:c": tsclient.DevicePostureAttributeRequest{Value: 3}.ExpiresIn(time.Hour),
+		"custom:a": {Value: 1},
+		"custom:b": {Value: 2},
+	})
+	assert.ErrorContains(t, err, "setting posture attribute custom:b: invalid value")
+	assert.Equal(t, []string{
+		"/api/v2/device/test/attributes/custom:a",
+		"/api/v2/device/test/attributes/custom:b",
+		"/api/v2/device/test/attributes/custom:c",
+	}, paths)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiry.Time, time.Minute)
+}
+
 func TestClient_SetDeviceKey(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	const deviceID = "test"
@@ -361,6 +657,7 @@ func TestClient_SetDeviceIPv4Address(t *testing.T) {
 	t.Parallel()
 
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	const deviceID = "test"
@@ -371,9 +668,30 @@ func TestClient_SetDeviceIPv4Address(t *testing.T) {
 	assert.EqualValues(t, "/api/v2/device/"+deviceID+"/ip", server.Path)
 }
 
+func TestClient_SetDeviceIP(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	ctx := context.Background()
+
+	assert.NoError(t, client.Devices().SetIP(ctx, "test", netip.MustParseAddr("100.64.0.1")))
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/device/test/ip", server.Path)
+	assert.JSONEq(t, `{"ipv4":"100.64.0.1"}`, server.Body.String())
+
+	assert.NoError(t, client.Devices().SetIP(ctx, "test", netip.MustParseAddr("fd7a:115c:a1e0::1")))
+	assert.JSONEq(t, `{"ipv6":"fd7a:115c:a1e0::1"}`, server.Body.String())
+
+	assert.Error(t, client.Devices().SetIP(ctx, "test", netip.MustParseAddr("192.168.0.1")))
+	assert.Error(t, client.Devices().SetIP(ctx, "test", netip.Addr{}))
+}
+
 func TestClient_UserAgent(t *testing.T) {
 	t.Parallel()
 	client, server := NewTestHarness(t)
+	server.Strict = true
 	server.ResponseCode = http.StatusOK
 
 	// Check the default user-agent.
@@ -388,4 +706,160 @@ func TestClient_UserAgent(t *testing.T) {
 	}
 	assert.NoError(t, client.Devices().SetAuthorized(context.Background(), "test", true))
 	assert.Equal(t, "custom-user-agent", server.Header.Get("User-Agent"))
+
+	// Check a user-agent suffix.
+	client = &tsclient.Client{
+		APIKey:          "fake key",
+		BaseURL:         server.BaseURL,
+		UserAgentSuffix: "myapp/1.2",
+	}
+	assert.NoError(t, client.Devices().SetAuthorized(context.Background(), "test", true))
+	assert.Equal(t, "tailscale-client-go myapp/1.2", server.Header.Get("User-Agent"))
+}
+
+func TestClient_Devices_WithAllFields(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	server.ExpectMethod = http.MethodGet
+	server.ExpectQuery = url.Values{"fields": {"all"}}
+
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {{ID: "test"}}}
+	_, err := client.Devices().List(context.Background(), tsclient.WithAllFields())
+	assert.NoError(t, err)
+	assert.Equal(t, "all", server.Query.Get("fields"))
+
+	server.ResponseBody = tsclient.Device{ID: "test"}
+	_, err = client.Devices().Get(context.Background(), "test", tsclient.WithAllFields())
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v2/device/test", server.Path)
+	assert.Equal(t, "all", server.Query.Get("fields"))
+
+	server.ExpectQuery = nil
+	_, err = client.Devices().Get(context.Background(), "test")
+	assert.NoError(t, err)
+	assert.Empty(t, server.Query.Get("fields"))
+}
+
+func TestClient_Devices_ListMatching(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	now := time.Now()
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", User: "alice@example.com", OS: "linux", Tags: []string{"tag:server", "tag:prod"}, LastSeen: tsclient.Time{Time: now}},
+			{ID: "2", User: "alice@example.com", OS: "linux", Tags: []string{"tag:server"}, LastSeen: tsclient.Time{Time: now.Add(-time.Hour)}},
+			{ID: "3", User: "bob@example.com", OS: "macOS", LastSeen: tsclient.Time{Time: now}},
+		},
+	}
+
+	ids := func(filter tsclient.DeviceListOptions) []string {
+		devices, err := client.Devices().ListMatching(context.Background(), filter)
+		assert.NoError(t, err)
+		var ids []string
+		for _, d := range devices {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, ids(tsclient.DeviceListOptions{}))
+	assert.Equal(t, []string{"1"}, ids(tsclient.DeviceListOptions{Tags: []string{"tag:server", "tag:prod"}}))
+	assert.Equal(t, []string{"1", "2"}, ids(tsclient.DeviceListOptions{User: "alice@example.com"}))
+	assert.Equal(t, []string{"3"}, ids(tsclient.DeviceListOptions{OS: "macos"}))
+	assert.Equal(t, []string{"1", "3"}, ids(tsclient.DeviceListOptions{Online: tsclient.PointerTo(true)}))
+	assert.Equal(t, []string{"2"}, ids(tsclient.DeviceListOptions{Online: tsclient.PointerTo(false)}))
+	assert.Equal(t, []string{"1", "2"}, ids(tsclient.DeviceListOptions{Online: tsclient.PointerTo(true), OnlineThreshold: 2 * time.Hour, OS: "linux"}))
+}
+
+func TestClient_Devices_Find(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", Name: "laptop.example.ts.net", Hostname: "server", Addresses: []string{"100.64.0.1", "fd7a:115c:a1e0::1"}, NodeKey: "nodekey:aaa"},
+			{ID: "2", Name: "server.example.ts.net", Hostname: "server-2", Addresses: []string{"100.64.0.2"}, NodeKey: "nodekey:bbb"},
+		},
+	}
+	ctx := context.Background()
+
+	device, err := client.Devices().FindByHostname(ctx, "Server.example.ts.net.")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", device.ID)
+
+	// MagicDNS machine names take precedence over hostnames.
+	device, err = client.Devices().FindByHostname(ctx, "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", device.ID)
+
+	device, err = client.Devices().FindByHostname(ctx, "server-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", device.ID)
+
+	device, err = client.Devices().FindByIP(ctx, netip.MustParseAddr("fd7a:115c:a1e0::1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", device.ID)
+
+	device, err = client.Devices().FindByNodeKey(ctx, "bbb")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", device.ID)
+
+	_, err = client.Devices().FindByIP(ctx, netip.MustParseAddr("100.64.0.3"))
+	assert.True(t, tsclient.IsNotFound(err))
+	assert.ErrorIs(t, err, tsclient.ErrDeviceNotFound)
+}
+
+func TestClient_Devices_UpdateReport(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.Strict = true
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", OS: "linux", ClientVersion: "1.70.0", UpdateAvailable: true},
+			{ID: "2", OS: "linux", ClientVersion: "1.72.0"},
+			{ID: "3", OS: "macOS", ClientVersion: "1.70.0", UpdateAvailable: true},
+		},
+	}
+
+	report, err := client.Devices().UpdateReport(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []tsclient.OSUpdateReport{
+		{OS: "linux", Devices: 2, UpdateAvailable: 1, ClientVersions: map[string]int{"1.70.0": 1, "1.72.0": 1}},
+		{OS: "macOS", Devices: 1, UpdateAvailable: 1, ClientVersions: map[string]int{"1.70.0": 1}},
+	}, report)
+}
+
+func TestClient_Devices_History(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	now := time.Now().UTC().Truncate(time.Second)
+	server.ResponseBody = map[string][]tsclient.ConfigurationLog{
+		"logs": {
+			{EventGroupID: "3", Target: tsclient.ConfigurationLogTarget{ID: "n1CNTRL", Type: "NODE"}, Action: "DELETE", EventTime: now},
+			{EventGroupID: "2", Target: tsclient.ConfigurationLogTarget{ID: "n2CNTRL", Type: "NODE"}, Action: "UPDATE", EventTime: now},
+			{EventGroupID: "1", Target: tsclient.ConfigurationLogTarget{ID: "n1CNTRL", Type: "NODE"}, Action: "CREATE", EventTime: now.Add(-time.Hour)},
+			{EventGroupID: "4", Target: tsclient.ConfigurationLogTarget{ID: "n1CNTRL", Type: "USER"}, Action: "UPDATE", EventTime: now},
+		},
+	}
+
+	history, err := client.Devices().History(context.Background(), "n1CNTRL", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/tailnet/example.com/logging/configuration", server.Path)
+	var groups []string
+	for _, log := range history {
+		groups = append(groups, log.EventGroupID)
+	}
+	assert.Equal(t, []string{"1", "3"}, groups)
 }
@@ -0,0 +1,79 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"time"
+)
+
+const defaultWaitInterval = 2 * time.Second
+
+// WaitOptions configures how [DevicesResource.WaitUntilAuthorized] and
+// [DevicesResource.WaitUntilVisible] poll the API. The time spent waiting is bounded by the
+// context passed to them.
+type WaitOptions struct {
+	// Interval is the time to wait after the first unsuccessful attempt. Defaults to 2 seconds.
+	Interval time.Duration
+	// Multiplier is the factor by which the interval grows after every unsuccessful attempt.
+	// Values below 1 keep the interval constant.
+	Multiplier float64
+	// MaxInterval caps the interval when Multiplier is greater than 1. Zero means no cap.
+	MaxInterval time.Duration
+}
+
+// WaitUntilAuthorized polls the device with the given id until it is authorized, and returns it.
+// A device that does not exist yet is waited for, as are rate-limited requests; other errors are
+// returned immediately.
+func (dr *DevicesResource) WaitUntilAuthorized(ctx context.Context, id string, opts WaitOptions) (*Device, error) {
+	return waitFor(ctx, opts, func() (*Device, bool, error) {
+		device, err := dr.Get(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		return device, device.Authorized, nil
+	})
+}
+
+// WaitUntilVisible polls the devices of the tailnet until one matches hostname as described for
+// [DevicesResource.FindByHostname], and returns it. Rate-limited requests are retried; other
+// errors are returned immediately.
+func (dr *DevicesResource) WaitUntilVisible(ctx context.Context, hostname string, opts WaitOptions) (*Device, error) {
+	return waitFor(ctx, opts, func() (*Device, bool, error) {
+		device, err := dr.FindByHostname(ctx, hostname)
+		if err != nil {
+			return nil, false, err
+		}
+		return device, true, nil
+	})
+}
+
+// waitFor calls attempt until it reports that it is done, backing off between attempts as
+// configured by opts. Not found and rate limited errors are treated as unsuccessful attempts.
+func waitFor[T any](ctx context.Context, opts WaitOptions, attempt func() (T, bool, error)) (T, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+	for {
+		v, done, err := attempt()
+		switch {
+		case err == nil && done:
+			return v, nil
+		case err != nil && !IsNotFound(err) && !IsRateLimited(err):
+			var zero T
+			return zero, err
+		}
+		if err := sleep(ctx, interval); err != nil {
+			var zero T
+			return zero, err
+		}
+		if opts.Multiplier > 1 {
+			interval = time.Duration(float64(interval) * opts.Multiplier)
+			if opts.MaxInterval > 0 {
+				interval = min(interval, opts.MaxInterval)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+)
+
+func TestClient_Devices_WaitUntilAuthorized(t *testing.T) {
+	t.Parallel()
+
+	// The device is first missing, then unauthorized, then authorized.
+	var gets atomic.Int32
+	client := apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch gets.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+		case 2:
+			assert.NoError(t, json.NewEncoder(w).Encode(tsclient.Device{ID: "test"}))
+		default:
+			assert.NoError(t, json.NewEncoder(w).Encode(tsclient.Device{ID: "test", Authorized: true}))
+		}
+	}))
+
+	device, err := client.Devices().WaitUntilAuthorized(context.Background(), "test", tsclient.WaitOptions{
+		Interval:    time.Millisecond,
+		Multiplier:  2,
+		MaxInterval: 3 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.True(t, device.Authorized)
+	assert.EqualValues(t, 3, gets.Load())
+}
+
+func TestClient_Devices_WaitUntilVisible(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {{ID: "1", Name: "other.example.ts.net"}, {ID: "2", Name: "ci-runner.example.ts.net"}}}
+
+	device, err := client.Devices().WaitUntilVisible(context.Background(), "ci-runner", tsclient.WaitOptions{Interval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, "2", device.ID)
+
+	// Errors other than not found and rate limited are returned immediately.
+	server.ResponseCode = http.StatusForbidden
+	server.ResponseBody = tsclient.APIError{Message: "forbidden"}
+	_, err = client.Devices().WaitUntilVisible(context.Background(), "ci-runner", tsclient.WaitOptions{Interval: time.Millisecond})
+	assert.ErrorContains(t, err, "forbidden")
+}
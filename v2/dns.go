@@ -24,10 +24,10 @@ type DNSPreferences struct {
 }
 
 // SetSearchPaths replaces the list of search paths with the list supplied by the user and returns an error otherwise.
-func (dr *DNSResource) SetSearchPaths(ctx context.Context, searchPaths []string) error {
+func (dr *DNSResource) SetSearchPaths(ctx context.Context, searchPaths []string, opts ...WriteOption) error {
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildTailnetURL("dns", "searchpaths"), requestBody(map[string][]string{
 		"searchPaths": searchPaths,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -36,8 +36,8 @@ func (dr *DNSResource) SetSearchPaths(ctx context.Context, searchPaths []string)
 }
 
 // SearchPaths retrieves the list of search paths that is currently set for the given tailnet.
-func (dr *DNSResource) SearchPaths(ctx context.Context) ([]string, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "searchpaths"))
+func (dr *DNSResource) SearchPaths(ctx context.Context, opts ...GetOption) ([]string, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "searchpaths"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -52,10 +52,10 @@ func (dr *DNSResource) SearchPaths(ctx context.Context) ([]string, error) {
 
 // SetNameservers replaces the list of DNS nameservers for the given tailnet with the list supplied by the user. Note
 // that changing the list of DNS nameservers may also affect the status of MagicDNS (if MagicDNS is on).
-func (dr *DNSResource) SetNameservers(ctx context.Context, dns []string) error {
+func (dr *DNSResource) SetNameservers(ctx context.Context, dns []string, opts ...WriteOption) error {
 	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildTailnetURL("dns", "nameservers"), requestBody(map[string][]string{
 		"dns": dns,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -64,8 +64,8 @@ func (dr *DNSResource) SetNameservers(ctx context.Context, dns []string) error {
 }
 
 // Nameservers lists the DNS nameservers for the tailnet
-func (dr *DNSResource) Nameservers(ctx context.Context) ([]string, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "nameservers"))
+func (dr *DNSResource) Nameservers(ctx context.Context, opts ...GetOption) ([]string, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "nameservers"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -86,8 +86,8 @@ func (dr *DNSResource) Nameservers(ctx context.Context) ([]string, error) {
 // associated with that domain. Values provided for domains will overwrite the
 // current value associated with the domain. Domains not included in the request
 // will remain unchanged.
-func (dr *DNSResource) UpdateSplitDNS(ctx context.Context, request SplitDNSRequest) (SplitDNSResponse, error) {
-	req, err := dr.buildRequest(ctx, http.MethodPatch, dr.buildTailnetURL("dns", "split-dns"), requestBody(request))
+func (dr *DNSResource) UpdateSplitDNS(ctx context.Context, request SplitDNSRequest, opts ...WriteOption) (SplitDNSResponse, error) {
+	req, err := dr.buildRequest(ctx, http.MethodPatch, dr.buildTailnetURL("dns", "split-dns"), requestBody(request), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +104,8 @@ func (dr *DNSResource) UpdateSplitDNS(ctx context.Context, request SplitDNSReque
 // data structure.
 //
 // Passing in an empty [SplitDNSRequest] will unset all split DNS mappings for the tailnet.
-func (dr *DNSResource) SetSplitDNS(ctx context.Context, request SplitDNSRequest) error {
-	req, err := dr.buildRequest(ctx, http.MethodPut, dr.buildTailnetURL("dns", "split-dns"), requestBody(request))
+func (dr *DNSResource) SetSplitDNS(ctx context.Context, request SplitDNSRequest, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodPut, dr.buildTailnetURL("dns", "split-dns"), requestBody(request), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -114,8 +114,8 @@ func (dr *DNSResource) SetSplitDNS(ctx context.Context, request SplitDNSRequest)
 }
 
 // SplitDNS retrieves the split DNS configuration for the tailnet.
-func (dr *DNSResource) SplitDNS(ctx context.Context) (SplitDNSResponse, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "split-dns"))
+func (dr *DNSResource) SplitDNS(ctx context.Context, opts ...GetOption) (SplitDNSResponse, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "split-dns"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -128,8 +128,8 @@ func (dr *DNSResource) SplitDNS(ctx context.Context) (SplitDNSResponse, error) {
 }
 
 // Preferences retrieves the DNS preferences that are currently set for the given tailnet.
-func (dr *DNSResource) Preferences(ctx context.Context) (*DNSPreferences, error) {
-	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "preferences"))
+func (dr *DNSResource) Preferences(ctx context.Context, opts ...GetOption) (*DNSPreferences, error) {
+	req, err := dr.buildRequest(ctx, http.MethodGet, dr.buildTailnetURL("dns", "preferences"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -139,8 +139,8 @@ func (dr *DNSResource) Preferences(ctx context.Context) (*DNSPreferences, error)
 
 // SetPreferences replaces the DNS preferences for the tailnet, specifically, the MagicDNS setting. Note that MagicDNS
 // is dependent on DNS servers.
-func (dr *DNSResource) SetPreferences(ctx context.Context, preferences DNSPreferences) error {
-	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildTailnetURL("dns", "preferences"), requestBody(preferences))
+func (dr *DNSResource) SetPreferences(ctx context.Context, preferences DNSPreferences, opts ...WriteOption) error {
+	req, err := dr.buildRequest(ctx, http.MethodPost, dr.buildTailnetURL("dns", "preferences"), requestBody(preferences), writeOptions(opts))
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,15 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Package experimental provides access to Tailscale API endpoints that are still subject to change,
+// such as endpoints in alpha or beta.
+//
+// This package is NOT covered by the compatibility guarantees of the rest of this module. Its types
+// and functions may change or be removed in any release, including patch releases, following
+// changes to the API. Once an endpoint is stable, it moves to the tsclient package.
+//
+// Resources of this package wrap a [tsclient.Client], sharing its configuration and authentication:
+//
+//	services := experimental.VIPServices(client)
+//	list, err := services.List(ctx)
+package experimental
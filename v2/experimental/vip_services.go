@@ -0,0 +1,81 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package experimental
+
+import (
+	"context"
+	"net/http"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+// VIPServicesResource provides access to the VIP services of a tailnet, which are in beta.
+type VIPServicesResource struct {
+	client *tsclient.Client
+}
+
+// VIPServices returns a [VIPServicesResource] for the tailnet of client.
+func VIPServices(client *tsclient.Client) *VIPServicesResource {
+	return &VIPServicesResource{client: client}
+}
+
+// VIPService is a service hosted by one or more devices of the tailnet, reachable at its own
+// Tailscale IP addresses.
+type VIPService struct {
+	// Name is the name of the service, such as "svc:web".
+	Name        string            `json:"name"`
+	Addrs       []string          `json:"addrs,omitempty"`
+	Comment     string            `json:"comment,omitempty"`
+	Ports       []string          `json:"ports,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// List lists every [VIPService] of the tailnet.
+func (vr *VIPServicesResource) List(ctx context.Context) ([]VIPService, error) {
+	req, err := vr.client.NewRequest(ctx, http.MethodGet, vr.client.BuildTailnetURL("vip-services"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string][]VIPService)
+	if err := vr.client.Do(req, &m); err != nil {
+		return nil, err
+	}
+	return m["vipServices"], nil
+}
+
+// Get gets the [VIPService] with the given name.
+func (vr *VIPServicesResource) Get(ctx context.Context, name string) (*VIPService, error) {
+	req, err := vr.client.NewRequest(ctx, http.MethodGet, vr.client.BuildTailnetURL("vip-services", name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var service VIPService
+	if err := vr.client.Do(req, &service); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// Set creates or replaces the [VIPService] with the name of service.
+func (vr *VIPServicesResource) Set(ctx context.Context, service VIPService) error {
+	req, err := vr.client.NewRequest(ctx, http.MethodPut, vr.client.BuildTailnetURL("vip-services", service.Name), service)
+	if err != nil {
+		return err
+	}
+
+	return vr.client.Do(req, nil)
+}
+
+// Delete deletes the [VIPService] with the given name.
+func (vr *VIPServicesResource) Delete(ctx context.Context, name string) error {
+	req, err := vr.client.NewRequest(ctx, http.MethodDelete, vr.client.BuildTailnetURL("vip-services", name), nil)
+	if err != nil {
+		return err
+	}
+
+	return vr.client.Do(req, nil)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package experimental_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tailscale/tailscale-client-go/v2/experimental"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+)
+
+func TestVIPServices(t *testing.T) {
+	t.Parallel()
+
+	var method, path, body string
+	client := apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		user, _, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "not a real key", user)
+
+		if r.Method == http.MethodGet {
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string][]experimental.VIPService{
+				"vipServices": {{Name: "svc:web", Ports: []string{"tcp:443"}}},
+			}))
+		}
+	}))
+	services := experimental.VIPServices(client)
+	ctx := context.Background()
+
+	list, err := services.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []experimental.VIPService{{Name: "svc:web", Ports: []string{"tcp:443"}}}, list)
+	assert.Equal(t, "/api/v2/tailnet/example.com/vip-services", path)
+
+	require.NoError(t, services.Set(ctx, experimental.VIPService{Name: "svc:web", Comment: "web"}))
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/api/v2/tailnet/example.com/vip-services/svc:web", path)
+	assert.JSONEq(t, `{"name":"svc:web","comment":"web"}`, body)
+
+	require.NoError(t, services.Delete(ctx, "svc:web"))
+	assert.Equal(t, http.MethodDelete, method)
+}
@@ -4,12 +4,12 @@ go 1.22.0
 
 require (
 	github.com/stretchr/testify v1.9.0
-	github.com/tailscale/hujson v0.0.0-20220506213045-af5ed07155e5
+	github.com/tailscale/hujson v0.0.0-20221223112325-20486734a56a
 	golang.org/x/oauth2 v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,114 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Names of application capabilities defined by Tailscale, for use as keys of [ACLGrant].App.
+const (
+	// GrantAppDrive grants access to Taildrive shares, with [DriveCapability] parameters.
+	GrantAppDrive = "tailscale.com/cap/drive"
+	// GrantAppKubernetes grants access to the Kubernetes API server proxy, with
+	// [KubernetesCapability] parameters.
+	GrantAppKubernetes = "tailscale.com/cap/kubernetes"
+)
+
+// DriveCapability holds the parameters of the [GrantAppDrive] capability. More details:
+// https://tailscale.com/kb/1369/taildrive
+type DriveCapability struct {
+	// Shares lists the names of the shares that can be accessed, or "*" for all shares.
+	Shares []string `json:"shares"`
+	// Access is either [DriveAccessReadOnly] or [DriveAccessReadWrite].
+	Access string `json:"access"`
+}
+
+const (
+	DriveAccessReadOnly  = "ro"
+	DriveAccessReadWrite = "rw"
+)
+
+// KubernetesCapability holds the parameters of the [GrantAppKubernetes] capability. More details:
+// https://tailscale.com/kb/1437/kubernetes-operator-api-server-proxy
+type KubernetesCapability struct {
+	Impersonate *KubernetesImpersonation `json:"impersonate,omitempty"`
+	// Recorder lists the tags of the devices recording kubectl exec sessions.
+	Recorder []string `json:"recorder,omitempty"`
+	// EnforceRecorder fails sessions that cannot be recorded.
+	EnforceRecorder bool `json:"enforceRecorder,omitempty"`
+}
+
+// KubernetesImpersonation lists the Kubernetes groups that the proxy impersonates for requests.
+type KubernetesImpersonation struct {
+	Groups []string `json:"groups"`
+}
+
+// SetApp grants the application capability name, such as [GrantAppDrive], with the given
+// parameters. Each of params is marshalled to a JSON object, so they can be typed structures
+// such as [DriveCapability]. Calling SetApp again with the same name replaces its parameters.
+func (g *ACLGrant) SetApp(name string, params ...any) error {
+	objects := make([]map[string]any, 0, len(params))
+	for _, p := range params {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshalling parameters of %s: %w", name, err)
+		}
+		var object map[string]any
+		if err := json.Unmarshal(data, &object); err != nil {
+			return fmt.Errorf("parameters of %s must be JSON objects: %w", name, err)
+		}
+		objects = append(objects, object)
+	}
+	if g.App == nil {
+		g.App = make(map[string][]map[string]any)
+	}
+	g.App[name] = objects
+	return nil
+}
+
+// GrantAppParams decodes the parameters of the application capability name granted by g into
+// values of type T, such as [DriveCapability]. It returns nil if g does not grant name.
+func GrantAppParams[T any](g ACLGrant, name string) ([]T, error) {
+	objects, ok := g.App[name]
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
+	var params []T
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("decoding parameters of %s: %w", name, err)
+	}
+	return params, nil
+}
+
+// Validate checks that the grant has sources and destinations, grants network or application
+// capabilities, routes only through tagged devices, and that its application capability names
+// are of the form "example.com/cap/name".
+func (g ACLGrant) Validate() error {
+	var errs []error
+	if len(g.Source) == 0 || len(g.Destination) == 0 {
+		errs = append(errs, errors.New("grant must have at least one source and destination"))
+	}
+	if len(g.IP) == 0 && len(g.App) == 0 {
+		errs = append(errs, errors.New("grant must have ip or app capabilities"))
+	}
+	for _, via := range g.Via {
+		if !strings.HasPrefix(via, TagPrefix) {
+			errs = append(errs, fmt.Errorf("invalid via %q, must be a tag", via))
+		}
+	}
+	for _, name := range sortedKeys(g.App) {
+		if domain, _, ok := strings.Cut(name, "/"); !ok || !strings.Contains(domain, ".") {
+			errs = append(errs, fmt.Errorf("invalid app capability %q, must be of the form example.com/cap/name", name))
+		}
+	}
+	return errors.Join(errs...)
+}
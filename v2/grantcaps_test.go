@@ -0,0 +1,65 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestACLGrant_App(t *testing.T) {
+	t.Parallel()
+
+	grant := tsclient.ACLGrant{Source: []string{"group:eng"}, Destination: []string{"tag:k8s-operator"}}
+	require.NoError(t, grant.SetApp(tsclient.GrantAppKubernetes, tsclient.KubernetesCapability{
+		Impersonate: &tsclient.KubernetesImpersonation{Groups: []string{"system:masters"}},
+		Recorder:    []string{"tag:recorder"},
+	}))
+	require.NoError(t, grant.Validate())
+
+	data, err := json.Marshal(grant)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"src": ["group:eng"],
+		"dst": ["tag:k8s-operator"],
+		"app": {"tailscale.com/cap/kubernetes": [{"impersonate": {"groups": ["system:masters"]}, "recorder": ["tag:recorder"]}]}
+	}`, string(data))
+
+	params, err := tsclient.GrantAppParams[tsclient.KubernetesCapability](grant, tsclient.GrantAppKubernetes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system:masters"}, params[0].Impersonate.Groups)
+
+	params, err = tsclient.GrantAppParams[tsclient.KubernetesCapability](grant, tsclient.GrantAppDrive)
+	require.NoError(t, err)
+	assert.Nil(t, params)
+
+	assert.EqualError(t, grant.SetApp(tsclient.GrantAppDrive, []string{"docs"}), "parameters of tailscale.com/cap/drive must be JSON objects: json: cannot unmarshal array into Go value of type map[string]interface {}")
+}
+
+func TestACLGrant_Validate(t *testing.T) {
+	t.Parallel()
+
+	grant := tsclient.ACLGrant{
+		Destination: []string{"tag:web"},
+		Via:         []string{"exit-node"},
+		App:         map[string][]map[string]any{"drive": {}},
+	}
+	assert.EqualError(t, grant.Validate(), `grant must have at least one source and destination
+invalid via "exit-node", must be a tag
+invalid app capability "drive", must be of the form example.com/cap/name`)
+}
+
+func TestACL_Grants_Drive(t *testing.T) {
+	t.Parallel()
+
+	acl, err := tsclient.ParseACL(string(huJSONACL))
+	require.NoError(t, err)
+	params, err := tsclient.GrantAppParams[tsclient.DriveCapability](acl.Grants[1], tsclient.GrantAppDrive)
+	require.NoError(t, err)
+	assert.Equal(t, []tsclient.DriveCapability{{Shares: []string{"docs"}, Access: tsclient.DriveAccessReadOnly}}, params)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// MutationGuard is evaluated before every mutating request made by a [Client], that is, every
+// request with a method other than GET, HEAD or OPTIONS. tailnet is the tailnet the [Client] is
+// configured for. If the guard returns an error, the request is not sent and the error is returned
+// to the caller.
+//
+// Note that some requests using mutating methods do not modify the tailnet, such as validating a
+// policy file, and are guarded as well.
+type MutationGuard func(tailnet string, req *http.Request) error
+
+// AllowTailnets returns a [MutationGuard] that only allows mutating the given tailnets.
+func AllowTailnets(tailnets ...string) MutationGuard {
+	return func(tailnet string, req *http.Request) error {
+		if !slices.Contains(tailnets, tailnet) {
+			return fmt.Errorf("tailnet %q is not in the list of tailnets allowed to be modified", tailnet)
+		}
+		return nil
+	}
+}
+
+type mutationConfirmedKey struct{}
+
+// ConfirmMutations returns a copy of ctx that satisfies the [MutationGuard] returned by
+// [RequireConfirmation].
+func ConfirmMutations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mutationConfirmedKey{}, true)
+}
+
+// RequireConfirmation returns a [MutationGuard] that only allows mutating requests made with a
+// context returned by [ConfirmMutations].
+func RequireConfirmation() MutationGuard {
+	return func(tailnet string, req *http.Request) error {
+		if confirmed, _ := req.Context().Value(mutationConfirmedKey{}).(bool); !confirmed {
+			return fmt.Errorf("modifying tailnet %q requires confirmation", tailnet)
+		}
+		return nil
+	}
+}
+
+// checkMutationGuard evaluates the Client's MutationGuard, if any, for the given request.
+func (c *Client) checkMutationGuard(req *http.Request) error {
+	if c.MutationGuard == nil {
+		return nil
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	tailnet := c.Tailnet
+	if tailnet == "" {
+		tailnet = defaultTailnet
+	}
+	if err := c.MutationGuard(tailnet, req); err != nil {
+		return fmt.Errorf("%s %s blocked by mutation guard: %w", req.Method, req.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestMutationGuard_AllowTailnets(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{}
+	client.MutationGuard = tsclient.AllowTailnets("staging.example.com")
+
+	err := client.Devices().Delete(context.Background(), "test")
+	assert.EqualError(t, err, `DELETE /api/v2/device/test blocked by mutation guard: tailnet "example.com" is not in the list of tailnets allowed to be modified`)
+	assert.Empty(t, server.Method, "request should not have been sent")
+
+	_, err = client.Devices().List(context.Background())
+	assert.NoError(t, err, "reads should not be guarded")
+
+	assert.NoError(t, client.ForTailnet("staging.example.com").Devices().Delete(context.Background(), "test"))
+	assert.Equal(t, http.MethodDelete, server.Method)
+}
+
+func TestMutationGuard_RequireConfirmation(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	client.MutationGuard = tsclient.RequireConfirmation()
+
+	err := client.Devices().Delete(context.Background(), "test")
+	assert.EqualError(t, err, `DELETE /api/v2/device/test blocked by mutation guard: modifying tailnet "example.com" requires confirmation`)
+
+	assert.NoError(t, client.Devices().Delete(tsclient.ConfirmMutations(context.Background()), "test"))
+	assert.Equal(t, http.MethodDelete, server.Method)
+}
@@ -0,0 +1,28 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Package apitest provides a fake API server for tests that need to control how each request is
+// answered, such as to simulate state that changes between requests.
+package apitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+// NewClient starts a server that answers requests using handler until the test ends, and returns a
+// [tsclient.Client] for the tailnet "example.com" that sends its requests to it.
+func NewClient(t testing.TB, handler http.Handler) *tsclient.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return &tsclient.Client{BaseURL: baseURL, APIKey: "not a real key", Tailnet: "example.com"}
+}
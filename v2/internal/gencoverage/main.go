@@ -0,0 +1,161 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Command gencoverage generates the coverage.json manifest embedded in the tsclient package by
+// inspecting the methods of its resources. The since version of entries that already exist in
+// the manifest is preserved, and new entries are marked as unreleased.
+//
+// Usage:
+//
+//	go run ./internal/gencoverage
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const manifestFile = "coverage.json"
+const unreleased = "unreleased"
+
+type entry struct {
+	Resource   string `json:"resource"`
+	Method     string `json:"method"`
+	HTTPMethod string `json:"httpMethod,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Since      string `json:"since"`
+}
+
+func main() {
+	existing := make(map[string]string)
+	data, err := os.ReadFile(manifestFile)
+	switch {
+	case err == nil:
+		var entries []entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			log.Fatalf("parsing %s: %v", manifestFile, err)
+		}
+		for _, e := range entries {
+			existing[e.Resource+"."+e.Method] = e.Since
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		log.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var entries []entry
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || !fn.Name.IsExported() {
+					continue
+				}
+				resource, ok := resourceName(fn.Recv.List[0].Type)
+				if !ok {
+					continue
+				}
+				e := entry{Resource: resource, Method: fn.Name.Name}
+				e.HTTPMethod, e.Path = endpoint(fn.Body)
+				e.Since = existing[e.Resource+"."+e.Method]
+				if e.Since == "" {
+					e.Since = unreleased
+				}
+				entries = append(entries, e)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Resource != entries[j].Resource {
+			return entries[i].Resource < entries[j].Resource
+		}
+		return entries[i].Method < entries[j].Method
+	})
+
+	out, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(manifestFile, append(out, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// resourceName returns the name of the resource for a receiver of type *<Name>Resource.
+func resourceName(recv ast.Expr) (string, bool) {
+	star, ok := recv.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(ident.Name, "Resource")
+}
+
+// endpoint returns the HTTP method and path of the request built in body, or empty strings if
+// body does not build exactly one request itself.
+func endpoint(body *ast.BlockStmt) (method, path string) {
+	var methods, paths []string
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok && x.Name == "http" && strings.HasPrefix(n.Sel.Name, "Method") {
+				methods = append(methods, strings.ToUpper(strings.TrimPrefix(n.Sel.Name, "Method")))
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			var prefix string
+			switch sel.Sel.Name {
+			case "buildURL":
+				prefix = "/api/v2"
+			case "buildTailnetURL":
+				prefix = "/api/v2/tailnet/{tailnet}"
+			default:
+				return true
+			}
+			elems := []string{prefix}
+			for _, arg := range n.Args {
+				elems = append(elems, pathElement(arg))
+			}
+			paths = append(paths, strings.Join(elems, "/"))
+		}
+		return true
+	})
+	if len(methods) != 1 || len(paths) != 1 {
+		return "", ""
+	}
+	return methods[0], paths[0]
+}
+
+func pathElement(arg ast.Expr) string {
+	switch arg := arg.(type) {
+	case *ast.BasicLit:
+		if s, err := strconv.Unquote(arg.Value); err == nil {
+			return s
+		}
+	case *ast.Ident:
+		return "{" + arg.Name + "}"
+	}
+	return "{}"
+}
@@ -0,0 +1,136 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InventoryFormat is the output format of a device inventory export.
+type InventoryFormat string
+
+const (
+	// InventoryCSV formats the inventory as CSV with a header row. Lists are joined with spaces.
+	InventoryCSV InventoryFormat = "csv"
+	// InventoryNDJSON formats the inventory as newline-delimited JSON, one device per line.
+	InventoryNDJSON InventoryFormat = "ndjson"
+)
+
+// InventoryColumns are the columns of a device inventory export, in order. They are also the keys
+// of the JSON objects of an NDJSON export. Columns are only ever appended to this list, so that
+// consumers of exports can rely on it.
+var InventoryColumns = []string{
+	"id",
+	"nodeId",
+	"name",
+	"hostname",
+	"user",
+	"os",
+	"clientVersion",
+	"addresses",
+	"tags",
+	"authorized",
+	"isExternal",
+	"created",
+	"lastSeen",
+	"expires",
+	"keyExpiryDisabled",
+	"updateAvailable",
+	"advertisedRoutes",
+	"enabledRoutes",
+}
+
+// ExportInventory lists the devices of the tailnet and writes them to w in the given format,
+// using [InventoryColumns]. Use [WithAllFields] to include the routes of devices.
+func (dr *DevicesResource) ExportInventory(ctx context.Context, w io.Writer, format InventoryFormat, opts ...ListOption) error {
+	devices, err := dr.List(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	return WriteDeviceInventory(w, format, devices)
+}
+
+// WriteDeviceInventory writes devices to w in the given format, using [InventoryColumns].
+// Times are formatted as RFC 3339 in UTC, and left empty when unknown.
+func WriteDeviceInventory(w io.Writer, format InventoryFormat, devices []Device) error {
+	switch format {
+	case InventoryCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(InventoryColumns); err != nil {
+			return err
+		}
+		for _, d := range devices {
+			record := inventoryRecord(d)
+			row := make([]string, len(InventoryColumns))
+			for i, column := range InventoryColumns {
+				switch v := record[column].(type) {
+				case string:
+					row[i] = v
+				case bool:
+					row[i] = strconv.FormatBool(v)
+				case []string:
+					row[i] = strings.Join(v, " ")
+				}
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case InventoryNDJSON:
+		enc := json.NewEncoder(w)
+		for _, d := range devices {
+			if err := enc.Encode(inventoryRecord(d)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported inventory format %q", format)
+	}
+}
+
+// inventoryRecord returns the values of the [InventoryColumns] of a device.
+func inventoryRecord(d Device) map[string]any {
+	formatTime := func(t Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	nonNil := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+		return s
+	}
+	return map[string]any{
+		"id":                d.ID,
+		"nodeId":            string(d.NodeID),
+		"name":              d.Name,
+		"hostname":          d.Hostname,
+		"user":              d.User,
+		"os":                d.OS,
+		"clientVersion":     d.ClientVersion,
+		"addresses":         nonNil(d.Addresses),
+		"tags":              nonNil(d.Tags),
+		"authorized":        d.Authorized,
+		"isExternal":        d.IsExternal,
+		"created":           formatTime(d.Created),
+		"lastSeen":          formatTime(d.LastSeen),
+		"expires":           formatTime(d.Expires),
+		"keyExpiryDisabled": d.KeyExpiryDisabled,
+		"updateAvailable":   d.UpdateAvailable,
+		"advertisedRoutes":  nonNil(d.AdvertisedRoutes),
+		"enabledRoutes":     nonNil(d.EnabledRoutes),
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Devices_ExportInventory(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{
+				ID:        "1",
+				NodeID:    "n1CNTRL",
+				Name:      "foo.example.com",
+				Addresses: []string{"100.64.0.1", "fd7a:115c:a1e0::1"},
+				Tags:      []string{"tag:server"},
+				LastSeen:  tsclient.Time{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+			},
+			{ID: "2", Name: "bar.example.com", Authorized: true},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, client.Devices().ExportInventory(context.Background(), &out, tsclient.InventoryCSV))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, tsclient.InventoryColumns, records[0])
+	row := make(map[string]string)
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+	assert.Equal(t, "n1CNTRL", row["nodeId"])
+	assert.Equal(t, "100.64.0.1 fd7a:115c:a1e0::1", row["addresses"])
+	assert.Equal(t, "2024-01-02T03:04:05Z", row["lastSeen"])
+	assert.Equal(t, "", row["created"])
+	assert.Equal(t, "false", row["authorized"])
+
+	out.Reset()
+	require.NoError(t, client.Devices().ExportInventory(context.Background(), &out, tsclient.InventoryNDJSON))
+	dec := json.NewDecoder(&out)
+	var lines []map[string]any
+	for dec.More() {
+		var line map[string]any
+		require.NoError(t, dec.Decode(&line))
+		assert.Len(t, line, len(tsclient.InventoryColumns))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 2)
+	assert.Equal(t, "bar.example.com", lines[1]["name"])
+	assert.Equal(t, true, lines[1]["authorized"])
+	assert.Equal(t, []any{}, lines[1]["tags"])
+
+	assert.Error(t, tsclient.WriteDeviceInventory(&out, "xml", nil))
+}
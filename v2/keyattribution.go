@@ -0,0 +1,139 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// KeyAttributionAuditLog means that a configuration audit log entry creating the device
+	// references the key.
+	KeyAttributionAuditLog KeyAttributionSource = "auditLog"
+	// KeyAttributionMetadata means that the device was created while the key was usable, and has
+	// the tags of the key or, for keys without tags, is owned by the owner of the key. It is a
+	// candidate for investigation rather than proof that the key was used.
+	KeyAttributionMetadata KeyAttributionSource = "metadata"
+)
+
+// KeyAttributionSource describes how a device was attributed to a key by
+// [KeysResource.AttributeDevices].
+type KeyAttributionSource string
+
+// KeyDeviceAttribution is a device attributed to a key by [KeysResource.AttributeDevices].
+type KeyDeviceAttribution struct {
+	NodeID NodeID
+	Name   string
+	// Device is the device, or nil if it has been deleted since it was created.
+	Device *Device
+	Source KeyAttributionSource
+	// Log is the configuration audit log entry that created the device, if it was found.
+	Log *ConfigurationLog
+}
+
+// AttributeDevices returns the devices that were likely enrolled using the key whose identifier
+// matches the one provided, such as to investigate what a leaked key was used for.
+//
+// The API does not record which key enrolled a device, so devices are attributed from the
+// configuration audit logs recorded while the key was usable, using the device creations that
+// reference the key, and from the metadata of the devices that currently exist. See
+// [KeyAttributionSource] for the confidence of each attribution. Devices attributed from audit logs
+// come first, followed by the others, both ordered by creation time.
+func (kr *KeysResource) AttributeDevices(ctx context.Context, id string) ([]KeyDeviceAttribution, error) {
+	key, err := kr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := kr.Devices().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if !key.Revoked.IsZero() && key.Revoked.Before(end) {
+		end = key.Revoked
+	}
+	if !key.Expires.IsZero() && key.Expires.Before(end) {
+		end = key.Expires
+	}
+	logs, err := kr.Logging().ConfigurationLogs(ctx, key.Created, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byNodeID := make(map[NodeID]*Device, len(devices))
+	for i := range devices {
+		byNodeID[devices[i].NodeID] = &devices[i]
+	}
+
+	var attributions []KeyDeviceAttribution
+	attributed := make(map[NodeID]bool)
+	for i := range logs {
+		log := &logs[i]
+		if log.Target.Type != "NODE" || log.Action != "CREATE" || !logReferencesKey(log, key.ID) {
+			continue
+		}
+		nodeID := NodeID(log.Target.ID)
+		if attributed[nodeID] {
+			continue
+		}
+		attributed[nodeID] = true
+		attributions = append(attributions, KeyDeviceAttribution{
+			NodeID: nodeID,
+			Name:   log.Target.Name,
+			Device: byNodeID[nodeID],
+			Source: KeyAttributionAuditLog,
+			Log:    log,
+		})
+	}
+	slices.SortStableFunc(attributions, func(a, b KeyDeviceAttribution) int {
+		return a.Log.EventTime.Compare(b.Log.EventTime)
+	})
+
+	owner := ""
+	keyTags := key.Capabilities.Devices.Create.Tags
+	if len(keyTags) == 0 && key.UserID != "" {
+		user, err := kr.Users().Get(ctx, key.UserID)
+		if err != nil {
+			return nil, err
+		}
+		owner = user.LoginName
+	}
+	var candidates []KeyDeviceAttribution
+	for i := range devices {
+		d := &devices[i]
+		if attributed[d.NodeID] || d.Created.Before(key.Created) || d.Created.After(end) {
+			continue
+		}
+		if len(keyTags) > 0 && !sameSet(d.Tags, keyTags) {
+			continue
+		}
+		if len(keyTags) == 0 && (owner == "" || d.User != owner || len(d.Tags) > 0) {
+			continue
+		}
+		candidates = append(candidates, KeyDeviceAttribution{NodeID: d.NodeID, Name: d.Name, Device: d, Source: KeyAttributionMetadata})
+	}
+	slices.SortStableFunc(candidates, func(a, b KeyDeviceAttribution) int {
+		return a.Device.Created.Compare(b.Device.Created.Time)
+	})
+
+	return append(attributions, candidates...), nil
+}
+
+// logReferencesKey reports whether log was made by the key with the given identifier, or references
+// it in its new value.
+func logReferencesKey(log *ConfigurationLog, keyID string) bool {
+	if log.Actor.ID == keyID {
+		return true
+	}
+	if log.New == nil {
+		return false
+	}
+	b, err := json.Marshal(log.New)
+	return err == nil && strings.Contains(string(b), keyID)
+}
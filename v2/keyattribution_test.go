@@ -0,0 +1,60 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Keys_AttributeDevices(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) tsclient.Time { return tsclient.Time{Time: created.AddDate(0, 0, days)} }
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]any{
+		"/api/v2/tailnet/example.com/keys/kLeaked": tsclient.Key{
+			ID:           "kLeaked",
+			Created:      created,
+			Expires:      created.AddDate(0, 0, 90),
+			Revoked:      created.AddDate(0, 0, 10),
+			Capabilities: tsclient.NewKeyCapabilities("tag:ci").Reusable().Build(),
+		},
+		"/api/v2/tailnet/example.com/devices": map[string][]tsclient.Device{"devices": {
+			{NodeID: "n1", Name: "logged", Tags: []string{"tag:ci"}, Created: at(2)},
+			{NodeID: "n2", Name: "candidate", Tags: []string{"tag:ci"}, Created: at(1)},
+			{NodeID: "n3", Name: "other-tags", Tags: []string{"tag:prod"}, Created: at(1)},
+			{NodeID: "n4", Name: "after-revocation", Tags: []string{"tag:ci"}, Created: at(11)},
+		}},
+		"/api/v2/tailnet/example.com/logging/configuration": map[string][]tsclient.ConfigurationLog{"logs": {
+			{Action: "CREATE", Target: tsclient.ConfigurationLogTarget{ID: "n1", Name: "logged", Type: "NODE"}, New: map[string]any{"authKey": "kLeaked"}, EventTime: at(2).Time},
+			{Action: "CREATE", Target: tsclient.ConfigurationLogTarget{ID: "n9", Name: "deleted", Type: "NODE"}, Actor: tsclient.ConfigurationLogActor{ID: "kLeaked"}, EventTime: at(3).Time},
+			{Action: "CREATE", Target: tsclient.ConfigurationLogTarget{ID: "n3", Name: "other-tags", Type: "NODE"}, EventTime: at(1).Time},
+		}},
+	}
+
+	attributions, err := client.Keys().AttributeDevices(context.Background(), "kLeaked")
+	require.NoError(t, err)
+
+	var names []string
+	var sources []tsclient.KeyAttributionSource
+	for _, a := range attributions {
+		names = append(names, a.Name)
+		sources = append(sources, a.Source)
+	}
+	assert.Equal(t, []string{"logged", "deleted", "candidate"}, names)
+	assert.Equal(t, []tsclient.KeyAttributionSource{tsclient.KeyAttributionAuditLog, tsclient.KeyAttributionAuditLog, tsclient.KeyAttributionMetadata}, sources)
+	assert.Nil(t, attributions[1].Device)
+	assert.Equal(t, tsclient.NodeID("n1"), attributions[0].Device.NodeID)
+	assert.Equal(t, "2024-01-11T00:00:00Z", server.Query.Get("end"))
+}
@@ -0,0 +1,112 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DeviceKeyExpiry describes when the key of a device expires.
+type DeviceKeyExpiry struct {
+	DeviceID string
+	Name     string
+	// Expires is the time at which the device key expires. It is zero if the key never expires.
+	Expires time.Time
+	// KeyExpiryDisabled is true if key expiry has been disabled for the device.
+	KeyExpiryDisabled bool
+	// DaysRemaining is the number of whole days until the key expires, as of the time of the
+	// report. It is negative for expired keys, and zero for keys that never expire.
+	DaysRemaining int
+}
+
+// KeyExpiryReport reports the key expiry of every device in the tailnet, ordered by expiry
+// with the earliest first, followed by devices whose keys never expire.
+func (dr *DevicesResource) KeyExpiryReport(ctx context.Context, opts ...ListOption) ([]DeviceKeyExpiry, error) {
+	devices, err := dr.List(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return keyExpiryReport(devices, time.Now()), nil
+}
+
+// KeysExpiringWithin reports the devices whose keys expire within window from now, including
+// devices whose keys have already expired, ordered by expiry with the earliest first. Devices with
+// key expiry disabled are never included.
+func (dr *DevicesResource) KeysExpiringWithin(ctx context.Context, window time.Duration, opts ...ListOption) ([]DeviceKeyExpiry, error) {
+	devices, err := dr.List(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return expiringWithin(keyExpiryReport(devices, now), now.Add(window)), nil
+}
+
+// expiringWithin returns the entries of report that expire before deadline.
+func expiringWithin(report []DeviceKeyExpiry, deadline time.Time) []DeviceKeyExpiry {
+	return slices.DeleteFunc(report, func(entry DeviceKeyExpiry) bool {
+		return entry.Expires.IsZero() || entry.Expires.After(deadline)
+	})
+}
+
+func keyExpiryReport(devices []Device, now time.Time) []DeviceKeyExpiry {
+	report := make([]DeviceKeyExpiry, 0, len(devices))
+	for _, d := range devices {
+		entry := DeviceKeyExpiry{
+			DeviceID:          d.ID,
+			Name:              d.Name,
+			KeyExpiryDisabled: d.KeyExpiryDisabled,
+		}
+		if !d.KeyExpiryDisabled && !d.Expires.IsZero() {
+			entry.Expires = d.Expires.Time
+			entry.DaysRemaining = int(math.Floor(entry.Expires.Sub(now).Hours() / 24))
+		}
+		report = append(report, entry)
+	}
+
+	slices.SortStableFunc(report, func(a, b DeviceKeyExpiry) int {
+		switch {
+		case a.Expires.IsZero() && b.Expires.IsZero():
+			return 0
+		case a.Expires.IsZero():
+			return 1
+		case b.Expires.IsZero():
+			return -1
+		}
+		return a.Expires.Compare(b.Expires)
+	})
+	return report
+}
+
+// WriteKeyExpiryCSV writes report to w as CSV, with a header row. Expiry times are formatted as
+// RFC 3339 and left empty for keys that never expire.
+func WriteKeyExpiryCSV(w io.Writer, report []DeviceKeyExpiry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"deviceId", "name", "expires", "keyExpiryDisabled", "daysRemaining"}); err != nil {
+		return err
+	}
+	for _, entry := range report {
+		var expires string
+		if !entry.Expires.IsZero() {
+			expires = entry.Expires.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			entry.DeviceID,
+			entry.Name,
+			expires,
+			strconv.FormatBool(entry.KeyExpiryDisabled),
+			strconv.Itoa(entry.DaysRemaining),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Devices_KeyExpiryReport(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	soon := time.Now().Add(36 * time.Hour).Truncate(time.Second).UTC()
+	expired := time.Now().Add(-36 * time.Hour).Truncate(time.Second).UTC()
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", Name: "disabled", KeyExpiryDisabled: true, Expires: tsclient.Time{Time: soon}},
+			{ID: "2", Name: "soon", Expires: tsclient.Time{Time: soon}},
+			{ID: "3", Name: "expired", Expires: tsclient.Time{Time: expired}},
+		},
+	}
+
+	report, err := client.Devices().KeyExpiryReport(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []tsclient.DeviceKeyExpiry{
+		{DeviceID: "3", Name: "expired", Expires: expired, DaysRemaining: -2},
+		{DeviceID: "2", Name: "soon", Expires: soon, DaysRemaining: 1},
+		{DeviceID: "1", Name: "disabled", KeyExpiryDisabled: true},
+	}, report)
+
+	var buf bytes.Buffer
+	require.NoError(t, tsclient.WriteKeyExpiryCSV(&buf, report))
+	assert.Equal(t, "deviceId,name,expires,keyExpiryDisabled,daysRemaining\n"+
+		"3,expired,"+expired.Format(time.RFC3339)+",false,-2\n"+
+		"2,soon,"+soon.Format(time.RFC3339)+",false,1\n"+
+		"1,disabled,,true,0\n", buf.String())
+}
+
+func TestClient_Devices_KeysExpiringWithin(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	soon := time.Now().Add(36 * time.Hour).Truncate(time.Second).UTC()
+	later := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	expired := time.Now().Add(-36 * time.Hour).Truncate(time.Second).UTC()
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", KeyExpiryDisabled: true, Expires: tsclient.Time{Time: soon}},
+			{ID: "2", Expires: tsclient.Time{Time: soon}},
+			{ID: "3", Expires: tsclient.Time{Time: expired}},
+			{ID: "4", Expires: tsclient.Time{Time: later}},
+			{ID: "5"},
+		},
+	}
+
+	expiring, err := client.Devices().KeysExpiringWithin(context.Background(), 7*24*time.Hour)
+	require.NoError(t, err)
+	var ids []string
+	for _, entry := range expiring {
+		ids = append(ids, entry.DeviceID)
+	}
+	assert.Equal(t, []string{"3", "2"}, ids)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// KeyRotationOptions configures [KeysResource.Rotate].
+type KeyRotationOptions struct {
+	// GracePeriod is the time to wait after creating the replacement key before revoking the old
+	// key, giving consumers time to pick up the new secret. Zero revokes the old key immediately.
+	GracePeriod time.Duration
+	// ExpirySeconds is the expiry of the replacement key. Defaults to the lifetime of the old key.
+	ExpirySeconds int64
+	// Description is the description of the replacement key. Defaults to the description of the old
+	// key, which must then be valid as described for [CreateKeyRequest.Validate]; descriptions of
+	// keys created before it was enforced may not be.
+	Description string
+}
+
+// KeyRotation describes the rotation of a key by [KeysResource.Rotate].
+type KeyRotation struct {
+	// Old is the key being replaced.
+	Old Key
+	// New is the replacement key. Its Key field holds the secret of the new key.
+	New Key
+
+	done chan struct{}
+	err  error
+}
+
+// Done returns a channel that is closed once the old key has been revoked, or revoking it failed.
+func (r *KeyRotation) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait waits until the old key has been revoked and returns the error of revoking it, if any. It
+// returns early with the context's error if ctx is done.
+func (r *KeyRotation) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Rotate replaces the key whose identifier matches the one provided with a new key with the same
+// capabilities and description, and revokes the old key once opts.GracePeriod has elapsed. Only
+// auth keys can be rotated; API access tokens and OAuth clients are rejected.
+//
+// If the grace period is zero, the old key is revoked before Rotate returns, and an error revoking
+// it is returned along with the [KeyRotation], as the new key has been created regardless.
+// Otherwise the old key is revoked in the background using ctx; cancel ctx to keep the old key, and
+// use [KeyRotation.Wait] to learn whether it was revoked.
+func (kr *KeysResource) Rotate(ctx context.Context, id string, opts KeyRotationOptions) (*KeyRotation, error) {
+	old, err := kr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if old.KeyType != "" && old.KeyType != KeyTypeAuth {
+		return nil, fmt.Errorf("cannot rotate key %s of type %q, only auth keys can be rotated", id, old.KeyType)
+	}
+
+	expirySeconds := opts.ExpirySeconds
+	if expirySeconds == 0 && !old.Created.IsZero() && old.Expires.After(old.Created) {
+		expirySeconds = int64(old.Expires.Sub(old.Created).Seconds())
+	}
+	description := opts.Description
+	if description == "" {
+		description = old.Description
+	}
+	replacement, err := kr.Create(ctx, CreateKeyRequest{
+		Capabilities:  old.Capabilities,
+		ExpirySeconds: expirySeconds,
+		Description:   description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating replacement for key %s: %w", id, err)
+	}
+
+	rotation := &KeyRotation{Old: *old, New: *replacement, done: make(chan struct{})}
+	revoke := func() {
+		defer close(rotation.done)
+		if err := sleep(ctx, opts.GracePeriod); err != nil {
+			rotation.err = err
+			return
+		}
+		if err := kr.Delete(ctx, id); err != nil {
+			rotation.err = fmt.Errorf("revoking key %s replaced by %s: %w", id, replacement.ID, err)
+		}
+	}
+
+	if opts.GracePeriod <= 0 {
+		revoke()
+		return rotation, rotation.err
+	}
+	go revoke()
+	return rotation, nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+)
+
+// keyServer serves the keys of a tailnet, creating and deleting them as requested.
+type keyServer struct {
+	mu      sync.Mutex
+	keys    map[string]tsclient.Key
+	created []tsclient.CreateKeyRequest
+	lists   int
+}
+
+// update calls fn with the keys of the server, allowing it to change them.
+func (s *keyServer) update(fn func(keys map[string]tsclient.Key)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.keys)
+}
+
+func (s *keyServer) listCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lists
+}
+
+func (s *keyServer) key(id string) (tsclient.Key, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+func (s *keyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/v2/tailnet/example.com/keys"), "/")
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		s.lists++
+		keys := make([]tsclient.Key, 0, len(s.keys))
+		for _, key := range s.keys {
+			keys = append(keys, key)
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]tsclient.Key{"keys": keys})
+	case r.Method == http.MethodGet:
+		key, ok := s.keys[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(key)
+	case r.Method == http.MethodPost:
+		var req tsclient.CreateKeyRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.created = append(s.created, req)
+		now := time.Now().UTC().Truncate(time.Second)
+		key := tsclient.Key{
+			ID:           fmt.Sprintf("new%d", len(s.created)),
+			Description:  req.Description,
+			Created:      now,
+			Expires:      now.Add(time.Duration(req.ExpirySeconds) * time.Second),
+			Capabilities: req.Capabilities,
+		}
+		s.keys[key.ID] = key
+		key.Key = "tskey-auth-" + key.ID
+		_ = json.NewEncoder(w).Encode(key)
+	case r.Method == http.MethodDelete:
+		if _, ok := s.keys[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		delete(s.keys, id)
+	}
+}
+
+func newKeyServer(t *testing.T, keys ...tsclient.Key) (*tsclient.Client, *keyServer) {
+	t.Helper()
+
+	ks := &keyServer{keys: make(map[string]tsclient.Key)}
+	for _, key := range keys {
+		ks.keys[key.ID] = key
+	}
+	return apitest.NewClient(t, ks), ks
+}
+
+func TestClient_RotateKey(t *testing.T) {
+	t.Parallel()
+
+	old := tsclient.Key{
+		ID:          "old",
+		Description: "ci runners",
+		Created:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Expires:     time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	old.Capabilities.Devices.Create.Reusable = true
+	old.Capabilities.Devices.Create.Tags = []string{"tag:ci"}
+	client, server := newKeyServer(t, old)
+
+	rotation, err := client.Keys().Rotate(context.Background(), "old", tsclient.KeyRotationOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, old, rotation.Old)
+	assert.Equal(t, "tskey-auth-new1", rotation.New.Key)
+	assert.Equal(t, []tsclient.CreateKeyRequest{{
+		Capabilities:  old.Capabilities,
+		ExpirySeconds: 30 * 24 * 60 * 60,
+		Description:   "ci runners",
+	}}, server.created)
+	assert.NoError(t, rotation.Wait(context.Background()))
+	_, ok := server.key("old")
+	assert.False(t, ok)
+}
+
+func TestClient_RotateKeyGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	client, server := newKeyServer(t, tsclient.Key{ID: "old"})
+
+	rotation, err := client.Keys().Rotate(context.Background(), "old", tsclient.KeyRotationOptions{
+		GracePeriod:   50 * time.Millisecond,
+		ExpirySeconds: 3600,
+	})
+	require.NoError(t, err)
+	_, ok := server.key("old")
+	assert.True(t, ok, "old key revoked before the grace period elapsed")
+
+	assert.NoError(t, rotation.Wait(context.Background()))
+	_, ok = server.key("old")
+	assert.False(t, ok)
+	_, ok = server.key(rotation.New.ID)
+	assert.True(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rotation, err = client.Keys().Rotate(ctx, rotation.New.ID, tsclient.KeyRotationOptions{GracePeriod: time.Hour})
+	require.NoError(t, err)
+	cancel()
+	<-rotation.Done()
+	assert.ErrorIs(t, rotation.Wait(context.Background()), context.Canceled)
+	_, ok = server.key(rotation.Old.ID)
+	assert.True(t, ok)
+}
+
+func TestClient_RotateKeyRejected(t *testing.T) {
+	t.Parallel()
+
+	client, server := newKeyServer(t,
+		tsclient.Key{ID: "api", KeyType: tsclient.KeyTypeAPI},
+		tsclient.Key{ID: "client", KeyType: tsclient.KeyTypeClient},
+		tsclient.Key{ID: "legacy", KeyType: tsclient.KeyTypeAuth, Description: "ci_runners"},
+	)
+
+	for _, id := range []string{"api", "client", "legacy"} {
+		_, err := client.Keys().Rotate(context.Background(), id, tsclient.KeyRotationOptions{})
+		assert.Error(t, err, id)
+		_, ok := server.key(id)
+		assert.True(t, ok, id)
+	}
+	assert.Empty(t, server.created)
+
+	rotation, err := client.Keys().Rotate(context.Background(), "legacy", tsclient.KeyRotationOptions{Description: "ci runners"})
+	require.NoError(t, err)
+	assert.Equal(t, "ci runners", rotation.New.Description)
+	_, ok := server.key("legacy")
+	assert.False(t, ok)
+}
@@ -5,7 +5,12 @@ package tsclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -16,14 +21,106 @@ type KeysResource struct {
 
 // KeyCapabilities describes the capabilities of an authentication key.
 type KeyCapabilities struct {
-	Devices struct {
-		Create struct {
-			Reusable      bool     `json:"reusable"`
-			Ephemeral     bool     `json:"ephemeral"`
-			Tags          []string `json:"tags"`
-			Preauthorized bool     `json:"preauthorized"`
-		} `json:"create"`
-	} `json:"devices"`
+	Devices KeyDeviceCapabilities `json:"devices"`
+}
+
+// KeyDeviceCapabilities describes the capabilities of an authentication key relating to devices.
+type KeyDeviceCapabilities struct {
+	Create KeyDeviceCreateCapabilities `json:"create"`
+}
+
+// KeyDeviceCreateCapabilities describes the devices an authentication key can add to the tailnet.
+type KeyDeviceCreateCapabilities struct {
+	Reusable      bool     `json:"reusable"`
+	Ephemeral     bool     `json:"ephemeral"`
+	Tags          []string `json:"tags"`
+	Preauthorized bool     `json:"preauthorized"`
+}
+
+// KeyCapabilitiesBuilder builds [KeyCapabilities]. The zero value builds capabilities for a
+// single-use key adding untagged, non-ephemeral devices that require approval.
+type KeyCapabilitiesBuilder struct {
+	create KeyDeviceCreateCapabilities
+}
+
+// NewKeyCapabilities returns a builder for [KeyCapabilities] of a key adding devices with the
+// given tags, such as:
+//
+//	caps := tsclient.NewKeyCapabilities("tag:ci").Reusable().Ephemeral().Build()
+func NewKeyCapabilities(tags ...string) *KeyCapabilitiesBuilder {
+	return &KeyCapabilitiesBuilder{create: KeyDeviceCreateCapabilities{Tags: slices.Clone(tags)}}
+}
+
+// Reusable allows the key to be used to add more than one device.
+func (b *KeyCapabilitiesBuilder) Reusable() *KeyCapabilitiesBuilder {
+	b.create.Reusable = true
+	return b
+}
+
+// Ephemeral makes the devices added with the key ephemeral.
+func (b *KeyCapabilitiesBuilder) Ephemeral() *KeyCapabilitiesBuilder {
+	b.create.Ephemeral = true
+	return b
+}
+
+// Preauthorized approves the devices added with the key without requiring manual approval.
+func (b *KeyCapabilitiesBuilder) Preauthorized() *KeyCapabilitiesBuilder {
+	b.create.Preauthorized = true
+	return b
+}
+
+// Tags adds tags to the devices added with the key.
+func (b *KeyCapabilitiesBuilder) Tags(tags ...string) *KeyCapabilitiesBuilder {
+	b.create.Tags = append(b.create.Tags, tags...)
+	return b
+}
+
+// Build returns the capabilities. Later changes to the builder do not affect them.
+func (b *KeyCapabilitiesBuilder) Build() KeyCapabilities {
+	create := b.create
+	create.Tags = slices.Clone(create.Tags)
+	return KeyCapabilities{Devices: KeyDeviceCapabilities{Create: create}}
+}
+
+// DiffKeyCapabilities returns a human-readable description of each difference between before and
+// after, such as "reusable→false" for a changed flag, or "+tag:ci" and "-tag:old" for added and
+// removed tags. It returns nil if the capabilities are equivalent, ignoring the order of tags.
+func DiffKeyCapabilities(before, after KeyCapabilities) []string {
+	b, a := before.Devices.Create, after.Devices.Create
+	var diff []string
+	for _, flag := range []struct {
+		name          string
+		before, after bool
+	}{
+		{"reusable", b.Reusable, a.Reusable},
+		{"ephemeral", b.Ephemeral, a.Ephemeral},
+		{"preauthorized", b.Preauthorized, a.Preauthorized},
+	} {
+		if flag.before != flag.after {
+			diff = append(diff, fmt.Sprintf("%s→%t", flag.name, flag.after))
+		}
+	}
+
+	beforeTags := sortedSet(b.Tags)
+	afterTags := sortedSet(a.Tags)
+	for _, tag := range afterTags {
+		if !slices.Contains(beforeTags, tag) {
+			diff = append(diff, "+"+tag)
+		}
+	}
+	for _, tag := range beforeTags {
+		if !slices.Contains(afterTags, tag) {
+			diff = append(diff, "-"+tag)
+		}
+	}
+	return diff
+}
+
+// sortedSet returns a sorted copy of values with duplicates removed.
+func sortedSet(values []string) []string {
+	s := slices.Clone(values)
+	slices.Sort(s)
+	return slices.Compact(s)
 }
 
 // CreateKeyRequest describes the definition of an authentication key to create.
@@ -33,7 +130,62 @@ type CreateKeyRequest struct {
 	Description   string          `json:"description"`
 }
 
-// Key describes an authentication key within the tailnet.
+// MaxKeyExpirySeconds is the maximum expiry of an authentication key, 90 days.
+const MaxKeyExpirySeconds = 90 * 24 * 60 * 60
+
+const maxKeyDescriptionLength = 50
+
+var keyDescriptionPattern = regexp.MustCompile(`^[a-zA-Z0-9 -]*$`)
+
+// Validate reports the problems with the request that would cause the API to reject it: an expiry
+// that is negative or longer than [MaxKeyExpirySeconds], a description longer than 50 characters or
+// containing characters other than letters, digits, spaces and hyphens, and tags that are invalid or
+// lack the "tag:" prefix. All combinations of the Reusable, Ephemeral and Preauthorized capabilities
+// are valid.
+func (ckr CreateKeyRequest) Validate() error {
+	var errs []error
+	if ckr.ExpirySeconds < 0 || ckr.ExpirySeconds > MaxKeyExpirySeconds {
+		errs = append(errs, fmt.Errorf("invalid expiry of %d seconds, must be between 0 (the default of 90 days) and %d", ckr.ExpirySeconds, MaxKeyExpirySeconds))
+	}
+	if len(ckr.Description) > maxKeyDescriptionLength {
+		errs = append(errs, fmt.Errorf("description is %d characters long, must be at most %d", len(ckr.Description), maxKeyDescriptionLength))
+	}
+	if !keyDescriptionPattern.MatchString(ckr.Description) {
+		errs = append(errs, fmt.Errorf("invalid description %q, must only contain letters, digits, spaces and hyphens", ckr.Description))
+	}
+	for _, tag := range ckr.Capabilities.Devices.Create.Tags {
+		if !strings.HasPrefix(tag, TagPrefix) {
+			errs = append(errs, fmt.Errorf("invalid tag %q, must start with %q", tag, TagPrefix))
+		} else if err := ValidateSelector(tag); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+const (
+	KeyTypeAuth   KeyType = "auth"
+	KeyTypeAPI    KeyType = "api"
+	KeyTypeClient KeyType = "client"
+)
+
+// KeyType is the type of a [Key]: an auth key for adding devices, an API access token, or an
+// OAuth client.
+type KeyType string
+
+const (
+	KeyStatusActive  KeyStatus = "active"
+	KeyStatusExpired KeyStatus = "expired"
+	KeyStatusRevoked KeyStatus = "revoked"
+	KeyStatusInvalid KeyStatus = "invalid"
+)
+
+// KeyStatus is the status of a [Key], as returned by [Key.Status].
+type KeyStatus string
+
+// Key describes an authentication key within the tailnet. Its Key field holds the secret of the key,
+// which is only returned when the key is created. The secret is redacted when a Key is formatted or
+// logged; use [Key.Secret] to pass it around safely on its own.
 type Key struct {
 	ID           string          `json:"id"`
 	Key          string          `json:"key"`
@@ -44,11 +196,45 @@ type Key struct {
 	Invalid      bool            `json:"invalid"`
 	Capabilities KeyCapabilities `json:"capabilities"`
 	UserID       string          `json:"userId"`
+	KeyType      KeyType         `json:"keyType,omitempty"`
+
+	// Scopes and Tags are only set for OAuth clients, and for keys created by OAuth clients.
+	Scopes []string `json:"scopes,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// Secret returns the secret of the key as a [Secret], which is redacted when formatted or logged.
+func (k Key) Secret() Secret {
+	return Secret(k.Key)
 }
 
-// Create creates a new authentication key. Returns the generated [Key] if successful.
-func (kr *KeysResource) Create(ctx context.Context, ckr CreateKeyRequest) (*Key, error) {
-	req, err := kr.buildRequest(ctx, http.MethodPost, kr.buildTailnetURL("keys"), requestBody(ckr))
+// Status returns the status of the key. A key that has been revoked is [KeyStatusRevoked], even if
+// it has also expired. Otherwise, a key that the API reports as invalid is [KeyStatusInvalid], and
+// a key whose expiry has passed is [KeyStatusExpired].
+func (k Key) Status() KeyStatus {
+	return k.statusAt(time.Now())
+}
+
+func (k Key) statusAt(now time.Time) KeyStatus {
+	switch {
+	case !k.Revoked.IsZero():
+		return KeyStatusRevoked
+	case k.Invalid:
+		return KeyStatusInvalid
+	case !k.Expires.IsZero() && !k.Expires.After(now):
+		return KeyStatusExpired
+	default:
+		return KeyStatusActive
+	}
+}
+
+// Create creates a new authentication key. Returns the generated [Key] if successful. The request is
+// checked with [CreateKeyRequest.Validate] before it is sent.
+func (kr *KeysResource) Create(ctx context.Context, ckr CreateKeyRequest, opts ...WriteOption) (*Key, error) {
+	if err := ckr.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid key request: %w", err)
+	}
+	req, err := kr.buildRequest(ctx, http.MethodPost, kr.buildTailnetURL("keys"), requestBody(ckr), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +244,8 @@ func (kr *KeysResource) Create(ctx context.Context, ckr CreateKeyRequest) (*Key,
 
 // Get returns all information on a [Key] whose identifier matches the one provided. This will not return the
 // authentication key itself, just the metadata.
-func (kr *KeysResource) Get(ctx context.Context, id string) (*Key, error) {
-	req, err := kr.buildRequest(ctx, http.MethodGet, kr.buildTailnetURL("keys", id))
+func (kr *KeysResource) Get(ctx context.Context, id string, opts ...GetOption) (*Key, error) {
+	req, err := kr.buildRequest(ctx, http.MethodGet, kr.buildTailnetURL("keys", id), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -67,16 +253,20 @@ func (kr *KeysResource) Get(ctx context.Context, id string) (*Key, error) {
 	return body[Key](kr, req)
 }
 
-// List returns every [Key] within the tailnet. The only fields set for each [Key] will be its identifier.
+// List returns every [Key] within the tailnet, ordered by ID. The only fields set for each [Key] will be its identifier.
 // The keys returned are relative to the user that owns the API key used to authenticate the client.
 //
-// Specify all to list both user and tailnet level keys.
-func (kr *KeysResource) List(ctx context.Context, all bool) ([]Key, error) {
-	url := kr.buildTailnetURL("keys")
+// Specify all to list every key in the tailnet, including keys owned by other users and keys created
+// by OAuth clients. This is typically required when the client authenticates with OAuth credentials.
+//
+// Keys can be filtered with [WithKeyDescription] and [WithKeyTags], in which case every key returned
+// has its metadata set.
+func (kr *KeysResource) List(ctx context.Context, all bool, opts ...ListOption) ([]Key, error) {
+	reqOpts := []requestOption{listOptions(opts)}
 	if all {
-		url.RawQuery = "all=true"
+		reqOpts = append(reqOpts, requestQuery("all", "true"))
 	}
-	req, err := kr.buildRequest(ctx, http.MethodGet, url)
+	req, err := kr.buildRequest(ctx, http.MethodGet, kr.buildTailnetURL("keys"), reqOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -85,13 +275,117 @@ func (kr *KeysResource) List(ctx context.Context, all bool) ([]Key, error) {
 	if err = kr.do(req, &resp); err != nil {
 		return nil, err
 	}
+	keys := sortByID(resp["keys"], func(k Key) string { return k.ID })
 
-	return resp["keys"], nil
+	filters := keyFilters(req.Context())
+	if len(filters) == 0 {
+		return keys, nil
+	}
+	if keys, err = kr.withMetadata(ctx, keys); err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(keys, func(key Key) bool {
+		return slices.ContainsFunc(filters, func(match func(Key) bool) bool { return !match(key) })
+	}), nil
+}
+
+// KeyFilterOption is returned by [WithKeyDescription] and [WithKeyTags]. It can be used with
+// [KeysResource.List].
+type KeyFilterOption struct {
+	requestOptionFunc
+}
+
+type keyFiltersKey struct{}
+
+// WithKeyDescription returns an option that only lists keys whose description contains substr,
+// ignoring case. Keys are filtered client-side, fetching the metadata of every key if needed.
+func WithKeyDescription(substr string) KeyFilterOption {
+	substr = strings.ToLower(substr)
+	return withKeyFilter(func(key Key) bool {
+		return strings.Contains(strings.ToLower(key.Description), substr)
+	})
+}
+
+// WithKeyTags returns an option that only lists keys adding devices with every one of tags. Keys
+// are filtered client-side, fetching the metadata of every key if needed.
+func WithKeyTags(tags ...string) KeyFilterOption {
+	return withKeyFilter(func(key Key) bool {
+		for _, tag := range tags {
+			if !slices.Contains(key.Capabilities.Devices.Create.Tags, tag) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func withKeyFilter(match func(Key) bool) KeyFilterOption {
+	return KeyFilterOption{func(rp *requestParams) {
+		filters := append(slices.Clip(keyFilters(rp.ctx)), match)
+		rp.ctx = context.WithValue(rp.ctx, keyFiltersKey{}, filters)
+	}}
+}
+
+func keyFilters(ctx context.Context) []func(Key) bool {
+	filters, _ := ctx.Value(keyFiltersKey{}).([]func(Key) bool)
+	return filters
+}
+
+// Expiring returns the auth keys of the user that owns the API key which expire within the given
+// window from now, with their metadata, ordered by expiry with the earliest first. Keys that have
+// been revoked, are invalid or have already expired are not included.
+//
+// Keys for which [KeysResource.List] returns only the identifier are fetched with [KeysResource.Get].
+func (kr *KeysResource) Expiring(ctx context.Context, within time.Duration, opts ...ListOption) ([]Key, error) {
+	keys, err := kr.listWithMetadata(ctx, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var expiring []Key
+	for _, key := range keys {
+		if key.KeyType != "" && key.KeyType != KeyTypeAuth {
+			continue
+		}
+		if key.statusAt(now) != KeyStatusActive || key.Expires.IsZero() || key.Expires.After(now.Add(within)) {
+			continue
+		}
+		expiring = append(expiring, key)
+	}
+
+	slices.SortStableFunc(expiring, func(a, b Key) int { return a.Expires.Compare(b.Expires) })
+	return expiring, nil
+}
+
+// listWithMetadata is like [KeysResource.List], but fetches the metadata of every key for which
+// List returns only the identifier.
+func (kr *KeysResource) listWithMetadata(ctx context.Context, all bool, opts ...ListOption) ([]Key, error) {
+	keys, err := kr.List(ctx, all, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return kr.withMetadata(ctx, keys)
+}
+
+// withMetadata fetches the metadata of every key of keys that only has its identifier set.
+func (kr *KeysResource) withMetadata(ctx context.Context, keys []Key) ([]Key, error) {
+	for i, key := range keys {
+		if !key.Created.IsZero() {
+			continue
+		}
+		k, err := kr.Get(ctx, key.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = *k
+	}
+	return keys, nil
 }
 
 // Delete removes an authentication key from the tailnet.
-func (kr *KeysResource) Delete(ctx context.Context, id string) error {
-	req, err := kr.buildRequest(ctx, http.MethodDelete, kr.buildTailnetURL("keys", id))
+func (kr *KeysResource) Delete(ctx context.Context, id string, opts ...WriteOption) error {
+	req, err := kr.buildRequest(ctx, http.MethodDelete, kr.buildTailnetURL("keys", id), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,7 +26,7 @@ func TestClient_CreateKey(t *testing.T) {
 	capabilities.Devices.Create.Ephemeral = true
 	capabilities.Devices.Create.Reusable = true
 	capabilities.Devices.Create.Preauthorized = true
-	capabilities.Devices.Create.Tags = []string{"test:test"}
+	capabilities.Devices.Create.Tags = []string{"tag:test"}
 
 	expected := &tsclient.Key{
 		ID:           "test",
@@ -62,7 +64,7 @@ func TestClient_CreateKeyWithExpirySeconds(t *testing.T) {
 	capabilities.Devices.Create.Ephemeral = true
 	capabilities.Devices.Create.Reusable = true
 	capabilities.Devices.Create.Preauthorized = true
-	capabilities.Devices.Create.Tags = []string{"test:test"}
+	capabilities.Devices.Create.Tags = []string{"tag:test"}
 
 	expected := &tsclient.Key{
 		ID:           "test",
@@ -101,7 +103,7 @@ func TestClient_CreateKeyWithDescription(t *testing.T) {
 	capabilities.Devices.Create.Ephemeral = true
 	capabilities.Devices.Create.Reusable = true
 	capabilities.Devices.Create.Preauthorized = true
-	capabilities.Devices.Create.Tags = []string{"test:test"}
+	capabilities.Devices.Create.Tags = []string{"tag:test"}
 
 	expected := &tsclient.Key{
 		ID:           "test",
@@ -130,6 +132,35 @@ func TestClient_CreateKeyWithDescription(t *testing.T) {
 	assert.EqualValues(t, "key description", actualReq.Description)
 }
 
+func TestCreateKeyRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	ckr := tsclient.CreateKeyRequest{
+		Capabilities:  tsclient.NewKeyCapabilities("tag:ci", "ci", "tag:bad name").Reusable().Ephemeral().Build(),
+		ExpirySeconds: tsclient.MaxKeyExpirySeconds + 1,
+		Description:   "ci_runners",
+	}
+	assert.EqualError(t, ckr.Validate(), strings.Join([]string{
+		`invalid expiry of 7776001 seconds, must be between 0 (the default of 90 days) and 7776000`,
+		`invalid description "ci_runners", must only contain letters, digits, spaces and hyphens`,
+		`invalid tag "ci", must start with "tag:"`,
+		`invalid selector "tag:bad name": invalid name "bad name" after "tag:"`,
+	}, "\n"))
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	_, err := client.Keys().Create(context.Background(), ckr)
+	assert.ErrorContains(t, err, "invalid key request: invalid expiry")
+	assert.Empty(t, server.Method)
+
+	assert.NoError(t, tsclient.CreateKeyRequest{
+		Capabilities:  tsclient.NewKeyCapabilities("tag:ci").Preauthorized().Build(),
+		ExpirySeconds: 3600,
+		Description:   "CI runners - eu",
+	}.Validate())
+	assert.ErrorContains(t, tsclient.CreateKeyRequest{Description: strings.Repeat("a", 51)}.Validate(), "description is 51 characters long")
+}
+
 func TestClient_GetKey(t *testing.T) {
 	t.Parallel()
 
@@ -166,8 +197,8 @@ func TestClient_Keys(t *testing.T) {
 	server.ResponseCode = http.StatusOK
 
 	expected := []tsclient.Key{
-		{ID: "key-a"},
-		{ID: "key-b"},
+		{ID: "key-a", KeyType: tsclient.KeyTypeAuth},
+		{ID: "key-b", KeyType: tsclient.KeyTypeClient, Scopes: []string{"devices:core", "auth_keys"}, Tags: []string{"tag:ci"}},
 	}
 
 	server.ResponseBody = map[string][]tsclient.Key{
@@ -181,6 +212,67 @@ func TestClient_Keys(t *testing.T) {
 	assert.Equal(t, "/api/v2/tailnet/example.com/keys", server.Path)
 }
 
+func TestClient_KeysAll(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Key{"keys": {{ID: "key-a"}}}
+	server.Strict = true
+	server.ExpectQuery = url.Values{"all": {"true"}}
+
+	actual, err := client.Keys().List(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []tsclient.Key{{ID: "key-a"}}, actual)
+}
+
+func TestClient_ExpiringKeys(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+
+	now := time.Now().UTC().Truncate(time.Second)
+	created := now.Add(-24 * time.Hour)
+	soon := tsclient.Key{ID: "soon", Description: "ci", Created: created, Expires: now.Add(time.Hour), KeyType: tsclient.KeyTypeAuth}
+	later := tsclient.Key{ID: "later", Created: created, Expires: now.Add(30 * time.Minute)}
+	server.ResponseBodies = map[string]any{
+		"/api/v2/tailnet/example.com/keys": map[string][]tsclient.Key{"keys": {
+			soon,
+			{ID: "later"},
+			{ID: "distant", Created: created, Expires: now.Add(30 * 24 * time.Hour)},
+			{ID: "expired", Created: created, Expires: now.Add(-time.Hour)},
+			{ID: "revoked", Created: created, Expires: now.Add(time.Hour), Revoked: now},
+			{ID: "api", Created: created, Expires: now.Add(time.Hour), KeyType: tsclient.KeyTypeAPI},
+		}},
+		"/api/v2/tailnet/example.com/keys/later": later,
+	}
+
+	actual, err := client.Keys().Expiring(context.Background(), 7*24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []tsclient.Key{later, soon}, actual)
+}
+
+func TestClient_KeysFiltered(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ciKey := tsclient.Key{ID: "ci", Description: "CI runners", Created: created, Capabilities: tsclient.NewKeyCapabilities("tag:ci", "tag:eu").Build()}
+	client, _ := newKeyServer(t,
+		ciKey,
+		tsclient.Key{ID: "ci-us", Description: "ci runners us", Created: created, Capabilities: tsclient.NewKeyCapabilities("tag:ci").Build()},
+		tsclient.Key{ID: "prod", Description: "prod servers", Created: created, Capabilities: tsclient.NewKeyCapabilities("tag:ci", "tag:eu").Build()},
+	)
+
+	actual, err := client.Keys().List(context.Background(), false, tsclient.WithKeyDescription("ci Runners"), tsclient.WithKeyTags("tag:eu", "tag:ci"))
+	assert.NoError(t, err)
+	assert.Equal(t, []tsclient.Key{ciKey}, actual)
+
+	actual, err = client.Keys().List(context.Background(), false, tsclient.WithKeyTags("tag:ci"))
+	assert.NoError(t, err)
+	assert.Len(t, actual, 3)
+}
+
 func TestClient_DeleteKey(t *testing.T) {
 	t.Parallel()
 
@@ -193,3 +285,62 @@ func TestClient_DeleteKey(t *testing.T) {
 	assert.Equal(t, http.MethodDelete, server.Method)
 	assert.Equal(t, "/api/v2/tailnet/example.com/keys/"+keyID, server.Path)
 }
+
+func TestDiffKeyCapabilities(t *testing.T) {
+	t.Parallel()
+
+	var old, updated tsclient.KeyCapabilities
+	old.Devices.Create.Reusable = true
+	old.Devices.Create.Tags = []string{"tag:old", "tag:shared"}
+	updated.Devices.Create.Preauthorized = true
+	updated.Devices.Create.Tags = []string{"tag:shared", "tag:ci"}
+
+	assert.Equal(t, []string{"reusable→false", "preauthorized→true", "+tag:ci", "-tag:old"}, tsclient.DiffKeyCapabilities(old, updated))
+	assert.Nil(t, tsclient.DiffKeyCapabilities(old, old))
+
+	reordered := old
+	reordered.Devices.Create.Tags = []string{"tag:shared", "tag:old"}
+	assert.Nil(t, tsclient.DiffKeyCapabilities(old, reordered))
+}
+
+func TestKeyCapabilitiesBuilder(t *testing.T) {
+	t.Parallel()
+
+	builder := tsclient.NewKeyCapabilities("tag:ci").Reusable().Preauthorized()
+	caps := builder.Build()
+	assert.Equal(t, tsclient.KeyCapabilities{Devices: tsclient.KeyDeviceCapabilities{
+		Create: tsclient.KeyDeviceCreateCapabilities{Reusable: true, Preauthorized: true, Tags: []string{"tag:ci"}},
+	}}, caps)
+
+	builder.Ephemeral().Tags("tag:runner")
+	assert.Equal(t, []string{"tag:ci"}, caps.Devices.Create.Tags)
+	assert.Equal(t, tsclient.KeyDeviceCreateCapabilities{
+		Reusable:      true,
+		Ephemeral:     true,
+		Preauthorized: true,
+		Tags:          []string{"tag:ci", "tag:runner"},
+	}, builder.Build().Devices.Create)
+
+	marshalled, err := json.Marshal(caps)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"devices": {"create": {"reusable": true, "ephemeral": false, "preauthorized": true, "tags": ["tag:ci"]}}}`, string(marshalled))
+}
+
+func TestKey_Status(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	for _, tc := range []struct {
+		key      tsclient.Key
+		expected tsclient.KeyStatus
+	}{
+		{tsclient.Key{Expires: future}, tsclient.KeyStatusActive},
+		{tsclient.Key{}, tsclient.KeyStatusActive},
+		{tsclient.Key{Expires: past}, tsclient.KeyStatusExpired},
+		{tsclient.Key{Expires: past, Invalid: true}, tsclient.KeyStatusInvalid},
+		{tsclient.Key{Expires: past, Invalid: true, Revoked: past}, tsclient.KeyStatusRevoked},
+	} {
+		assert.Equal(t, tc.expected, tc.key.Status(), "%+v", tc.key)
+	}
+}
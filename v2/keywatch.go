@@ -0,0 +1,130 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	KeyEventCreated KeyEventType = "created"
+	KeyEventRevoked KeyEventType = "revoked"
+	KeyEventInvalid KeyEventType = "invalid"
+	KeyEventExpired KeyEventType = "expired"
+)
+
+// KeyEventType identifies the kind of change described by a [KeyEvent].
+type KeyEventType string
+
+// KeyEvent describes a change to a key observed by [KeysResource.Watch].
+type KeyEvent struct {
+	Type KeyEventType
+	// Key is the key after the change, or the last observed state of keys that were deleted.
+	Key Key
+}
+
+// KeyWatchOptions configures [KeysResource.Watch].
+type KeyWatchOptions struct {
+	// Interval is the time between two successive lists of keys. Defaults to 1 minute.
+	Interval time.Duration
+	// All watches every key of the tailnet instead of the keys of the user that owns the API key,
+	// as with [KeysResource.List].
+	All bool
+	// OnError, if set, is called with the error of every list of keys that failed. Watching
+	// continues after errors.
+	OnError func(error)
+}
+
+// Watch lists keys every interval and emits an event on the returned channel for every key that
+// was created, revoked or deleted, became invalid or expired between two successive lists. The
+// first list only establishes the initial state, without emitting events. Events of a single list
+// are ordered by key ID, followed by keys that were deleted.
+//
+// The metadata of keys for which [KeysResource.List] returns only the identifier is fetched with
+// [KeysResource.Get], so every list may take one request per key.
+//
+// The channel is closed once ctx is done. Callers must receive events promptly, as listing is
+// paused while events are waiting to be received.
+func (kr *KeysResource) Watch(ctx context.Context, opts KeyWatchOptions) <-chan KeyEvent {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	events := make(chan KeyEvent)
+	go func() {
+		defer close(events)
+
+		var previous []Key
+		var previousTime time.Time
+		initialized := false
+		for {
+			now := time.Now()
+			keys, err := kr.listWithMetadata(ctx, opts.All)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return
+			case err != nil:
+				if opts.OnError != nil {
+					opts.OnError(err)
+				}
+			case !initialized:
+				previous, previousTime, initialized = keys, now, true
+			default:
+				for _, event := range keyEvents(previous, keys, previousTime, now) {
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				previous, previousTime = keys, now
+			}
+
+			if err := sleep(ctx, interval); err != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// keyEvents returns the events describing the changes from previous, listed at previousTime, to
+// current, listed at now. Both previous and current are ordered by key ID.
+func keyEvents(previous, current []Key, previousTime, now time.Time) []KeyEvent {
+	byID := make(map[string]*Key, len(previous))
+	for i := range previous {
+		byID[previous[i].ID] = &previous[i]
+	}
+
+	var events []KeyEvent
+	seen := make(map[string]bool, len(current))
+	for _, key := range current {
+		seen[key.ID] = true
+		prev, ok := byID[key.ID]
+		if !ok {
+			events = append(events, KeyEvent{Type: KeyEventCreated, Key: key})
+			continue
+		}
+		status := key.statusAt(now)
+		if status == prev.statusAt(previousTime) {
+			continue
+		}
+		switch status {
+		case KeyStatusRevoked:
+			events = append(events, KeyEvent{Type: KeyEventRevoked, Key: key})
+		case KeyStatusInvalid:
+			events = append(events, KeyEvent{Type: KeyEventInvalid, Key: key})
+		case KeyStatusExpired:
+			events = append(events, KeyEvent{Type: KeyEventExpired, Key: key})
+		}
+	}
+	for _, key := range previous {
+		if !seen[key.ID] && key.statusAt(previousTime) != KeyStatusRevoked {
+			events = append(events, KeyEvent{Type: KeyEventRevoked, Key: key})
+		}
+	}
+	return events
+}
@@ -0,0 +1,62 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Keys_Watch(t *testing.T) {
+	t.Parallel()
+
+	created := time.Now().Add(-time.Hour)
+	expires := time.Now().Add(24 * time.Hour)
+	client, server := newKeyServer(t,
+		tsclient.Key{ID: "expiring", Created: created, Expires: time.Now().Add(100 * time.Millisecond)},
+		tsclient.Key{ID: "deleted", Created: created, Expires: expires},
+		tsclient.Key{ID: "invalidated", Created: created, Expires: expires},
+		tsclient.Key{ID: "unchanged", Created: created, Expires: expires},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.Keys().Watch(ctx, tsclient.KeyWatchOptions{Interval: 5 * time.Millisecond})
+
+	require.Eventually(t, func() bool { return server.listCount() > 0 }, time.Second, time.Millisecond)
+	server.update(func(keys map[string]tsclient.Key) {
+		delete(keys, "deleted")
+		invalidated := keys["invalidated"]
+		invalidated.Invalid = true
+		keys["invalidated"] = invalidated
+		keys["new"] = tsclient.Key{ID: "new", Created: time.Now(), Expires: expires}
+	})
+
+	got := make(map[string]tsclient.KeyEventType)
+	timeout := time.After(5 * time.Second)
+	for len(got) < 4 {
+		select {
+		case event := <-events:
+			got[event.Key.ID] = event.Type
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %v", got)
+		}
+	}
+	assert.Equal(t, map[string]tsclient.KeyEventType{
+		"expiring":    tsclient.KeyEventExpired,
+		"deleted":     tsclient.KeyEventRevoked,
+		"invalidated": tsclient.KeyEventInvalid,
+		"new":         tsclient.KeyEventCreated,
+	}, got)
+
+	cancel()
+	for range events {
+	}
+}
@@ -6,6 +6,7 @@ package tsclient
 import (
 	"context"
 	"net/http"
+	"time"
 )
 
 // LoggingResource provides access to https://tailscale.com/api#tag/logging.
@@ -74,8 +75,8 @@ type LogType string
 type S3AuthenticationType string
 
 // LogstreamConfiguration retrieves the tailnet's [LogstreamConfiguration] for the given [LogType].
-func (lr *LoggingResource) LogstreamConfiguration(ctx context.Context, logType LogType) (*LogstreamConfiguration, error) {
-	req, err := lr.buildRequest(ctx, http.MethodGet, lr.buildTailnetURL("logging", logType, "stream"))
+func (lr *LoggingResource) LogstreamConfiguration(ctx context.Context, logType LogType, opts ...GetOption) (*LogstreamConfiguration, error) {
+	req, err := lr.buildRequest(ctx, http.MethodGet, lr.buildTailnetURL("logging", logType, "stream"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +85,8 @@ func (lr *LoggingResource) LogstreamConfiguration(ctx context.Context, logType L
 }
 
 // SetLogstreamConfiguration sets the tailnet's [LogstreamConfiguration] for the given [LogType].
-func (lr *LoggingResource) SetLogstreamConfiguration(ctx context.Context, logType LogType, request SetLogstreamConfigurationRequest) error {
-	req, err := lr.buildRequest(ctx, http.MethodPut, lr.buildTailnetURL("logging", logType, "stream"), requestBody(request))
+func (lr *LoggingResource) SetLogstreamConfiguration(ctx context.Context, logType LogType, request SetLogstreamConfigurationRequest, opts ...WriteOption) error {
+	req, err := lr.buildRequest(ctx, http.MethodPut, lr.buildTailnetURL("logging", logType, "stream"), requestBody(request), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -94,8 +95,8 @@ func (lr *LoggingResource) SetLogstreamConfiguration(ctx context.Context, logTyp
 }
 
 // DeleteLogstreamConfiguration deletes the tailnet's [LogstreamConfiguration] for the given [LogType].
-func (lr *LoggingResource) DeleteLogstreamConfiguration(ctx context.Context, logType LogType) error {
-	req, err := lr.buildRequest(ctx, http.MethodDelete, lr.buildTailnetURL("logging", logType, "stream"))
+func (lr *LoggingResource) DeleteLogstreamConfiguration(ctx context.Context, logType LogType, opts ...WriteOption) error {
+	req, err := lr.buildRequest(ctx, http.MethodDelete, lr.buildTailnetURL("logging", logType, "stream"), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -103,6 +104,59 @@ func (lr *LoggingResource) DeleteLogstreamConfiguration(ctx context.Context, log
 	return lr.do(req, nil)
 }
 
+// ConfigurationLog is an entry of the configuration audit log of a tailnet, describing a change
+// made to the configuration of the tailnet.
+type ConfigurationLog struct {
+	EventGroupID string                 `json:"eventGroupID"`
+	Origin       string                 `json:"origin"`
+	Actor        ConfigurationLogActor  `json:"actor"`
+	Type         string                 `json:"type"`
+	Target       ConfigurationLogTarget `json:"target"`
+	// Action is the kind of change, such as "CREATE", "UPDATE" or "DELETE".
+	Action string `json:"action"`
+	// Old and New are the values of the changed property before and after the change, if any.
+	Old       any       `json:"old,omitempty"`
+	New       any       `json:"new,omitempty"`
+	EventTime time.Time `json:"eventTime"`
+}
+
+// ConfigurationLogActor is the user or client that made a change recorded by a [ConfigurationLog].
+type ConfigurationLogActor struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	LoginName   string `json:"loginName,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// ConfigurationLogTarget is the entity changed by a [ConfigurationLog]. For devices, Type is
+// "NODE" and ID is the [NodeID] of the device.
+type ConfigurationLogTarget struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+	// Property is the changed property of the entity, such as "TAGS", if the change was limited to it.
+	Property string `json:"property,omitempty"`
+}
+
+// ConfigurationLogs retrieves the configuration audit logs of the tailnet recorded between start and end.
+func (lr *LoggingResource) ConfigurationLogs(ctx context.Context, start, end time.Time, opts ...ListOption) ([]ConfigurationLog, error) {
+	req, err := lr.buildRequest(ctx, http.MethodGet, lr.buildTailnetURL("logging", LogTypeConfig),
+		requestQuery("start", start.UTC().Format(time.RFC3339)),
+		requestQuery("end", end.UTC().Format(time.RFC3339)),
+		listOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Logs []ConfigurationLog `json:"logs"`
+	}
+	if err := lr.do(req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Logs, nil
+}
+
 // AWSExternalID represents an AWS External ID that Tailscale can use to stream logs from a
 // particular Tailscale AWS account to a LogstreamS3Endpoint that uses S3RoleARNAuthentication.
 type AWSExternalID struct {
@@ -113,10 +167,10 @@ type AWSExternalID struct {
 // CreateOrGetAwsExternalId gets an AWS External ID that Tailscale can use to stream logs to
 // a LogstreamS3Endpoint using S3RoleARNAuthentication, creating a new one for this tailnet
 // when necessary.
-func (lr *LoggingResource) CreateOrGetAwsExternalId(ctx context.Context, reusable bool) (*AWSExternalID, error) {
+func (lr *LoggingResource) CreateOrGetAwsExternalId(ctx context.Context, reusable bool, opts ...WriteOption) (*AWSExternalID, error) {
 	req, err := lr.buildRequest(ctx, http.MethodPost, lr.buildTailnetURL("aws-external-id"), requestBody(map[string]bool{
 		"reusable": reusable,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -125,10 +179,10 @@ func (lr *LoggingResource) CreateOrGetAwsExternalId(ctx context.Context, reusabl
 
 // ValidateAWSTrustPolicy validates that Tailscale can assume your AWS IAM role with (and only
 // with) the given AWS External ID.
-func (lr *LoggingResource) ValidateAWSTrustPolicy(ctx context.Context, awsExternalID string, roleARN string) error {
+func (lr *LoggingResource) ValidateAWSTrustPolicy(ctx context.Context, awsExternalID string, roleARN string, opts ...WriteOption) error {
 	req, err := lr.buildRequest(ctx, http.MethodPost, lr.buildTailnetURL("aws-external-id", awsExternalID, "validate-aws-trust-policy"), requestBody(map[string]string{
 		"roleArn": roleARN,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	tsclient "github.com/tailscale/tailscale-client-go/v2"
@@ -115,3 +116,31 @@ func TestClient_ValidateAWSTrustPolicy(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, gotRequest, map[string]string{"roleArn": roleARN})
 }
+
+func TestClient_ConfigurationLogs(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	expected := []tsclient.ConfigurationLog{
+		{
+			EventGroupID: "1",
+			Actor:        tsclient.ConfigurationLogActor{ID: "u1", Type: "USER", LoginName: "alice@example.com"},
+			Type:         "CONFIG",
+			Target:       tsclient.ConfigurationLogTarget{ID: "n1CNTRL", Name: "foo.example.com", Type: "NODE", Property: "TAGS"},
+			Action:       "UPDATE",
+			New:          []any{"tag:server"},
+			EventTime:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+	server.ResponseBody = map[string]any{"logs": expected, "version": "1.1"}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs, err := client.Logging().ConfigurationLogs(context.Background(), start, start.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, server.Method)
+	assert.Equal(t, "/api/v2/tailnet/example.com/logging/configuration", server.Path)
+	assert.Equal(t, "2024-01-01T00:00:00Z", server.Query.Get("start"))
+	assert.Equal(t, "2024-01-02T00:00:00Z", server.Query.Get("end"))
+	assert.Equal(t, expected, logs)
+}
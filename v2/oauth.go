@@ -7,6 +7,7 @@ import (
 	"context"
 	"net/http"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -20,9 +21,14 @@ type OAuthConfig struct {
 	Scopes []string
 	// BaseURL is an optional base URL for the API server to which we'll connect. Defaults to https://api.tailscale.com.
 	BaseURL string
+	// Transport is an optional [http.RoundTripper] used for requests, such as one returned by
+	// [TransportConfig.Transport]. Defaults to [http.DefaultTransport].
+	Transport http.RoundTripper
 }
 
-// HTTPClient constructs an HTTP client that authenticates using OAuth.
+// HTTPClient constructs an HTTP client that authenticates using OAuth. Requests made with it time
+// out after 1 minute, which also bounds longer [Client].Timeouts; set its Timeout to zero to only
+// be limited by the [Client].Timeouts.
 func (ocfg OAuthConfig) HTTPClient() *http.Client {
 	baseURL := ocfg.BaseURL
 	if baseURL == "" {
@@ -36,7 +42,11 @@ func (ocfg OAuthConfig) HTTPClient() *http.Client {
 	}
 
 	// Use context.Background() here, since this is used to refresh the token in the future.
-	client := oauthConfig.Client(context.Background())
+	ctx := context.Background()
+	if ocfg.Transport != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: ocfg.Transport})
+	}
+	client := oauthConfig.Client(ctx)
 	client.Timeout = defaultHttpClientTimeout
 	return client
 }
@@ -0,0 +1,62 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// OAuthClientsResource provides access to the OAuth clients of a tailnet. OAuth clients are
+// managed through https://tailscale.com/api#tag/keys as keys of type [KeyTypeClient].
+type OAuthClientsResource struct {
+	*Client
+}
+
+// CreateOAuthClientRequest describes the definition of an OAuth client to create.
+type CreateOAuthClientRequest struct {
+	Description string `json:"description"`
+	// Scopes are the scopes granted to the OAuth client, such as "devices:core" or "auth_keys".
+	Scopes []string `json:"scopes"`
+	// Tags are the tags the OAuth client may assign to devices and auth keys. They are required
+	// by scopes that create devices or auth keys.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Create creates a new OAuth client. The returned [Key] holds the client ID in its ID field and
+// the client secret in its Key field. The secret is only returned on creation.
+func (ocr *OAuthClientsResource) Create(ctx context.Context, cr CreateOAuthClientRequest, opts ...WriteOption) (*Key, error) {
+	reqBody := struct {
+		KeyType KeyType `json:"keyType"`
+		CreateOAuthClientRequest
+	}{KeyTypeClient, cr}
+	req, err := ocr.buildRequest(ctx, http.MethodPost, ocr.buildTailnetURL("keys"), requestBody(reqBody), writeOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return body[Key](ocr, req)
+}
+
+// List returns every OAuth client within the tailnet, ordered by ID.
+func (ocr *OAuthClientsResource) List(ctx context.Context, opts ...ListOption) ([]Key, error) {
+	keys, err := ocr.Keys().List(ctx, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []Key
+	for _, key := range keys {
+		if key.KeyType == KeyTypeClient {
+			clients = append(clients, key)
+		}
+	}
+	return clients, nil
+}
+
+// Delete revokes an OAuth client. Access tokens issued to the client stop working, but auth keys
+// and devices it created are not affected.
+func (ocr *OAuthClientsResource) Delete(ctx context.Context, id string, opts ...WriteOption) error {
+	return ocr.Keys().Delete(ctx, id, opts...)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// ListOption configures a request that lists resources, such as [DevicesResource.List].
+//
+// Every resource method accepts options of the type matching its kind of request, so that
+// options can be introduced without changing method signatures. Options that apply to
+// several kinds of requests implement several option types.
+type ListOption interface {
+	applyListOption(*requestParams)
+}
+
+// GetOption configures a request that retrieves a resource, such as [DevicesResource.Get].
+// See [ListOption] for details.
+type GetOption interface {
+	applyGetOption(*requestParams)
+}
+
+// WriteOption configures a request that creates, modifies or deletes a resource, such as
+// [DevicesResource.SetTags]. See [ListOption] for details.
+type WriteOption interface {
+	applyWriteOption(*requestParams)
+}
+
+func listOptions(opts []ListOption) requestOption {
+	return func(rp *requestParams) {
+		for _, opt := range opts {
+			opt.applyListOption(rp)
+		}
+	}
+}
+
+func getOptions(opts []GetOption) requestOption {
+	return func(rp *requestParams) {
+		for _, opt := range opts {
+			opt.applyGetOption(rp)
+		}
+	}
+}
+
+func writeOptions(opts []WriteOption) requestOption {
+	return func(rp *requestParams) {
+		for _, opt := range opts {
+			opt.applyWriteOption(rp)
+		}
+	}
+}
+
+// requestOptionFunc adapts a requestOption so that it can be used as any of
+// [ListOption], [GetOption] and [WriteOption].
+type requestOptionFunc requestOption
+
+func (o requestOptionFunc) applyListOption(rp *requestParams)  { o(rp) }
+func (o requestOptionFunc) applyGetOption(rp *requestParams)   { o(rp) }
+func (o requestOptionFunc) applyWriteOption(rp *requestParams) { o(rp) }
+
+// RawResponseOption is returned by [WithRawResponse]. It can be used with any resource method.
+type RawResponseOption struct {
+	requestOptionFunc
+}
+
+type rawResponseKey struct{}
+
+// WithRawResponse returns an option that stores the raw body of a successful response into dst,
+// in addition to decoding it as usual. This gives access to fields that are not modelled by the
+// types of this package, or to the exact response for archiving, without issuing a second request.
+func WithRawResponse(dst *json.RawMessage) RawResponseOption {
+	return RawResponseOption{func(rp *requestParams) {
+		rp.ctx = context.WithValue(rp.ctx, rawResponseKey{}, dst)
+	}}
+}
+
+func recordRawResponse(ctx context.Context, body []byte) {
+	if dst, ok := ctx.Value(rawResponseKey{}).(*json.RawMessage); ok {
+		*dst = bytes.Clone(body)
+	}
+}
+
+// StrictJSONOption is returned by [WithStrictJSON]. It can be used with any resource method.
+type StrictJSONOption struct {
+	requestOptionFunc
+}
+
+type strictJSONKey struct{}
+
+// WithStrictJSON returns an option that disables the conversion of HuJSON response bodies to JSON.
+// Responses that are not valid JSON then fail with a [ResponseContentError] rather than being
+// standardized, which helps diagnosing proxies or endpoints that return unexpected content.
+func WithStrictJSON() StrictJSONOption {
+	return StrictJSONOption{func(rp *requestParams) {
+		rp.ctx = context.WithValue(rp.ctx, strictJSONKey{}, true)
+	}}
+}
+
+func strictJSON(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictJSONKey{}).(bool)
+	return strict
+}
+
+// ForceOption is returned by [WithForce].
+type ForceOption struct {
+	requestOptionFunc
+}
+
+type forceKey struct{}
+
+// WithForce returns an option that allows [PolicyFileResource.Set] to set the policy file without
+// an ETag, unconditionally overwriting it, when the Client has RequirePolicyETag set. It also allows
+// [PolicySnapshot.Restore] to restore a snapshot whose AppliedETag is unknown.
+func WithForce() ForceOption {
+	return ForceOption{func(rp *requestParams) {
+		rp.ctx = context.WithValue(rp.ctx, forceKey{}, true)
+	}}
+}
+
+func forced(ctx context.Context) bool {
+	force, _ := ctx.Value(forceKey{}).(bool)
+	return force
+}
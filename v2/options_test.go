@@ -0,0 +1,58 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestWithRawResponse(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = []byte(`{"id":"test","futureField":"value"}`)
+
+	var raw json.RawMessage
+	device, err := client.Devices().Get(context.Background(), "test", tsclient.WithRawResponse(&raw))
+	assert.NoError(t, err)
+	assert.Equal(t, "test", device.ID)
+	assert.JSONEq(t, `{"id":"test","futureField":"value"}`, string(raw))
+
+	server.ResponseBody = []byte(`{"devices":[{"id":"test"}]}`)
+	devices, err := client.Devices().List(context.Background(), tsclient.WithRawResponse(&raw))
+	assert.NoError(t, err)
+	assert.Len(t, devices, 1)
+	assert.JSONEq(t, `{"devices":[{"id":"test"}]}`, string(raw))
+}
+
+func TestWithStrictJSON(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseHeader.Set("Content-Type", "application/hujson")
+	server.ResponseBody = []byte(`{"id":"test",}`)
+
+	device, err := client.Devices().Get(context.Background(), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, "test", device.ID)
+
+	_, err = client.Devices().Get(context.Background(), "test", tsclient.WithStrictJSON())
+	var contentErr tsclient.ResponseContentError
+	assert.ErrorAs(t, err, &contentErr)
+	assert.Equal(t, "application/hujson", contentErr.ContentType)
+
+	server.ResponseHeader.Set("Content-Type", "text/html")
+	server.ResponseBody = []byte(`<html></html>`)
+	_, err = client.Devices().Get(context.Background(), "test")
+	assert.ErrorAs(t, err, &contentErr)
+	assert.Equal(t, "text/html", contentErr.ContentType)
+}
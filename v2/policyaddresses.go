@@ -0,0 +1,96 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// PolicyAddressError describes an IP address or CIDR prefix in a policy file that cannot be
+// parsed. It is returned by [ACL.ValidateAddresses], and when marshalling an [ACL], so that
+// invalid addresses are reported before the policy file is sent to the API.
+type PolicyAddressError struct {
+	// Path locates the address within the policy file, such as `hosts["db"]` or "acls[0].dst[1]".
+	Path    string
+	Address string
+	Err     error
+}
+
+func (e PolicyAddressError) Error() string {
+	return fmt.Sprintf("%s: invalid address %q: %v", e.Path, e.Address, e.Err)
+}
+
+func (e PolicyAddressError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateAddresses checks that the values of Hosts, the routes of AutoApprovers, and the
+// sources and destinations of rules and tests that look like IP addresses, CIDR prefixes or IP
+// ranges can be parsed. It returns a [PolicyAddressError] for every invalid address, joined
+// using [errors.Join].
+func (acl *ACL) ValidateAddresses() error {
+	var errs []error
+	check := func(path, address string, parse func(string) error) {
+		if err := parse(address); err != nil {
+			errs = append(errs, PolicyAddressError{Path: path, Address: address, Err: err})
+		}
+	}
+
+	for _, name := range sortedKeys(acl.Hosts) {
+		check(fmt.Sprintf("hosts[%q]", name), acl.Hosts[name], parseAddress)
+	}
+	if acl.AutoApprovers != nil {
+		for _, route := range sortedKeys(acl.AutoApprovers.Routes) {
+			check(fmt.Sprintf("autoApprovers.routes[%q]", route), route, func(s string) error {
+				_, err := netip.ParsePrefix(s)
+				return err
+			})
+		}
+	}
+	for _, name := range sortedKeys(acl.IPSets) {
+		for i, address := range acl.IPSets[name] {
+			if looksLikeAddress(address) {
+				check(fmt.Sprintf("ipsets[%q][%d]", name, i), address, parseAddress)
+			}
+		}
+	}
+	for _, ref := range acl.references() {
+		if looksLikeAddress(ref.selector) {
+			check(ref.path, ref.selector, parseAddress)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseAddress parses s as an IP address, CIDR prefix or range of IP addresses such as
+// "100.64.0.1-100.64.0.10".
+func parseAddress(s string) error {
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		if _, err := netip.ParseAddr(from); err != nil {
+			return err
+		}
+		_, err := netip.ParseAddr(to)
+		return err
+	}
+	if strings.Contains(s, "/") {
+		_, err := netip.ParsePrefix(s)
+		return err
+	}
+	_, err := netip.ParseAddr(s)
+	return err
+}
+
+// looksLikeAddress reports whether s appears to be intended as an IP address, CIDR prefix or IP
+// range rather than another kind of selector, such as a host alias or tag.
+func looksLikeAddress(s string) bool {
+	if strings.Contains(s, "/") {
+		return true
+	}
+	ipv4 := strings.Contains(s, ".") && strings.Trim(s, "0123456789.-") == ""
+	ipv6 := strings.Count(s, ":") >= 2 && strings.Trim(s, "0123456789abcdefABCDEF:.-") == ""
+	return ipv4 || ipv6
+}
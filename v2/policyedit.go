@@ -0,0 +1,195 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// PolicyEditor edits a HuJSON policy file in place, preserving its comments and formatting, unlike
+// unmarshalling it into an [ACL] and marshalling it back. Create one using [NewPolicyEditor], or
+// use [PolicyFileResource.Edit] to edit the policy file of the tailnet.
+type PolicyEditor struct {
+	value hujson.Value
+}
+
+// NewPolicyEditor returns a [PolicyEditor] for the given HuJSON policy file.
+func NewPolicyEditor(huJSON string) (*PolicyEditor, error) {
+	value, err := hujson.Parse([]byte(huJSON))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.Value.(*hujson.Object); !ok {
+		return nil, fmt.Errorf("policy file must be an object")
+	}
+	return &PolicyEditor{value: value}, nil
+}
+
+// HuJSON returns the edited policy file.
+func (e *PolicyEditor) HuJSON() string {
+	return e.value.String()
+}
+
+// Format formats the whole policy file in the standard HuJSON style, keeping comments. Values
+// added by the editor are compact, so formatting is recommended if the file is meant to be read.
+func (e *PolicyEditor) Format() {
+	e.value.Format()
+}
+
+// Patch applies a JSON Patch (RFC 6902), such as [{"op":"remove","path":"/acls/0"}], to the policy
+// file. Paths must use the keys as spelled in the policy file.
+func (e *PolicyEditor) Patch(patch []byte) error {
+	return e.value.Patch(patch)
+}
+
+// AppendACL appends entry to the acls section, which is created if needed.
+func (e *PolicyEditor) AppendACL(entry ACLEntry) error {
+	return e.appendTo("acls", entry)
+}
+
+// AppendGrant appends grant to the grants section, which is created if needed.
+func (e *PolicyEditor) AppendGrant(grant ACLGrant) error {
+	return e.appendTo("grants", grant)
+}
+
+// AddGroupMember adds member to group, which is created if needed. Adding an existing member does
+// nothing.
+func (e *PolicyEditor) AddGroupMember(group, member string) error {
+	groups, err := e.section("groups", map[string][]string{})
+	if err != nil {
+		return err
+	}
+	members, ok := e.lookup(groups, group)
+	if !ok {
+		return e.patch("add", groups+"/"+pointerEscape(group), []string{member})
+	}
+	if slices.Contains(e.strings(members), member) {
+		return nil
+	}
+	return e.patch("add", members+"/-", member)
+}
+
+// RemoveGroupMember removes member from group. Removing a member that is not in group does nothing.
+func (e *PolicyEditor) RemoveGroupMember(group, member string) error {
+	groups, ok := e.lookup("", "groups")
+	if !ok {
+		return nil
+	}
+	members, ok := e.lookup(groups, group)
+	if !ok {
+		return nil
+	}
+	if i := slices.Index(e.strings(members), member); i >= 0 {
+		return e.patch("remove", fmt.Sprintf("%s/%d", members, i), nil)
+	}
+	return nil
+}
+
+// SetTagOwners sets the owners of tag, replacing any existing owners.
+func (e *PolicyEditor) SetTagOwners(tag string, owners []string) error {
+	tagOwners, err := e.section("tagOwners", map[string][]string{})
+	if err != nil {
+		return err
+	}
+	if existing, ok := e.lookup(tagOwners, tag); ok {
+		return e.patch("replace", existing, owners)
+	}
+	return e.patch("add", tagOwners+"/"+pointerEscape(tag), owners)
+}
+
+// appendTo appends v to the top-level array named name, creating it if needed.
+func (e *PolicyEditor) appendTo(name string, v any) error {
+	section, err := e.section(name, []any{})
+	if err != nil {
+		return err
+	}
+	return e.patch("add", section+"/-", v)
+}
+
+// section returns the JSON pointer to the top-level section named name, adding it with the given
+// empty value if it does not exist. Sections are matched case-insensitively, like the API does.
+func (e *PolicyEditor) section(name string, empty any) (string, error) {
+	if ptr, ok := e.lookup("", name); ok {
+		return ptr, nil
+	}
+	ptr := "/" + pointerEscape(name)
+	return ptr, e.patch("add", ptr, empty)
+}
+
+// lookup returns the JSON pointer to the member named name of the object at ptr. Top-level
+// sections are matched case-insensitively.
+func (e *PolicyEditor) lookup(ptr, name string) (string, bool) {
+	parent := e.value.Find(ptr)
+	if parent == nil {
+		return "", false
+	}
+	obj, ok := parent.Value.(*hujson.Object)
+	if !ok {
+		return "", false
+	}
+	for _, member := range obj.Members {
+		key := member.Name.Value.(hujson.Literal).String()
+		if key == name || (ptr == "" && strings.EqualFold(key, name)) {
+			return ptr + "/" + pointerEscape(key), true
+		}
+	}
+	return "", false
+}
+
+// strings returns the strings of the array at ptr.
+func (e *PolicyEditor) strings(ptr string) []string {
+	var values []string
+	if v := e.value.Find(ptr); v != nil {
+		if arr, ok := v.Value.(*hujson.Array); ok {
+			for _, elem := range arr.Elements {
+				if lit, ok := elem.Value.(hujson.Literal); ok && lit.Kind() == '"' {
+					values = append(values, lit.String())
+				}
+			}
+		}
+	}
+	return values
+}
+
+func (e *PolicyEditor) patch(op, path string, value any) error {
+	operation := map[string]any{"op": op, "path": path}
+	if op != "remove" {
+		operation["value"] = value
+	}
+	patch, err := json.Marshal([]any{operation})
+	if err != nil {
+		return err
+	}
+	return e.value.Patch(patch)
+}
+
+// pointerEscape escapes s for use as a JSON pointer (RFC 6901) reference token.
+func pointerEscape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// Edit gets the policy file of the tailnet as HuJSON, calls edit to modify it using a
+// [PolicyEditor], and sets the result, preserving comments and formatting. The policy file is
+// only set if it has not been modified since it was retrieved, as checked using its ETag;
+// otherwise, the returned error satisfies [IsPreconditionFailed].
+func (pr *PolicyFileResource) Edit(ctx context.Context, edit func(*PolicyEditor) error, opts ...WriteOption) error {
+	raw, err := pr.Raw(ctx)
+	if err != nil {
+		return err
+	}
+	editor, err := NewPolicyEditor(raw.HuJSON)
+	if err != nil {
+		return err
+	}
+	if err := edit(editor); err != nil {
+		return err
+	}
+	return pr.Set(ctx, editor.HuJSON(), raw.ETag, opts...)
+}
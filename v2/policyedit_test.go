@@ -0,0 +1,88 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tailscale/hujson"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+const editablePolicy = `{
+  // Engineering team.
+  "Groups": {
+    "group:eng": ["alice@example.com"], // Alice leads the team.
+  },
+  "acls": [
+    // Everyone can reach their own devices.
+    {"action": "accept", "src": ["autogroup:member"], "dst": ["autogroup:self:*"]},
+  ],
+}`
+
+func TestPolicyEditor(t *testing.T) {
+	t.Parallel()
+
+	editor, err := tsclient.NewPolicyEditor(editablePolicy)
+	require.NoError(t, err)
+
+	require.NoError(t, editor.AddGroupMember("group:eng", "bob@example.com"))
+	require.NoError(t, editor.AddGroupMember("group:eng", "bob@example.com"))
+	require.NoError(t, editor.AddGroupMember("group:ops", "carl@example.com"))
+	require.NoError(t, editor.RemoveGroupMember("group:eng", "alice@example.com"))
+	require.NoError(t, editor.AppendACL(tsclient.ACLEntry{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:dev:*"}}))
+	require.NoError(t, editor.AppendGrant(tsclient.ACLGrant{Source: []string{"group:ops"}, Destination: []string{"tag:prod"}, IP: []string{"*"}}))
+	require.NoError(t, editor.SetTagOwners("tag:dev", []string{"group:eng"}))
+	require.NoError(t, editor.SetTagOwners("tag:dev", []string{"group:ops"}))
+
+	huJSON := editor.HuJSON()
+	assert.Contains(t, huJSON, "// Engineering team.")
+	assert.Contains(t, huJSON, "// Everyone can reach their own devices.")
+	assert.NotContains(t, huJSON, `"groups"`, "the existing Groups section should be reused")
+
+	var acl tsclient.ACL
+	standard, err := hujson.Standardize([]byte(huJSON))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(standard, &acl))
+	assert.Equal(t, map[string][]string{
+		"group:eng": {"bob@example.com"},
+		"group:ops": {"carl@example.com"},
+	}, acl.Groups)
+	assert.Len(t, acl.ACLs, 2)
+	assert.Equal(t, []string{"tag:dev:*"}, acl.ACLs[1].Destination)
+	assert.Equal(t, []tsclient.ACLGrant{{Source: []string{"group:ops"}, Destination: []string{"tag:prod"}, IP: []string{"*"}}}, acl.Grants)
+	assert.Equal(t, map[string][]string{"tag:dev": {"group:ops"}}, acl.TagOwners)
+
+	_, err = tsclient.NewPolicyEditor(`[]`)
+	assert.Error(t, err)
+}
+
+func TestClient_EditACL(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = []byte(editablePolicy)
+	server.ResponseHeader.Set("ETag", "myetag")
+
+	err := client.PolicyFile().Edit(context.Background(), func(editor *tsclient.PolicyEditor) error {
+		return editor.AddGroupMember("group:eng", "bob@example.com")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, `"myetag"`, server.Header.Get("If-Match"))
+	assert.Contains(t, server.Body.String(), "// Alice leads the team.")
+	assert.Contains(t, server.Body.String(), "bob@example.com")
+
+	server.ResponseCode = http.StatusPreconditionFailed
+	server.ResponseBody = map[string]string{"message": "precondition failed"}
+	err = client.PolicyFile().Set(context.Background(), editablePolicy, "stale")
+	assert.True(t, tsclient.IsPreconditionFailed(err))
+}
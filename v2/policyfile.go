@@ -5,8 +5,16 @@ package tsclient
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tailscale/hujson"
 )
 
 // PolicyFileResource provides access to https://tailscale.com/api#tag/policyfile.
@@ -17,6 +25,7 @@ type PolicyFileResource struct {
 // ACL contains the schema for a tailnet policy file. More details: https://tailscale.com/kb/1018/acls/
 type ACL struct {
 	ACLs                []ACLEntry          `json:"acls,omitempty" hujson:"ACLs,omitempty"`
+	Grants              []ACLGrant          `json:"grants,omitempty" hujson:"Grants,omitempty"`
 	AutoApprovers       *ACLAutoApprovers   `json:"autoApprovers,omitempty" hujson:"AutoApprovers,omitempty"`
 	Groups              map[string][]string `json:"groups,omitempty" hujson:"Groups,omitempty"`
 	Hosts               map[string]string   `json:"hosts,omitempty" hujson:"Hosts,omitempty"`
@@ -28,16 +37,121 @@ type ACL struct {
 	DisableIPv4         bool                `json:"disableIPv4,omitempty" hujson:"DisableIPv4,omitempty"`
 	OneCGNATRoute       string              `json:"oneCGNATRoute,omitempty" hujson:"OneCGNATRoute,omitempty"`
 	RandomizeClientPort bool                `json:"randomizeClientPort,omitempty" hujson:"RandomizeClientPort,omitempty"`
+	SSHTests            []ACLSSHTest        `json:"sshTests,omitempty" hujson:"SSHTests,omitempty"`
+	IPSets              map[string][]string `json:"ipsets,omitempty" hujson:"IPSets,omitempty"`
+
+	// Postures maps the names of device postures, such as "posture:latestMac", to their
+	// conditions. More details: https://tailscale.com/kb/1288/device-posture
+	Postures map[string]ACLPosture `json:"postures,omitempty" hujson:"Postures,omitempty"`
+	// DefaultSourcePosture lists the postures that sources must match for rules that do not
+	// specify their own SourcePosture.
+	DefaultSourcePosture []string `json:"defaultSrcPosture,omitempty" hujson:"DefaultSrcPosture,omitempty"`
 
-	// Postures and DefaultSourcePosture are for an experimental feature and not yet public or documented as of 2023-08-17.
-	// This API is subject to change. Internal bug: corp/13986
-	Postures             map[string][]string `json:"postures,omitempty" hujson:"Postures,omitempty"`
-	DefaultSourcePosture []string            `json:"defaultSrcPosture,omitempty" hujson:"DefaultSrcPosture,omitempty"`
+	// Unknown holds the top-level sections of the policy file that are not modelled by this
+	// type, such as sections introduced after this version of the package. They are preserved
+	// when marshalling the ACL, so that setting a policy that was retrieved does not drop them.
+	Unknown map[string]json.RawMessage `json:"-"`
 
 	// ETag is the etag corresponding to this version of the ACL
 	ETag string `json:"-"`
 }
 
+// aclFields is ACL without its methods, for use when marshalling.
+type aclFields ACL
+
+// UnmarshalJSON unmarshals data into the ACL, storing sections that are not modelled by
+// [ACL] into Unknown.
+func (acl *ACL) UnmarshalJSON(data []byte) error {
+	var fields aclFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	unknown, err := unknownFields[ACL](data)
+	if err != nil {
+		return err
+	}
+	fields.Unknown = unknown
+	*acl = ACL(fields)
+	return nil
+}
+
+// MarshalJSON marshals the ACL, including its Unknown sections. It fails if the ACL contains
+// invalid IP addresses or CIDR prefixes, as reported by [ACL.ValidateAddresses].
+func (acl ACL) MarshalJSON() ([]byte, error) {
+	if err := acl.ValidateAddresses(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(aclFields(acl))
+	if err != nil {
+		return nil, err
+	}
+	return withUnknownFields[ACL](data, acl.Unknown)
+}
+
+// unknownFields returns the fields of the JSON object data that are not fields of T, or nil if
+// there are none.
+func unknownFields[T any](data []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var unknown map[string]json.RawMessage
+	for name, field := range fields {
+		if !isJSONField[T](name) {
+			if unknown == nil {
+				unknown = make(map[string]json.RawMessage)
+			}
+			unknown[name] = field
+		}
+	}
+	return unknown, nil
+}
+
+// withUnknownFields adds the unknown fields to the JSON object data, which is the marshalled
+// form of a T. Fields of T take precedence over unknown fields of the same name.
+func withUnknownFields[T any](data []byte, unknown map[string]json.RawMessage) ([]byte, error) {
+	if len(unknown) == 0 {
+		return data, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, field := range unknown {
+		if !isJSONField[T](name) {
+			fields[name] = field
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// isJSONField reports whether name is the JSON name of a field of T, matching it
+// case-insensitively like encoding/json does.
+func isJSONField[T any](name string) bool {
+	t := reflect.TypeFor[T]()
+	for i := range t.NumField() {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag != "-" && strings.EqualFold(tag, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseACL parses a policy file in HuJSON format, such as one checked into a repository or
+// returned by [PolicyFileResource.Raw], into an [ACL].
+func ParseACL(huJSON string) (*ACL, error) {
+	data, err := hujson.Standardize([]byte(huJSON))
+	if err != nil {
+		return nil, err
+	}
+	var acl ACL
+	if err := json.Unmarshal(data, &acl); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
 // RawACL contains a raw HuJSON ACL and its associated ETag.
 type RawACL struct {
 	// HuJSON is the raw HuJSON ACL string
@@ -45,11 +159,17 @@ type RawACL struct {
 
 	// ETag is the etag corresponding to this version of the ACL
 	ETag string
+
+	// LastModified is the time at which this version of the ACL was set, if reported by the API.
+	LastModified time.Time
 }
 
 type ACLAutoApprovers struct {
 	Routes   map[string][]string `json:"routes,omitempty" hujson:"Routes,omitempty"`
 	ExitNode []string            `json:"exitNode,omitempty" hujson:"ExitNode,omitempty"`
+	// Services maps services, such as "svc:web", to the tags, users and groups whose devices
+	// are automatically approved to host them.
+	Services map[string][]string `json:"services,omitempty" hujson:"Services,omitempty"`
 }
 
 type ACLEntry struct {
@@ -60,7 +180,23 @@ type ACLEntry struct {
 	Destination []string `json:"dst,omitempty" hujson:"Dst,omitempty"`
 	Protocol    string   `json:"proto,omitempty" hujson:"Proto,omitempty"`
 
-	// SourcePosture is for an experimental feature and not yet public or documented as of 2023-08-17.
+	// SourcePosture lists the names of postures, one of which sources must match.
+	SourcePosture []string `json:"srcPosture,omitempty" hujson:"SrcPosture,omitempty"`
+}
+
+// ACLGrant grants access from sources to destinations, using the grant syntax which generalizes
+// [ACLEntry]. More details: https://tailscale.com/kb/1324/acl-grants
+type ACLGrant struct {
+	Source      []string `json:"src" hujson:"Src"`
+	Destination []string `json:"dst" hujson:"Dst"`
+	// IP lists the network capabilities granted, such as "*", "tcp:443" or "80-90".
+	IP []string `json:"ip,omitempty" hujson:"IP,omitempty"`
+	// App maps application capability names, such as "tailscale.com/cap/drive", to the
+	// parameters of the capability, which are defined by the application.
+	App map[string][]map[string]any `json:"app,omitempty" hujson:"App,omitempty"`
+	// Via lists the tags of the devices, such as exit nodes, subnet routers or app connectors,
+	// through which traffic to Destination must be routed.
+	Via           []string `json:"via,omitempty" hujson:"Via,omitempty"`
 	SourcePosture []string `json:"srcPosture,omitempty" hujson:"SrcPosture,omitempty"`
 }
 
@@ -72,6 +208,18 @@ type ACLTest struct {
 	Accept []string `json:"accept,omitempty" hujson:"Accept,omitempty"`
 }
 
+// ACLSSHTest asserts which SSH connections a source is allowed to make. More details:
+// https://tailscale.com/kb/1337/acl-syntax#sshtests
+type ACLSSHTest struct {
+	Source      string   `json:"src" hujson:"Src"`
+	Destination []string `json:"dst" hujson:"Dst"`
+	// Accept, Check and Deny list the users as which the source can connect without additional
+	// verification, can connect after a check, and cannot connect, respectively.
+	Accept []string `json:"accept,omitempty" hujson:"Accept,omitempty"`
+	Check  []string `json:"check,omitempty" hujson:"Check,omitempty"`
+	Deny   []string `json:"deny,omitempty" hujson:"Deny,omitempty"`
+}
+
 type ACLDERPMap struct {
 	Regions            map[int]*ACLDERPRegion `json:"regions" hujson:"Regions"`
 	OmitDefaultRegions bool                   `json:"omitDefaultRegions,omitempty" hujson:"OmitDefaultRegions,omitempty"`
@@ -105,12 +253,148 @@ type ACLSSH struct {
 	CheckPeriod     Duration `json:"checkPeriod,omitempty" hujson:"CheckPeriod,omitempty"`
 	Recorder        []string `json:"recorder,omitempty" hujson:"Recorder,omitempty"`
 	EnforceRecorder bool     `json:"enforceRecorder,omitempty" hujson:"EnforceRecorder,omitempty"`
+	// AcceptEnv lists the environment variables that clients may forward to the destination,
+	// as names that may contain the wildcards "*" and "?".
+	AcceptEnv []string `json:"acceptEnv,omitempty" hujson:"AcceptEnv,omitempty"`
+}
+
+const (
+	ACLSSHActionAccept = "accept"
+	ACLSSHActionCheck  = "check"
+
+	// DefaultSSHCheckPeriod is the check period of [ACLSSH] rules with the check action that do not
+	// specify a CheckPeriod.
+	DefaultSSHCheckPeriod = 12 * time.Hour
+	// MinSSHCheckPeriod and MaxSSHCheckPeriod bound the CheckPeriod of [ACLSSH] rules.
+	MinSSHCheckPeriod = time.Minute
+	MaxSSHCheckPeriod = 7 * 24 * time.Hour
+)
+
+// SSHCheckPeriodAlways is the CheckPeriod of [ACLSSH] rules that require users to re-authenticate
+// for every connection. It is represented as "always" in policy files.
+const SSHCheckPeriodAlways Duration = -1
+
+var sshAcceptEnvPattern = regexp.MustCompile(`^[A-Za-z_*?][A-Za-z0-9_*?]*$`)
+
+// Validate checks that the action, check period and accepted environment variables of the rule
+// are accepted by the API.
+func (s ACLSSH) Validate() error {
+	switch s.Action {
+	case ACLSSHActionAccept:
+		if s.CheckPeriod != 0 {
+			return fmt.Errorf("checkPeriod is only valid for the %q action", ACLSSHActionCheck)
+		}
+	case ACLSSHActionCheck:
+		if period := time.Duration(s.CheckPeriod); period != 0 && s.CheckPeriod != SSHCheckPeriodAlways && (period < MinSSHCheckPeriod || period > MaxSSHCheckPeriod) {
+			return fmt.Errorf("checkPeriod %v must be between %v and %v, or %q", period, MinSSHCheckPeriod, MaxSSHCheckPeriod, SSHCheckPeriodAlways)
+		}
+	default:
+		return fmt.Errorf("invalid action %q, must be %q or %q", s.Action, ACLSSHActionAccept, ACLSSHActionCheck)
+	}
+	for _, env := range s.AcceptEnv {
+		if !sshAcceptEnvPattern.MatchString(env) {
+			return fmt.Errorf("invalid acceptEnv %q, must be an environment variable name, optionally with * and ? wildcards", env)
+		}
+	}
+	return nil
+}
+
+// ChecksEveryConnection reports whether the rule requires users to re-authenticate for every
+// connection, rather than once per check period.
+func (s ACLSSH) ChecksEveryConnection() bool {
+	return s.Action == ACLSSHActionCheck && s.CheckPeriod == SSHCheckPeriodAlways
+}
+
+// EffectiveCheckPeriod returns the period after which users must re-authenticate to use a rule with
+// the check action, or zero for other actions and for rules that check every connection.
+func (s ACLSSH) EffectiveCheckPeriod() time.Duration {
+	if s.Action != ACLSSHActionCheck || s.CheckPeriod == SSHCheckPeriodAlways {
+		return 0
+	}
+	if s.CheckPeriod == 0 {
+		return DefaultSSHCheckPeriod
+	}
+	return time.Duration(s.CheckPeriod)
 }
 
 type NodeAttrGrant struct {
-	Target []string                       `json:"target,omitempty" hujson:"Target,omitempty"`
-	Attr   []string                       `json:"attr,omitempty" hujson:"Attr,omitempty"`
-	App    map[string][]*NodeAttrGrantApp `json:"app,omitempty" hujson:"App,omitempty"`
+	Target []string `json:"target,omitempty" hujson:"Target,omitempty"`
+	// Attr lists the names of the attributes granted to Target, such as "funnel".
+	Attr []string                       `json:"attr,omitempty" hujson:"Attr,omitempty"`
+	App  map[string][]*NodeAttrGrantApp `json:"app,omitempty" hujson:"App,omitempty"`
+
+	// Values holds the values of the attributes in Attr that have one, keyed by attribute name.
+	// In the policy file, such attributes are given as objects mapping their name to their value,
+	// rather than as strings.
+	Values map[string]json.RawMessage `json:"-"`
+	// Unknown holds the fields of the grant that are not modelled by this type. They are
+	// preserved when marshalling the grant.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// nodeAttrGrantFields is NodeAttrGrant without its methods, for use when marshalling.
+type nodeAttrGrantFields NodeAttrGrant
+
+// UnmarshalJSON unmarshals data into the grant, storing the values of valued attributes into
+// Values and fields that are not modelled by [NodeAttrGrant] into Unknown.
+func (g *NodeAttrGrant) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		nodeAttrGrantFields
+		Attr []json.RawMessage `json:"attr"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	grant := NodeAttrGrant(fields.nodeAttrGrantFields)
+	grant.Attr = nil
+	for _, attr := range fields.Attr {
+		var name string
+		if err := json.Unmarshal(attr, &name); err == nil {
+			grant.Attr = append(grant.Attr, name)
+			continue
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(attr, &values); err != nil {
+			return fmt.Errorf("node attribute must be a string or an object, got %s", attr)
+		}
+		for _, name := range sortedKeys(values) {
+			if grant.Values == nil {
+				grant.Values = make(map[string]json.RawMessage)
+			}
+			grant.Attr = append(grant.Attr, name)
+			grant.Values[name] = values[name]
+		}
+	}
+
+	unknown, err := unknownFields[NodeAttrGrant](data)
+	if err != nil {
+		return err
+	}
+	grant.Unknown = unknown
+	*g = grant
+	return nil
+}
+
+// MarshalJSON marshals the grant, including the values of its attributes and its Unknown fields.
+func (g NodeAttrGrant) MarshalJSON() ([]byte, error) {
+	fields := struct {
+		nodeAttrGrantFields
+		Attr []any `json:"attr,omitempty"`
+	}{nodeAttrGrantFields: nodeAttrGrantFields(g)}
+	for _, name := range g.Attr {
+		if value, ok := g.Values[name]; ok {
+			fields.Attr = append(fields.Attr, map[string]json.RawMessage{name: value})
+		} else {
+			fields.Attr = append(fields.Attr, name)
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return withUnknownFields[NodeAttrGrant](data, g.Unknown)
 }
 
 type NodeAttrGrantApp struct {
@@ -119,9 +403,11 @@ type NodeAttrGrantApp struct {
 	Domains    []string `json:"domains,omitempty" hujson:"Domains,omitempty"`
 }
 
-// Get retrieves the [ACL] that is currently set for the tailnet.
-func (pr *PolicyFileResource) Get(ctx context.Context) (*ACL, error) {
-	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildTailnetURL("acl"))
+// Get retrieves the [ACL] that is currently set for the tailnet. The ETag of the returned ACL is
+// populated from the response, so that it can be passed to [PolicyFileResource.Set] to only
+// update the policy file if it has not changed since.
+func (pr *PolicyFileResource) Get(ctx context.Context, opts ...GetOption) (*ACL, error) {
+	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildTailnetURL("acl"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -134,9 +420,11 @@ func (pr *PolicyFileResource) Get(ctx context.Context) (*ACL, error) {
 	return acl, nil
 }
 
-// Raw retrieves the [ACL] that is currently set for the tailnet as a HuJSON string.
-func (pr *PolicyFileResource) Raw(ctx context.Context) (*RawACL, error) {
-	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildTailnetURL("acl"), requestContentType("application/hujson"))
+// Raw retrieves the [ACL] that is currently set for the tailnet as a HuJSON string, preserving
+// comments and formatting, along with its ETag and modification time. Pass the ETag to
+// [PolicyFileResource.Set] to only update the policy file if it has not changed since.
+func (pr *PolicyFileResource) Raw(ctx context.Context, opts ...GetOption) (*RawACL, error) {
+	req, err := pr.buildRequest(ctx, http.MethodGet, pr.buildTailnetURL("acl"), requestContentType("application/hujson"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -147,15 +435,30 @@ func (pr *PolicyFileResource) Raw(ctx context.Context) (*RawACL, error) {
 		return nil, err
 	}
 
+	// The Last-Modified header is informative, so a missing or malformed value is ignored.
+	lastModified, _ := http.ParseTime(header.Get("Last-Modified"))
 	return &RawACL{
-		HuJSON: string(resp),
-		ETag:   header.Get("Etag"),
+		HuJSON:       string(resp),
+		ETag:         header.Get("Etag"),
+		LastModified: lastModified,
 	}, nil
 }
 
+// ErrPolicyETagRequired is returned by [PolicyFileResource.Set] when called without an ETag or
+// [WithForce] by a [Client] with RequirePolicyETag set, and by [PolicySnapshot.Restore] when the
+// ETag of the applied policy file is unknown.
+var ErrPolicyETagRequired = errors.New("setting the policy file without an ETag requires WithForce")
+
 // Set sets the [ACL] for the tailnet. acl can either be an [ACL], or a HuJSON string.
 // etag is an optional value that, if supplied, will be used in the "If-Match" HTTP request header.
-func (pr *PolicyFileResource) Set(ctx context.Context, acl any, etag string) error {
+// If the Client has RequirePolicyETag set, etag may only be empty if [WithForce] is passed.
+func (pr *PolicyFileResource) Set(ctx context.Context, acl any, etag string, opts ...WriteOption) error {
+	_, err := pr.set(ctx, acl, etag, opts...)
+	return err
+}
+
+// set implements [PolicyFileResource.Set], returning the ETag of the new policy file.
+func (pr *PolicyFileResource) set(ctx context.Context, acl any, etag string, opts ...WriteOption) (string, error) {
 	headers := make(map[string]string)
 	if etag != "" {
 		headers["If-Match"] = fmt.Sprintf("%q", etag)
@@ -164,27 +467,36 @@ func (pr *PolicyFileResource) Set(ctx context.Context, acl any, etag string) err
 	reqOpts := []requestOption{
 		requestHeaders(headers),
 		requestBody(acl),
+		writeOptions(opts),
 	}
 	switch v := acl.(type) {
 	case ACL:
 	case string:
 		reqOpts = append(reqOpts, requestContentType("application/hujson"))
 	default:
-		return fmt.Errorf("expected ACL content as a string or as ACL struct; got %T", v)
+		return "", fmt.Errorf("expected ACL content as a string or as ACL struct; got %T", v)
 	}
 
 	req, err := pr.buildRequest(ctx, http.MethodPost, pr.buildTailnetURL("acl"), reqOpts...)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if etag == "" && pr.RequirePolicyETag && !forced(req.Context()) {
+		return "", ErrPolicyETagRequired
 	}
 
-	return pr.do(req, nil)
+	header, err := pr.doWithResponseHeaders(req, nil)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("Etag"), nil
 }
 
 // Validate validates the provided ACL via the API. acl can either be an [ACL], or a HuJSON string.
-func (pr *PolicyFileResource) Validate(ctx context.Context, acl any) error {
+func (pr *PolicyFileResource) Validate(ctx context.Context, acl any, opts ...WriteOption) error {
 	reqOpts := []requestOption{
 		requestBody(acl),
+		writeOptions(opts),
 	}
 	switch v := acl.(type) {
 	case ACL:
@@ -204,7 +516,136 @@ func (pr *PolicyFileResource) Validate(ctx context.Context, acl any) error {
 		return err
 	}
 	if response.Message != "" {
-		return fmt.Errorf("ACL validation failed: %s; %v", response.Message, response.Data)
+		return aclValidationError{response}
 	}
 	return nil
 }
+
+// ValidateTests validates acl via the API like [PolicyFileResource.Validate], and returns the
+// failures of its tests, matched to acl.Tests as described for [ACLTestFailures]. It returns nil
+// failures if the policy is valid, and an error if the validation fails for another reason than
+// failing tests, such as an invalid policy.
+func (pr *PolicyFileResource) ValidateTests(ctx context.Context, acl ACL, opts ...WriteOption) ([]ACLTestFailure, error) {
+	err := pr.Validate(ctx, acl, opts...)
+	var validationErr aclValidationError
+	if errors.As(err, &validationErr) {
+		if failures := ACLTestFailures(err, acl.Tests); failures != nil {
+			return failures, nil
+		}
+	}
+	return nil, err
+}
+
+// aclValidationError is returned by [PolicyFileResource.Validate] when the API reports
+// a validation failure. It unwraps to the [APIError] describing the failure, so that
+// [ErrorData] and [ACLTestFailures] can be used on it.
+type aclValidationError struct {
+	apiErr APIError
+}
+
+func (err aclValidationError) Error() string {
+	return fmt.Sprintf("ACL validation failed: %s; %v", err.apiErr.Message, err.apiErr.Data)
+}
+
+func (err aclValidationError) Unwrap() error {
+	return err.apiErr
+}
+
+// ACLTestFailure describes a failure of one of the [ACLTest] entries submitted to the API.
+type ACLTestFailure struct {
+	// Index is the index of the failing test within the submitted tests, or -1 if the
+	// failure could not be matched to a submitted test.
+	Index int
+	// Test is the failing test, or nil if the failure could not be matched to a submitted test.
+	Test *ACLTest
+	// Source is the user or source the API reported the failure for.
+	Source string
+	// Errors are the individual assertion failures reported by the API.
+	Errors []string
+}
+
+// ACLTestFailures maps the [APIErrorData] of err back to the given tests, which should be
+// the tests that were submitted with the policy. Failures are matched to tests by the
+// test's Source, falling back to its User. When several tests share the same source,
+// failures are matched to them in order.
+//
+// Returns a nil slice if err does not carry any [APIErrorData].
+func ACLTestFailures(err error, tests []ACLTest) []ACLTestFailure {
+	data := ErrorData(err)
+	if len(data) == 0 {
+		return nil
+	}
+
+	used := make([]bool, len(tests))
+	failures := make([]ACLTestFailure, 0, len(data))
+	for _, d := range data {
+		failure := ACLTestFailure{
+			Index:  -1,
+			Source: d.User,
+			Errors: d.Errors,
+		}
+		for i := range tests {
+			if used[i] || aclTestSource(tests[i]) != d.User {
+				continue
+			}
+			used[i] = true
+			failure.Index = i
+			failure.Test = &tests[i]
+			break
+		}
+		failures = append(failures, failure)
+	}
+	return failures
+}
+
+// aclTestSource returns the source of the given test, which is its Source if set,
+// or its User otherwise.
+func aclTestSource(test ACLTest) string {
+	if test.Source != "" {
+		return test.Source
+	}
+	return test.User
+}
+
+// Messages returns a single line for each of the failure's errors, in the form
+// "tests[1] (src user@example.com): <error>", suitable for annotating CI output.
+func (f ACLTestFailure) Messages() []string {
+	prefix := "tests[?]"
+	if f.Index >= 0 {
+		prefix = fmt.Sprintf("tests[%d]", f.Index)
+	}
+
+	messages := make([]string, 0, len(f.Errors))
+	for _, e := range f.Errors {
+		messages = append(messages, fmt.Sprintf("%s (src %s): %s", prefix, f.Source, e))
+	}
+	return messages
+}
+
+// ACLTestAssertion is a failed assertion of an [ACLTest], parsed from an error reported by the API.
+type ACLTestAssertion struct {
+	// Destination is the destination of the assertion, as resolved by the API, such as
+	// "100.60.3.4:22". It is empty if the error could not be parsed.
+	Destination string
+	// Want and Got are the expected and actual results of the assertion, such as "Accept" and "Drop".
+	Want string
+	Got  string
+	// Message is the error as reported by the API.
+	Message string
+}
+
+var aclTestErrorPattern = regexp.MustCompile(`^address "([^"]*)": want: (\w+), got: (\w+)$`)
+
+// Assertions parses the errors of the failure into structured assertions. Errors that are not
+// in a known format only populate the Message of their assertion.
+func (f ACLTestFailure) Assertions() []ACLTestAssertion {
+	assertions := make([]ACLTestAssertion, 0, len(f.Errors))
+	for _, e := range f.Errors {
+		assertion := ACLTestAssertion{Message: e}
+		if m := aclTestErrorPattern.FindStringSubmatch(e); m != nil {
+			assertion.Destination, assertion.Want, assertion.Got = m[1], m[2], m[3]
+		}
+		assertions = append(assertions, assertion)
+	}
+	return assertions
+}
@@ -8,10 +8,12 @@ import (
 	_ "embed"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tailscale/hujson"
 	tsclient "github.com/tailscale/tailscale-client-go/v2"
 )
@@ -118,6 +120,21 @@ func TestACL_Unmarshal(t *testing.T) {
 						CheckPeriod: tsclient.Duration(time.Hour * 20),
 					},
 				},
+				Grants: []tsclient.ACLGrant{
+					{
+						Source:      []string{"group:dev"},
+						Destination: []string{"tag:dev"},
+						IP:          []string{"tcp:443"},
+						Via:         []string{"tag:exit"},
+					},
+					{
+						Source:      []string{"autogroup:members"},
+						Destination: []string{"tag:files"},
+						App: map[string][]map[string]any{
+							"tailscale.com/cap/drive": {{"shares": []any{"docs"}, "access": "ro"}},
+						},
+					},
+				},
 			},
 		},
 		{
@@ -198,6 +215,21 @@ func TestACL_Unmarshal(t *testing.T) {
 						CheckPeriod: tsclient.Duration(time.Hour * 20),
 					},
 				},
+				Grants: []tsclient.ACLGrant{
+					{
+						Source:      []string{"group:dev"},
+						Destination: []string{"tag:dev"},
+						IP:          []string{"tcp:443"},
+						Via:         []string{"tag:exit"},
+					},
+					{
+						Source:      []string{"autogroup:members"},
+						Destination: []string{"tag:files"},
+						App: map[string][]map[string]any{
+							"tailscale.com/cap/drive": {{"shares": []any{"docs"}, "access": "ro"}},
+						},
+					},
+				},
 				Tests: []tsclient.ACLTest{
 					{
 						User:   "",
@@ -315,6 +347,24 @@ func TestClient_SetACLWithETag(t *testing.T) {
 	assert.EqualValues(t, expectedACL, actualACL)
 }
 
+func TestClient_SetACLRequireETag(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	client.RequirePolicyETag = true
+	server.ResponseCode = http.StatusOK
+
+	assert.ErrorIs(t, client.PolicyFile().Set(context.Background(), string(huJSONACL), ""), tsclient.ErrPolicyETagRequired)
+	assert.Empty(t, server.Method)
+
+	assert.NoError(t, client.PolicyFile().Set(context.Background(), string(huJSONACL), "test-etag"))
+	assert.Equal(t, `"test-etag"`, server.Header.Get("If-Match"))
+
+	assert.NoError(t, client.PolicyFile().Set(context.Background(), string(huJSONACL), "", tsclient.WithForce()))
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "", server.Header.Get("If-Match"))
+}
+
 func TestClient_ACL(t *testing.T) {
 	t.Parallel()
 
@@ -373,10 +423,12 @@ func TestClient_RawACL(t *testing.T) {
 	server.ResponseCode = http.StatusOK
 	server.ResponseBody = huJSONACL
 	server.ResponseHeader.Add("ETag", "myetag")
+	server.ResponseHeader.Add("Last-Modified", "Tue, 02 Jan 2024 03:04:05 GMT")
 
 	expectedRawACL := &tsclient.RawACL{
-		HuJSON: string(huJSONACL),
-		ETag:   "myetag",
+		HuJSON:       string(huJSONACL),
+		ETag:         "myetag",
+		LastModified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
 	}
 	acl, err := client.PolicyFile().Raw(context.Background())
 	assert.NoError(t, err)
@@ -385,3 +437,189 @@ func TestClient_RawACL(t *testing.T) {
 	assert.EqualValues(t, "application/hujson", server.Header.Get("Accept"))
 	assert.EqualValues(t, "/api/v2/tailnet/example.com/acl", server.Path)
 }
+
+func TestACL_UnknownSections(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+		"acls": [{"action": "accept", "src": ["*"], "dst": ["*:*"]}],
+		"ssh": [{"action": "check", "src": ["group:eng"], "dst": ["tag:prod"], "users": ["root"], "checkPeriod": "always", "acceptEnv": ["GIT_*"]}],
+		"sshTests": [{"src": "alice@example.com", "dst": ["tag:prod"], "accept": ["root"], "check": ["admin"]}],
+		"ipsets": {"ipset:office": ["192.0.2.0/24"]},
+		"futureSection": {"enabled": true}
+	}`)
+
+	var acl tsclient.ACL
+	require.NoError(t, json.Unmarshal(data, &acl))
+	assert.Equal(t, []tsclient.ACLSSHTest{{
+		Source:      "alice@example.com",
+		Destination: []string{"tag:prod"},
+		Accept:      []string{"root"},
+		Check:       []string{"admin"},
+	}}, acl.SSHTests)
+	assert.Equal(t, map[string][]string{"ipset:office": {"192.0.2.0/24"}}, acl.IPSets)
+	assert.True(t, acl.SSH[0].ChecksEveryConnection())
+	assert.Equal(t, []string{"GIT_*"}, acl.SSH[0].AcceptEnv)
+	assert.Equal(t, map[string]json.RawMessage{"futureSection": json.RawMessage(`{"enabled": true}`)}, acl.Unknown)
+
+	marshalled, err := json.Marshal(acl)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(marshalled))
+}
+
+func TestNodeAttrGrant_Values(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+		"nodeAttrs": [
+			{"target": ["tag:exit"], "attr": ["funnel", {"custom:limits": {"max": 10}}, "nextdns:abc123"]},
+			{"target": ["autogroup:member"], "ipPool": ["100.81.0.0/16"]}
+		]
+	}`)
+
+	var acl tsclient.ACL
+	require.NoError(t, json.Unmarshal(data, &acl))
+	assert.Equal(t, []tsclient.NodeAttrGrant{
+		{
+			Target: []string{"tag:exit"},
+			Attr:   []string{"funnel", "custom:limits", "nextdns:abc123"},
+			Values: map[string]json.RawMessage{"custom:limits": json.RawMessage(`{"max": 10}`)},
+		},
+		{
+			Target:  []string{"autogroup:member"},
+			Unknown: map[string]json.RawMessage{"ipPool": json.RawMessage(`["100.81.0.0/16"]`)},
+		},
+	}, acl.NodeAttrs)
+
+	marshalled, err := json.Marshal(acl)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(marshalled))
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"attr": [1]}`), &tsclient.NodeAttrGrant{}), "node attribute must be a string or an object, got 1")
+}
+
+func TestACL_AutoApproverServices(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"autoApprovers": {"routes": {"10.0.0.0/8": ["tag:router"]}, "services": {"svc:web": ["tag:web"]}}}`)
+
+	var acl tsclient.ACL
+	require.NoError(t, json.Unmarshal(data, &acl))
+	assert.Equal(t, &tsclient.ACLAutoApprovers{
+		Routes:   map[string][]string{"10.0.0.0/8": {"tag:router"}},
+		Services: map[string][]string{"svc:web": {"tag:web"}},
+	}, acl.AutoApprovers)
+
+	marshalled, err := json.Marshal(acl)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(marshalled))
+}
+
+func TestACL_ValidateAddresses(t *testing.T) {
+	t.Parallel()
+
+	acl := tsclient.ACL{
+		Hosts: map[string]string{"db": "100.64.0.10", "cache": "10.0.0.256", "net": "192.0.2.0/24"},
+		ACLs: []tsclient.ACLEntry{
+			{Action: "accept", Source: []string{"100.64.0.1-100.64.0.9"}, Destination: []string{"db:5432", "192.0.2.0/33:22", "[fd7a:115c:a1e0::1]:22"}},
+		},
+		AutoApprovers: &tsclient.ACLAutoApprovers{Routes: map[string][]string{"10.0.0.0/8": {"tag:router"}, "10.1.0.0": {"tag:router"}}},
+	}
+
+	err := acl.ValidateAddresses()
+	assert.EqualError(t, err, strings.Join([]string{
+		`hosts["cache"]: invalid address "10.0.0.256": ParseAddr("10.0.0.256"): IPv4 field has value >255`,
+		`autoApprovers.routes["10.1.0.0"]: invalid address "10.1.0.0": netip.ParsePrefix("10.1.0.0"): no '/'`,
+		`acls[0].dst[1]: invalid address "192.0.2.0/33": netip.ParsePrefix("192.0.2.0/33"): prefix length out of range`,
+	}, "\n"))
+
+	var addrErr tsclient.PolicyAddressError
+	assert.ErrorAs(t, err, &addrErr)
+	assert.Equal(t, `hosts["cache"]`, addrErr.Path)
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	assert.ErrorAs(t, client.PolicyFile().Set(context.Background(), acl, ""), &addrErr)
+	assert.Empty(t, server.Method)
+}
+
+func TestClient_ValidateACL(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = tsclient.APIError{
+		Message: "test(s) failed",
+		Data: []tsclient.APIErrorData{
+			{
+				User:   "user2@example.com",
+				Errors: []string{`address "100.60.3.4:22": want: Accept, got: Drop`},
+			},
+		},
+	}
+
+	tests := []tsclient.ACLTest{
+		{
+			User:  "user1@example.com",
+			Allow: []string{"example-host-1:22"},
+		},
+		{
+			Source: "user2@example.com",
+			Allow:  []string{"100.60.3.4:22"},
+		},
+	}
+
+	err := client.PolicyFile().Validate(context.Background(), tsclient.ACL{Tests: tests})
+	assert.EqualError(t, err, `ACL validation failed: test(s) failed; [{user2@example.com [address "100.60.3.4:22": want: Accept, got: Drop]}]`)
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/tailnet/example.com/acl/validate", server.Path)
+
+	failures := tsclient.ACLTestFailures(err, tests)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, 1, failures[0].Index)
+	assert.Equal(t, &tests[1], failures[0].Test)
+	assert.Equal(t, []string{
+		`tests[1] (src user2@example.com): address "100.60.3.4:22": want: Accept, got: Drop`,
+	}, failures[0].Messages())
+	assert.Equal(t, []tsclient.ACLTestAssertion{{
+		Destination: "100.60.3.4:22",
+		Want:        "Accept",
+		Got:         "Drop",
+		Message:     `address "100.60.3.4:22": want: Accept, got: Drop`,
+	}}, failures[0].Assertions())
+
+	failures, err = client.PolicyFile().ValidateTests(context.Background(), tsclient.ACL{Tests: tests})
+	assert.NoError(t, err)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, 1, failures[0].Index)
+
+	server.ResponseBody = tsclient.APIError{Message: "line 3: unexpected token"}
+	failures, err = client.PolicyFile().ValidateTests(context.Background(), tsclient.ACL{Tests: tests})
+	assert.ErrorContains(t, err, "unexpected token")
+	assert.Nil(t, failures)
+
+	server.ResponseBody = map[string]any{}
+	failures, err = client.PolicyFile().ValidateTests(context.Background(), tsclient.ACL{Tests: tests})
+	assert.NoError(t, err)
+	assert.Nil(t, failures)
+}
+
+func TestACLTestFailures_Unmatched(t *testing.T) {
+	t.Parallel()
+
+	err := tsclient.APIError{
+		Data: []tsclient.APIErrorData{
+			{
+				User:   "user3@example.com",
+				Errors: []string{"some failure"},
+			},
+		},
+	}
+
+	failures := tsclient.ACLTestFailures(err, nil)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, -1, failures[0].Index)
+	assert.Nil(t, failures[0].Test)
+	assert.Equal(t, []string{"tests[?] (src user3@example.com): some failure"}, failures[0].Messages())
+	assert.Equal(t, []tsclient.ACLTestAssertion{{Message: "some failure"}}, failures[0].Assertions())
+}
@@ -0,0 +1,325 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ACLLintFinding describes a construct in a policy file found by [ACL.Lint] that the API accepts,
+// but that is likely a mistake.
+type ACLLintFinding struct {
+	// Rule is the name of the lint rule that produced the finding, such as "ssh-check-identity".
+	Rule string
+	// Path locates the construct within the policy file, such as "ssh[2]".
+	Path    string
+	Message string
+}
+
+func (f ACLLintFinding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Path, f.Message, f.Rule)
+}
+
+type aclLintRule struct {
+	name  string
+	check func(acl *ACL, report func(path, format string, args ...any))
+}
+
+var aclLintRules = []aclLintRule{
+	{"ssh-check-period", lintSSHCheckPeriod},
+	{"ssh-check-identity", lintSSHCheckIdentity},
+	{"selector-syntax", lintSelectorSyntax},
+	{"grant-syntax", lintGrantSyntax},
+	{"posture-syntax", lintPostureSyntax},
+	{"undefined-reference", lintUndefinedReferences},
+	{"unused-group", lintUnusedGroups},
+	{"unused-host", lintUnusedHosts},
+	{"unowned-tag", lintUnownedTags},
+	{"shadowed-rule", lintShadowedRules},
+}
+
+// Lint checks acl for likely mistakes, returning a finding for every problem found, ordered by rule.
+func (acl *ACL) Lint() []ACLLintFinding {
+	var findings []ACLLintFinding
+	for _, rule := range aclLintRules {
+		rule.check(acl, func(path, format string, args ...any) {
+			findings = append(findings, ACLLintFinding{Rule: rule.name, Path: path, Message: fmt.Sprintf(format, args...)})
+		})
+	}
+	return findings
+}
+
+// lintSSHCheckPeriod flags SSH rules with an invalid action or check period.
+func lintSSHCheckPeriod(acl *ACL, report func(path, format string, args ...any)) {
+	for i, rule := range acl.SSH {
+		if err := rule.Validate(); err != nil {
+			report(fmt.Sprintf("ssh[%d]", i), "%v", err)
+		}
+	}
+}
+
+// lintSSHCheckIdentity flags SSH rules with the check action whose sources are all tagged devices.
+// Tagged devices have no user identity to re-authenticate with the identity provider, so such
+// rules can never be satisfied and lock everyone out.
+func lintSSHCheckIdentity(acl *ACL, report func(path, format string, args ...any)) {
+	for i, rule := range acl.SSH {
+		if rule.Action != ACLSSHActionCheck || len(rule.Source) == 0 {
+			continue
+		}
+		viable := false
+		for _, src := range rule.Source {
+			if !strings.HasPrefix(src, TagPrefix) && src != AutogroupTagged {
+				viable = true
+				break
+			}
+		}
+		if !viable {
+			report(fmt.Sprintf("ssh[%d]", i), "check rule only has tagged sources, which cannot re-authenticate with an identity provider")
+		}
+	}
+}
+
+// lintGrantSyntax flags grants that are invalid, as reported by [ACLGrant.Validate].
+func lintGrantSyntax(acl *ACL, report func(path, format string, args ...any)) {
+	for i, grant := range acl.Grants {
+		if err := grant.Validate(); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				report(fmt.Sprintf("grants[%d]", i), "%s", line)
+			}
+		}
+	}
+}
+
+// lintPostureSyntax flags invalid postures and references to undefined postures, as reported by
+// [ACL.ValidatePostures].
+func lintPostureSyntax(acl *ACL, report func(path, format string, args ...any)) {
+	if err := acl.ValidatePostures(); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			path, message, _ := strings.Cut(line, ": ")
+			report(path, "%s", message)
+		}
+	}
+}
+
+// lintSelectorSyntax flags sources and destinations of rules that are not valid selectors, such as
+// misspelled autogroups or prefixes.
+func lintSelectorSyntax(acl *ACL, report func(path, format string, args ...any)) {
+	check := func(path string, values []string, validate func(string) error) {
+		for j, v := range values {
+			if err := validate(v); err != nil {
+				report(fmt.Sprintf("%s[%d]", path, j), "%v", err)
+			}
+		}
+	}
+	for i, rule := range acl.ACLs {
+		check(fmt.Sprintf("acls[%d].src", i), rule.Source, ValidateSelector)
+		check(fmt.Sprintf("acls[%d].dst", i), rule.Destination, ValidateDestination)
+	}
+	for i, grant := range acl.Grants {
+		check(fmt.Sprintf("grants[%d].src", i), grant.Source, ValidateSelector)
+		check(fmt.Sprintf("grants[%d].dst", i), grant.Destination, ValidateSelector)
+	}
+	for i, rule := range acl.SSH {
+		check(fmt.Sprintf("ssh[%d].src", i), rule.Source, ValidateSelector)
+		check(fmt.Sprintf("ssh[%d].dst", i), rule.Destination, ValidateSelector)
+	}
+}
+
+// aclReference is a selector used in a policy file, such as a source or destination of a rule.
+type aclReference struct {
+	path     string
+	selector string
+}
+
+// references returns the selectors used in acl outside the sections that define them, in the
+// order they appear. The ports of destinations are removed.
+func (acl *ACL) references() []aclReference {
+	var refs []aclReference
+	add := func(path string, values ...string) {
+		for i, v := range values {
+			refs = append(refs, aclReference{fmt.Sprintf("%s[%d]", path, i), v})
+		}
+	}
+	addDestinations := func(path string, values ...string) {
+		for i, v := range values {
+			if selector, ports, ok := splitDestination(v); ok && validPorts(ports) {
+				v = selector
+			}
+			refs = append(refs, aclReference{fmt.Sprintf("%s[%d]", path, i), v})
+		}
+	}
+
+	for i, rule := range acl.ACLs {
+		add(fmt.Sprintf("acls[%d].src", i), rule.Source...)
+		add(fmt.Sprintf("acls[%d].users", i), rule.Users...)
+		addDestinations(fmt.Sprintf("acls[%d].dst", i), rule.Destination...)
+		addDestinations(fmt.Sprintf("acls[%d].ports", i), rule.Ports...)
+	}
+	for i, grant := range acl.Grants {
+		add(fmt.Sprintf("grants[%d].src", i), grant.Source...)
+		add(fmt.Sprintf("grants[%d].dst", i), grant.Destination...)
+		add(fmt.Sprintf("grants[%d].via", i), grant.Via...)
+	}
+	for i, rule := range acl.SSH {
+		add(fmt.Sprintf("ssh[%d].src", i), rule.Source...)
+		add(fmt.Sprintf("ssh[%d].dst", i), rule.Destination...)
+	}
+	for _, tag := range sortedKeys(acl.TagOwners) {
+		add(fmt.Sprintf("tagOwners[%q]", tag), acl.TagOwners[tag]...)
+	}
+	if acl.AutoApprovers != nil {
+		for _, route := range sortedKeys(acl.AutoApprovers.Routes) {
+			add(fmt.Sprintf("autoApprovers.routes[%q]", route), acl.AutoApprovers.Routes[route]...)
+		}
+		add("autoApprovers.exitNode", acl.AutoApprovers.ExitNode...)
+		for _, service := range sortedKeys(acl.AutoApprovers.Services) {
+			add(fmt.Sprintf("autoApprovers.services[%q]", service), acl.AutoApprovers.Services[service]...)
+		}
+	}
+	for i, grant := range acl.NodeAttrs {
+		add(fmt.Sprintf("nodeAttrs[%d].target", i), grant.Target...)
+	}
+	for i, test := range acl.Tests {
+		if test.User != "" {
+			add(fmt.Sprintf("tests[%d].user", i), test.User)
+		}
+		if test.Source != "" {
+			add(fmt.Sprintf("tests[%d].src", i), test.Source)
+		}
+		addDestinations(fmt.Sprintf("tests[%d].accept", i), test.Accept...)
+		addDestinations(fmt.Sprintf("tests[%d].allow", i), test.Allow...)
+		addDestinations(fmt.Sprintf("tests[%d].deny", i), test.Deny...)
+	}
+	for i, test := range acl.SSHTests {
+		add(fmt.Sprintf("sshTests[%d].src", i), test.Source)
+		add(fmt.Sprintf("sshTests[%d].dst", i), test.Destination...)
+	}
+	return refs
+}
+
+// lintUndefinedReferences flags selectors referring to groups, tags, IP sets or host aliases
+// that are not defined in the policy file. Tags are defined by their entry in tagOwners.
+func lintUndefinedReferences(acl *ACL, report func(path, format string, args ...any)) {
+	for _, ref := range acl.references() {
+		var defined bool
+		switch {
+		case strings.HasPrefix(ref.selector, GroupPrefix):
+			_, defined = acl.Groups[ref.selector]
+		case strings.HasPrefix(ref.selector, TagPrefix):
+			_, defined = acl.TagOwners[ref.selector]
+		case strings.HasPrefix(ref.selector, IPSetPrefix):
+			_, defined = acl.IPSets[ref.selector]
+		case isHostAlias(ref.selector):
+			_, defined = acl.Hosts[ref.selector]
+		default:
+			continue
+		}
+		if !defined {
+			report(ref.path, "%q is not defined", ref.selector)
+		}
+	}
+}
+
+// lintUnusedGroups flags groups that are not referred to anywhere in the policy file.
+func lintUnusedGroups(acl *ACL, report func(path, format string, args ...any)) {
+	used := usedSelectors(acl)
+	for _, group := range sortedKeys(acl.Groups) {
+		if !used[group] {
+			report(fmt.Sprintf("groups[%q]", group), "group is not used")
+		}
+	}
+}
+
+// lintUnusedHosts flags host aliases that are not referred to anywhere in the policy file.
+func lintUnusedHosts(acl *ACL, report func(path, format string, args ...any)) {
+	used := usedSelectors(acl)
+	for _, host := range sortedKeys(acl.Hosts) {
+		if !used[host] {
+			report(fmt.Sprintf("hosts[%q]", host), "host is not used")
+		}
+	}
+}
+
+func usedSelectors(acl *ACL) map[string]bool {
+	used := make(map[string]bool)
+	for _, ref := range acl.references() {
+		used[ref.selector] = true
+	}
+	return used
+}
+
+// lintUnownedTags flags tags without owners, which only admins can apply to devices.
+func lintUnownedTags(acl *ACL, report func(path, format string, args ...any)) {
+	for _, tag := range sortedKeys(acl.TagOwners) {
+		if len(acl.TagOwners[tag]) == 0 {
+			report(fmt.Sprintf("tagOwners[%q]", tag), "tag has no owners, so only admins can apply it")
+		}
+	}
+}
+
+// lintShadowedRules flags ACL entries and grants that only allow traffic that an earlier rule
+// already allows, so removing them would not change the policy.
+func lintShadowedRules(acl *ACL, report func(path, format string, args ...any)) {
+	for i, rule := range acl.ACLs {
+		for j := range i {
+			if aclEntryCovers(acl.ACLs[j], rule) {
+				report(fmt.Sprintf("acls[%d]", i), "rule is shadowed by acls[%d], which already allows all of its traffic", j)
+				break
+			}
+		}
+	}
+	for i, grant := range acl.Grants {
+		for j := range i {
+			if aclGrantCovers(acl.Grants[j], grant) {
+				report(fmt.Sprintf("grants[%d]", i), "grant is shadowed by grants[%d], which already allows all of its traffic", j)
+				break
+			}
+		}
+	}
+}
+
+// aclEntryCovers reports whether the ACL entry a allows all the traffic that b allows.
+func aclEntryCovers(a, b ACLEntry) bool {
+	if a.Action != b.Action || (a.Protocol != "" && a.Protocol != b.Protocol) || !slices.Equal(a.SourcePosture, b.SourcePosture) {
+		return false
+	}
+	if !coversAll(append(a.Source, a.Users...), append(b.Source, b.Users...), "*") {
+		return false
+	}
+	aDst := append(slices.Clone(a.Destination), a.Ports...)
+	for _, dst := range append(slices.Clone(b.Destination), b.Ports...) {
+		selector, _, _ := splitDestination(dst)
+		if !slices.Contains(aDst, dst) && !slices.Contains(aDst, "*:*") && !slices.Contains(aDst, selector+":*") {
+			return false
+		}
+	}
+	return true
+}
+
+// aclGrantCovers reports whether the network capabilities granted by a include those granted by
+// b. Grants with application capabilities, routing or posture conditions are never considered
+// to cover one another.
+func aclGrantCovers(a, b ACLGrant) bool {
+	for _, g := range []ACLGrant{a, b} {
+		if len(g.App) > 0 || len(g.Via) > 0 || len(g.SourcePosture) > 0 {
+			return false
+		}
+	}
+	return coversAll(a.Source, b.Source, "*") && coversAll(a.Destination, b.Destination, "*") && coversAll(a.IP, b.IP, "*")
+}
+
+// coversAll reports whether every value of b is in a, or a contains wildcard.
+func coversAll(a, b []string, wildcard string) bool {
+	if len(b) == 0 || slices.Contains(a, wildcard) {
+		return true
+	}
+	for _, v := range b {
+		if !slices.Contains(a, v) {
+			return false
+		}
+	}
+	return true
+}
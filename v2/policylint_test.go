@@ -0,0 +1,100 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestACL_Lint_SSHCheck(t *testing.T) {
+	t.Parallel()
+
+	acl := &tsclient.ACL{
+		Groups:    map[string][]string{"group:eng": {"alice@example.com"}},
+		TagOwners: map[string][]string{"tag:ci": {"group:eng"}, "tag:prod": {"group:eng"}},
+		SSH: []tsclient.ACLSSH{
+			{Action: "check", Source: []string{"autogroup:member"}, Destination: []string{"autogroup:self"}, Users: []string{"autogroup:nonroot"}},
+			{Action: "check", Source: []string{"tag:ci"}, Destination: []string{"tag:prod"}, Users: []string{"root"}},
+			{Action: "check", Source: []string{"group:eng"}, CheckPeriod: tsclient.Duration(30 * time.Second)},
+			{Action: "accept", Source: []string{"group:eng"}, CheckPeriod: tsclient.Duration(time.Hour)},
+		},
+	}
+
+	var findings []string
+	for _, f := range acl.Lint() {
+		findings = append(findings, f.String())
+	}
+	assert.Equal(t, []string{
+		`ssh[2]: checkPeriod 30s must be between 1m0s and 168h0m0s, or "always" (ssh-check-period)`,
+		`ssh[3]: checkPeriod is only valid for the "check" action (ssh-check-period)`,
+		`ssh[1]: check rule only has tagged sources, which cannot re-authenticate with an identity provider (ssh-check-identity)`,
+	}, findings)
+}
+
+func TestACLSSH_EffectiveCheckPeriod(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, tsclient.DefaultSSHCheckPeriod, tsclient.ACLSSH{Action: "check"}.EffectiveCheckPeriod())
+	assert.Equal(t, time.Hour, tsclient.ACLSSH{Action: "check", CheckPeriod: tsclient.Duration(time.Hour)}.EffectiveCheckPeriod())
+	assert.Zero(t, tsclient.ACLSSH{Action: "accept"}.EffectiveCheckPeriod())
+	assert.Error(t, tsclient.ACLSSH{Action: "deny"}.Validate())
+
+	always := tsclient.ACLSSH{Action: "check", CheckPeriod: tsclient.SSHCheckPeriodAlways}
+	assert.NoError(t, always.Validate())
+	assert.True(t, always.ChecksEveryConnection())
+	assert.Zero(t, always.EffectiveCheckPeriod())
+	assert.False(t, tsclient.ACLSSH{Action: "check"}.ChecksEveryConnection())
+
+	assert.NoError(t, tsclient.ACLSSH{Action: "accept", AcceptEnv: []string{"GIT_*", "LANG", "LC_?"}}.Validate())
+	assert.Error(t, tsclient.ACLSSH{Action: "accept", AcceptEnv: []string{"NOT-VALID"}}.Validate())
+}
+
+func TestACL_Lint_Definitions(t *testing.T) {
+	t.Parallel()
+
+	acl, err := tsclient.ParseACL(`{
+		"groups": {
+			"group:eng": ["alice@example.com"],
+			"group:old": ["bob@example.com"], // Not used.
+		},
+		"hosts": {
+			"db": "100.64.0.10",
+			"legacy": "100.64.0.11", // Not used.
+		},
+		"tagOwners": {
+			"tag:web": ["group:eng"],
+			"tag:admin": [],
+		},
+		"acls": [
+			{"action": "accept", "src": ["group:eng"], "dst": ["tag:web:*", "db:5432"]},
+			{"action": "accept", "src": ["group:eng"], "dst": ["tag:web:443"]},
+			{"action": "accept", "src": ["group:ops"], "dst": ["tag:api:443", "cache:6379"]},
+		],
+		"grants": [
+			{"src": ["*"], "dst": ["tag:web"], "ip": ["*"]},
+			{"src": ["group:eng"], "dst": ["tag:web"], "ip": ["443"]},
+		],
+	}`)
+	require.NoError(t, err)
+
+	var findings []string
+	for _, f := range acl.Lint() {
+		findings = append(findings, f.String())
+	}
+	assert.Equal(t, []string{
+		`acls[2].src[0]: "group:ops" is not defined (undefined-reference)`,
+		`acls[2].dst[0]: "tag:api" is not defined (undefined-reference)`,
+		`acls[2].dst[1]: "cache" is not defined (undefined-reference)`,
+		`groups["group:old"]: group is not used (unused-group)`,
+		`hosts["legacy"]: host is not used (unused-host)`,
+		`tagOwners["tag:admin"]: tag has no owners, so only admins can apply it (unowned-tag)`,
+		`acls[1]: rule is shadowed by acls[0], which already allows all of its traffic (shadowed-rule)`,
+		`grants[1]: grant is shadowed by grants[0], which already allows all of its traffic (shadowed-rule)`,
+	}, findings)
+}
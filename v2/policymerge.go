@@ -0,0 +1,169 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ACLMergeError is returned by [MergeACLs] when fragments define the same entity differently.
+type ACLMergeError struct {
+	Conflicts []ACLMergeConflict
+}
+
+// ACLMergeConflict describes an entity that is defined differently by several fragments.
+type ACLMergeConflict struct {
+	// Path locates the entity within the policy file, such as `groups["group:eng"]` or
+	// "disableIPv4".
+	Path string
+	// Fragments are the indexes of the fragments that define the entity differently, in order.
+	Fragments []int
+}
+
+func (c ACLMergeConflict) String() string {
+	fragments := make([]string, len(c.Fragments))
+	for i, f := range c.Fragments {
+		fragments[i] = fmt.Sprint(f)
+	}
+	return fmt.Sprintf("%s is defined differently in fragments %s", c.Path, strings.Join(fragments, ", "))
+}
+
+func (err ACLMergeError) Error() string {
+	conflicts := make([]string, len(err.Conflicts))
+	for i, c := range err.Conflicts {
+		conflicts[i] = c.String()
+	}
+	return fmt.Sprintf("merging policy fragments: %s", strings.Join(conflicts, "; "))
+}
+
+// MergeACLs combines partial policy files, such as the files of different teams that each define
+// their own groups and rules, into a single policy file. Rules, grants, tests and node attributes
+// are concatenated in the order of the fragments, and auto approvers are combined. Groups, hosts,
+// tag owners, IP sets, postures, unknown sections and settings such as DisableIPv4 may be
+// defined by several fragments only if they are defined identically; otherwise, an
+// [ACLMergeError] listing every conflict is returned. The ETags of the fragments are ignored.
+func MergeACLs(fragments ...ACL) (ACL, error) {
+	m := aclMerger{defined: make(map[string][]int)}
+	for i, fragment := range fragments {
+		m.fragment = i
+		m.merged.ACLs = append(m.merged.ACLs, fragment.ACLs...)
+		m.merged.Grants = append(m.merged.Grants, fragment.Grants...)
+		m.merged.SSH = append(m.merged.SSH, fragment.SSH...)
+		m.merged.Tests = append(m.merged.Tests, fragment.Tests...)
+		m.merged.SSHTests = append(m.merged.SSHTests, fragment.SSHTests...)
+		m.merged.NodeAttrs = append(m.merged.NodeAttrs, fragment.NodeAttrs...)
+
+		mergeMap(&m, "groups", &m.merged.Groups, fragment.Groups)
+		mergeMap(&m, "hosts", &m.merged.Hosts, fragment.Hosts)
+		mergeMap(&m, "tagOwners", &m.merged.TagOwners, fragment.TagOwners)
+		mergeMap(&m, "ipsets", &m.merged.IPSets, fragment.IPSets)
+		mergeMap(&m, "postures", &m.merged.Postures, fragment.Postures)
+		mergeMap(&m, "", &m.merged.Unknown, fragment.Unknown)
+
+		mergeValue(&m, "derpMap", &m.merged.DERPMap, fragment.DERPMap)
+		mergeValue(&m, "disableIPv4", &m.merged.DisableIPv4, fragment.DisableIPv4)
+		mergeValue(&m, "oneCGNATRoute", &m.merged.OneCGNATRoute, fragment.OneCGNATRoute)
+		mergeValue(&m, "randomizeClientPort", &m.merged.RandomizeClientPort, fragment.RandomizeClientPort)
+		mergeValue(&m, "defaultSrcPosture", &m.merged.DefaultSourcePosture, fragment.DefaultSourcePosture)
+
+		if fragment.AutoApprovers != nil {
+			if m.merged.AutoApprovers == nil {
+				m.merged.AutoApprovers = &ACLAutoApprovers{}
+			}
+			approvers := m.merged.AutoApprovers
+			for route, owners := range fragment.AutoApprovers.Routes {
+				if approvers.Routes == nil {
+					approvers.Routes = make(map[string][]string)
+				}
+				approvers.Routes[route] = appendMissing(approvers.Routes[route], owners...)
+			}
+			approvers.ExitNode = appendMissing(approvers.ExitNode, fragment.AutoApprovers.ExitNode...)
+			for service, owners := range fragment.AutoApprovers.Services {
+				if approvers.Services == nil {
+					approvers.Services = make(map[string][]string)
+				}
+				approvers.Services[service] = appendMissing(approvers.Services[service], owners...)
+			}
+		}
+	}
+
+	var conflicts []ACLMergeConflict
+	for _, path := range sortedKeys(m.conflicting) {
+		conflicts = append(conflicts, ACLMergeConflict{Path: path, Fragments: m.defined[path]})
+	}
+	if len(conflicts) > 0 {
+		return ACL{}, ACLMergeError{Conflicts: conflicts}
+	}
+	return m.merged, nil
+}
+
+// aclMerger holds the state of [MergeACLs].
+type aclMerger struct {
+	merged   ACL
+	fragment int
+	// defined maps the paths of entities to the fragments that define them, and conflicting
+	// records the paths of entities that are defined differently.
+	defined     map[string][]int
+	conflicting map[string]bool
+}
+
+// define records that the current fragment defines the entity at path, and whether it defines it
+// the same as the previous fragments.
+func (m *aclMerger) define(path string, same bool) {
+	m.defined[path] = append(m.defined[path], m.fragment)
+	if !same {
+		if m.conflicting == nil {
+			m.conflicting = make(map[string]bool)
+		}
+		m.conflicting[path] = true
+	}
+}
+
+// mergeMap adds the entries of src to *dst, recording a conflict for keys whose values differ.
+// Entries of the section named "" are top-level sections.
+func mergeMap[V any](m *aclMerger, section string, dst *map[string]V, src map[string]V) {
+	for key, value := range src {
+		path := key
+		if section != "" {
+			path = fmt.Sprintf("%s[%q]", section, key)
+		}
+		existing, ok := (*dst)[key]
+		if !ok {
+			if *dst == nil {
+				*dst = make(map[string]V)
+			}
+			(*dst)[key] = value
+			m.define(path, true)
+			continue
+		}
+		m.define(path, reflect.DeepEqual(existing, value))
+	}
+}
+
+// mergeValue sets *dst to src if src is not the zero value, recording a conflict if *dst was
+// already set to a different value.
+func mergeValue[V any](m *aclMerger, path string, dst *V, src V) {
+	if reflect.ValueOf(&src).Elem().IsZero() {
+		return
+	}
+	if reflect.ValueOf(dst).Elem().IsZero() {
+		*dst = src
+		m.define(path, true)
+		return
+	}
+	m.define(path, reflect.DeepEqual(*dst, src))
+}
+
+// appendMissing appends the values that are not already in s.
+func appendMissing(s []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(s, v) {
+			s = append(s, v)
+		}
+	}
+	return s
+}
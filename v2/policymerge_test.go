@@ -0,0 +1,61 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestMergeACLs(t *testing.T) {
+	t.Parallel()
+
+	platform := tsclient.ACL{
+		Groups:        map[string][]string{"group:platform": {"alice@example.com"}},
+		TagOwners:     map[string][]string{"tag:shared": {"group:platform"}},
+		ACLs:          []tsclient.ACLEntry{{Action: "accept", Source: []string{"group:platform"}, Destination: []string{"*:*"}}},
+		AutoApprovers: &tsclient.ACLAutoApprovers{Routes: map[string][]string{"10.0.0.0/8": {"group:platform"}}},
+		DisableIPv4:   true,
+		ETag:          "platform",
+	}
+	data := tsclient.ACL{
+		Groups:        map[string][]string{"group:data": {"bob@example.com"}},
+		TagOwners:     map[string][]string{"tag:shared": {"group:platform"}, "tag:db": {"group:data"}},
+		ACLs:          []tsclient.ACLEntry{{Action: "accept", Source: []string{"group:data"}, Destination: []string{"tag:db:5432"}}},
+		AutoApprovers: &tsclient.ACLAutoApprovers{Routes: map[string][]string{"10.0.0.0/8": {"group:data"}}},
+		DisableIPv4:   true,
+	}
+
+	merged, err := tsclient.MergeACLs(platform, data)
+	require.NoError(t, err)
+	assert.Equal(t, tsclient.ACL{
+		Groups:        map[string][]string{"group:platform": {"alice@example.com"}, "group:data": {"bob@example.com"}},
+		TagOwners:     map[string][]string{"tag:shared": {"group:platform"}, "tag:db": {"group:data"}},
+		ACLs:          append(platform.ACLs, data.ACLs...),
+		AutoApprovers: &tsclient.ACLAutoApprovers{Routes: map[string][]string{"10.0.0.0/8": {"group:platform", "group:data"}}},
+		DisableIPv4:   true,
+	}, merged)
+	assert.Equal(t, []string{"group:platform"}, platform.AutoApprovers.Routes["10.0.0.0/8"])
+}
+
+func TestMergeACLs_Conflicts(t *testing.T) {
+	t.Parallel()
+
+	_, err := tsclient.MergeACLs(
+		tsclient.ACL{Groups: map[string][]string{"group:eng": {"alice@example.com"}}, OneCGNATRoute: "mac"},
+		tsclient.ACL{Hosts: map[string]string{"db": "100.64.0.1"}},
+		tsclient.ACL{Groups: map[string][]string{"group:eng": {"bob@example.com"}}, OneCGNATRoute: "all"},
+		tsclient.ACL{Groups: map[string][]string{"group:eng": {"alice@example.com"}}},
+	)
+	var mergeErr tsclient.ACLMergeError
+	require.ErrorAs(t, err, &mergeErr)
+	assert.Equal(t, []tsclient.ACLMergeConflict{
+		{Path: `groups["group:eng"]`, Fragments: []int{0, 2, 3}},
+		{Path: "oneCGNATRoute", Fragments: []int{0, 2}},
+	}, mergeErr.Conflicts)
+	assert.EqualError(t, err, `merging policy fragments: groups["group:eng"] is defined differently in fragments 0, 2, 3; oneCGNATRoute is defined differently in fragments 0, 2`)
+}
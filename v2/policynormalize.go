@@ -0,0 +1,167 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// NormalizeACL returns a canonical form of acl, so that policy files that only differ in the
+// order of unordered values or in formatting compare equal, and diffs between them do not show
+// spurious changes. Lists whose order has no meaning, such as the sources and destinations of
+// rules and the members of groups, are sorted with duplicates removed, IP addresses and CIDR
+// prefixes are formatted canonically, and empty lists and maps are replaced by nil. The order of
+// rules and tests is preserved. acl is not modified.
+func NormalizeACL(acl ACL) ACL {
+	acl.Groups = normalizeSetMap(acl.Groups, normalizeSelector)
+	acl.TagOwners = normalizeSetMap(acl.TagOwners, normalizeSelector)
+	acl.IPSets = normalizeSetMap(acl.IPSets, normalizeSelector)
+	if len(acl.Postures) > 0 {
+		postures := make(map[string]ACLPosture, len(acl.Postures))
+		for name, posture := range acl.Postures {
+			postures[name] = posture.normalize()
+		}
+		acl.Postures = postures
+	} else {
+		acl.Postures = nil
+	}
+	acl.DefaultSourcePosture = normalizeSet(acl.DefaultSourcePosture, strings.TrimSpace)
+	if len(acl.Hosts) > 0 {
+		hosts := make(map[string]string, len(acl.Hosts))
+		for name, addr := range acl.Hosts {
+			hosts[name] = normalizeSelector(addr)
+		}
+		acl.Hosts = hosts
+	} else {
+		acl.Hosts = nil
+	}
+	if acl.AutoApprovers != nil {
+		autoApprovers := ACLAutoApprovers{
+			ExitNode: normalizeSet(acl.AutoApprovers.ExitNode, normalizeSelector),
+			Services: normalizeSetMap(acl.AutoApprovers.Services, normalizeSelector),
+		}
+		for route, approvers := range acl.AutoApprovers.Routes {
+			if autoApprovers.Routes == nil {
+				autoApprovers.Routes = make(map[string][]string)
+			}
+			route = normalizeSelector(route)
+			autoApprovers.Routes[route] = normalizeSet(append(autoApprovers.Routes[route], approvers...), normalizeSelector)
+		}
+		acl.AutoApprovers = &autoApprovers
+	}
+
+	acl.ACLs = normalizeEach(acl.ACLs, func(rule ACLEntry) ACLEntry {
+		rule.Source = normalizeSet(rule.Source, normalizeSelector)
+		rule.Destination = normalizeSet(rule.Destination, normalizeDestination)
+		rule.Users = normalizeSet(rule.Users, normalizeSelector)
+		rule.Ports = normalizeSet(rule.Ports, normalizeDestination)
+		rule.SourcePosture = normalizeSet(rule.SourcePosture, strings.TrimSpace)
+		return rule
+	})
+	acl.Grants = normalizeEach(acl.Grants, func(grant ACLGrant) ACLGrant {
+		grant.Source = normalizeSet(grant.Source, normalizeSelector)
+		grant.Destination = normalizeSet(grant.Destination, normalizeSelector)
+		grant.IP = normalizeSet(grant.IP, strings.TrimSpace)
+		grant.Via = normalizeSet(grant.Via, normalizeSelector)
+		grant.SourcePosture = normalizeSet(grant.SourcePosture, strings.TrimSpace)
+		if len(grant.App) == 0 {
+			grant.App = nil
+		}
+		return grant
+	})
+	acl.SSH = normalizeEach(acl.SSH, func(rule ACLSSH) ACLSSH {
+		rule.Source = normalizeSet(rule.Source, normalizeSelector)
+		rule.Destination = normalizeSet(rule.Destination, normalizeSelector)
+		rule.Users = normalizeSet(rule.Users, strings.TrimSpace)
+		rule.Recorder = normalizeSet(rule.Recorder, normalizeSelector)
+		rule.AcceptEnv = normalizeSet(rule.AcceptEnv, strings.TrimSpace)
+		return rule
+	})
+	acl.NodeAttrs = normalizeEach(acl.NodeAttrs, func(grant NodeAttrGrant) NodeAttrGrant {
+		grant.Target = normalizeSet(grant.Target, normalizeSelector)
+		grant.Attr = normalizeSet(grant.Attr, strings.TrimSpace)
+		if len(grant.App) == 0 {
+			grant.App = nil
+		}
+		return grant
+	})
+	acl.Tests = normalizeEach(acl.Tests, func(test ACLTest) ACLTest {
+		test.Allow = normalizeSet(test.Allow, normalizeDestination)
+		test.Deny = normalizeSet(test.Deny, normalizeDestination)
+		test.Accept = normalizeSet(test.Accept, normalizeDestination)
+		return test
+	})
+	acl.SSHTests = normalizeEach(acl.SSHTests, func(test ACLSSHTest) ACLSSHTest {
+		test.Destination = normalizeSet(test.Destination, normalizeSelector)
+		test.Accept = normalizeSet(test.Accept, strings.TrimSpace)
+		test.Check = normalizeSet(test.Check, strings.TrimSpace)
+		test.Deny = normalizeSet(test.Deny, strings.TrimSpace)
+		return test
+	})
+	if len(acl.Unknown) == 0 {
+		acl.Unknown = nil
+	}
+	return acl
+}
+
+// normalizeEach returns a copy of s with normalize applied to each element, or nil if s is empty.
+func normalizeEach[T any](s []T, normalize func(T) T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	normalized := make([]T, len(s))
+	for i, v := range s {
+		normalized[i] = normalize(v)
+	}
+	return normalized
+}
+
+// normalizeSet returns a sorted copy of s without duplicates, with normalize applied to each
+// element, or nil if s is empty.
+func normalizeSet(s []string, normalize func(string) string) []string {
+	normalized := normalizeEach(s, normalize)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+func normalizeSetMap(m map[string][]string, normalize func(string) string) map[string][]string {
+	if len(m) == 0 {
+		return nil
+	}
+	normalized := make(map[string][]string, len(m))
+	for k, s := range m {
+		normalized[k] = normalizeSet(s, normalize)
+	}
+	return normalized
+}
+
+// normalizeSelector trims s and formats it canonically if it is an IP address or CIDR prefix.
+func normalizeSelector(s string) string {
+	s = strings.TrimSpace(s)
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.String()
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.String()
+	}
+	return s
+}
+
+// normalizeDestination normalizes the selector of the destination s, leaving its ports as is.
+func normalizeDestination(s string) string {
+	s = strings.TrimSpace(s)
+	selector, ports, ok := splitDestination(s)
+	if !ok {
+		return s
+	}
+	selector = normalizeSelector(selector)
+	if strings.Contains(selector, ":") && !strings.HasPrefix(selector, "[") {
+		if _, err := netip.ParseAddr(selector); err == nil {
+			selector = "[" + selector + "]"
+		}
+	}
+	return selector + ":" + ports
+}
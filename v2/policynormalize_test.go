@@ -0,0 +1,45 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestNormalizeACL(t *testing.T) {
+	t.Parallel()
+
+	acl := tsclient.ACL{
+		Groups: map[string][]string{"group:eng": {"bob@example.com", "alice@example.com", "bob@example.com"}},
+		Hosts:  map[string]string{"db": " fd7a:115c:a1e0:0:0:0:0:1 "},
+		ACLs: []tsclient.ACLEntry{
+			{Action: "accept", Source: []string{"group:eng", "tag:ci"}, Destination: []string{"tag:web:443", "[fd7a:115c:a1e0:0::2]:22"}},
+			{Action: "accept", Source: []string{"*"}, Destination: []string{"autogroup:internet:*"}, Users: []string{}},
+		},
+		SSH: []tsclient.ACLSSH{
+			{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:prod", "autogroup:self"}, Users: []string{"root", "autogroup:nonroot"}},
+		},
+		TagOwners: map[string][]string{},
+		ETag:      "etag",
+	}
+	original := acl.ACLs[0].Source[0]
+
+	assert.Equal(t, tsclient.ACL{
+		Groups: map[string][]string{"group:eng": {"alice@example.com", "bob@example.com"}},
+		Hosts:  map[string]string{"db": "fd7a:115c:a1e0::1"},
+		ACLs: []tsclient.ACLEntry{
+			{Action: "accept", Source: []string{"group:eng", "tag:ci"}, Destination: []string{"[fd7a:115c:a1e0::2]:22", "tag:web:443"}},
+			{Action: "accept", Source: []string{"*"}, Destination: []string{"autogroup:internet:*"}},
+		},
+		SSH: []tsclient.ACLSSH{
+			{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"autogroup:self", "tag:prod"}, Users: []string{"autogroup:nonroot", "root"}},
+		},
+		ETag: "etag",
+	}, tsclient.NormalizeACL(acl))
+	assert.Equal(t, original, acl.ACLs[0].Source[0])
+	assert.Equal(t, []string{"tag:web:443", "[fd7a:115c:a1e0:0::2]:22"}, acl.ACLs[0].Destination)
+}
@@ -0,0 +1,189 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ACLPosture is a device posture of a policy file, which devices match if they satisfy every
+// one of its conditions.
+type ACLPosture []PostureCondition
+
+// PostureCondition is a condition of an [ACLPosture], which compares a device posture attribute
+// to values, such as "node:os IN ['macos', 'linux']" or "node:tsVersion >= '1.60'".
+type PostureCondition string
+
+// PostureExpression is a parsed [PostureCondition].
+type PostureExpression struct {
+	// Attribute is the device posture attribute, such as "node:os" or "custom:tier".
+	Attribute string
+	// Operator is one of the PostureOperator constants.
+	Operator string
+	// Values holds the single value compared with by comparison operators, the values of the
+	// set for [PostureOperatorIn] and [PostureOperatorNotIn], and is empty for
+	// [PostureOperatorIsSet] and [PostureOperatorNotSet]. String values are unquoted.
+	Values []string
+}
+
+const (
+	PostureOperatorEqual          = "=="
+	PostureOperatorNotEqual       = "!="
+	PostureOperatorLess           = "<"
+	PostureOperatorLessOrEqual    = "<="
+	PostureOperatorGreater        = ">"
+	PostureOperatorGreaterOrEqual = ">="
+	PostureOperatorIn             = "IN"
+	PostureOperatorNotIn          = "NOT IN"
+	PostureOperatorIsSet          = "IS SET"
+	PostureOperatorNotSet         = "NOT SET"
+)
+
+// postureOperators are the operators of posture conditions, ordered so that no operator is
+// matched before a longer one that it is a prefix of.
+var postureOperators = []string{
+	PostureOperatorIsSet, PostureOperatorNotSet, PostureOperatorNotIn, PostureOperatorIn,
+	PostureOperatorEqual, PostureOperatorNotEqual, PostureOperatorLessOrEqual,
+	PostureOperatorGreaterOrEqual, PostureOperatorLess, PostureOperatorGreater,
+}
+
+var (
+	postureAttributePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*:[A-Za-z0-9_.]+$`)
+	postureNamePattern      = regexp.MustCompile(`^posture:[A-Za-z0-9_-]+$`)
+)
+
+// Parse parses the condition, reporting an error if it is not a valid posture expression.
+func (c PostureCondition) Parse() (PostureExpression, error) {
+	attribute, rest, _ := strings.Cut(strings.TrimSpace(string(c)), " ")
+	if !postureAttributePattern.MatchString(attribute) {
+		return PostureExpression{}, fmt.Errorf("invalid posture condition %q: invalid attribute %q", c, attribute)
+	}
+	rest = strings.TrimSpace(rest)
+
+	for _, op := range postureOperators {
+		operand, ok := strings.CutPrefix(rest, op)
+		if !ok {
+			continue
+		}
+		operand = strings.TrimSpace(operand)
+		expr := PostureExpression{Attribute: attribute, Operator: op}
+		var err error
+		switch op {
+		case PostureOperatorIsSet, PostureOperatorNotSet:
+			if operand != "" {
+				err = fmt.Errorf("%s takes no value", op)
+			}
+		case PostureOperatorIn, PostureOperatorNotIn:
+			expr.Values, err = parsePostureSet(operand)
+		default:
+			var value string
+			value, err = parsePostureValue(operand)
+			expr.Values = []string{value}
+		}
+		if err != nil {
+			return PostureExpression{}, fmt.Errorf("invalid posture condition %q: %w", c, err)
+		}
+		return expr, nil
+	}
+	return PostureExpression{}, fmt.Errorf("invalid posture condition %q: missing or unknown operator", c)
+}
+
+func parsePostureSet(s string) ([]string, error) {
+	inner, ok := strings.CutPrefix(s, "[")
+	if inner, ok = strings.CutSuffix(inner, "]"); !ok || strings.TrimSpace(inner) == "" {
+		return nil, errors.New("expected a non-empty list of values in brackets")
+	}
+	var values []string
+	for _, v := range strings.Split(inner, ",") {
+		value, err := parsePostureValue(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// parsePostureValue parses a single value, which is either quoted with single quotes, or a bare
+// number or boolean.
+func parsePostureValue(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		if strings.Contains(s[1:len(s)-1], "'") {
+			return "", fmt.Errorf("invalid value %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	}
+	if s == "" || strings.ContainsAny(s, " '[],") {
+		return "", fmt.Errorf("invalid value %q, strings must be quoted with single quotes", s)
+	}
+	return s, nil
+}
+
+// String formats the expression as a [PostureCondition].
+func (e PostureExpression) String() string {
+	switch e.Operator {
+	case PostureOperatorIsSet, PostureOperatorNotSet:
+		return e.Attribute + " " + e.Operator
+	case PostureOperatorIn, PostureOperatorNotIn:
+		quoted := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			quoted[i] = "'" + v + "'"
+		}
+		return fmt.Sprintf("%s %s [%s]", e.Attribute, e.Operator, strings.Join(quoted, ", "))
+	default:
+		var value string
+		if len(e.Values) > 0 {
+			value = e.Values[0]
+		}
+		return fmt.Sprintf("%s %s '%s'", e.Attribute, e.Operator, value)
+	}
+}
+
+// normalize returns the posture with its conditions trimmed, sorted and deduplicated, since
+// their order has no meaning.
+func (p ACLPosture) normalize() ACLPosture {
+	normalized := normalizeEach(p, func(c PostureCondition) PostureCondition {
+		return PostureCondition(strings.TrimSpace(string(c)))
+	})
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// ValidatePostures checks that the names of the postures of acl are of the form
+// "posture:name", that their conditions are valid posture expressions, and that the postures
+// referred to by DefaultSourcePosture and the SourcePosture of rules and grants are defined. It
+// returns an error for every problem found, joined using [errors.Join].
+func (acl *ACL) ValidatePostures() error {
+	var errs []error
+	for _, name := range sortedKeys(acl.Postures) {
+		if !postureNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("postures[%q]: posture names must be of the form posture:name", name))
+		}
+		for i, condition := range acl.Postures[name] {
+			if _, err := condition.Parse(); err != nil {
+				errs = append(errs, fmt.Errorf("postures[%q][%d]: %w", name, i, err))
+			}
+		}
+	}
+
+	checkRefs := func(path string, names []string) {
+		for i, name := range names {
+			if _, ok := acl.Postures[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s[%d]: posture %q is not defined", path, i, name))
+			}
+		}
+	}
+	checkRefs("defaultSrcPosture", acl.DefaultSourcePosture)
+	for i, rule := range acl.ACLs {
+		checkRefs(fmt.Sprintf("acls[%d].srcPosture", i), rule.SourcePosture)
+	}
+	for i, grant := range acl.Grants {
+		checkRefs(fmt.Sprintf("grants[%d].srcPosture", i), grant.SourcePosture)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestPostureCondition_Parse(t *testing.T) {
+	t.Parallel()
+
+	for condition, expected := range map[tsclient.PostureCondition]tsclient.PostureExpression{
+		"node:os IN ['macos', 'linux']":    {Attribute: "node:os", Operator: "IN", Values: []string{"macos", "linux"}},
+		"node:os NOT IN ['windows']":       {Attribute: "node:os", Operator: "NOT IN", Values: []string{"windows"}},
+		"node:tsVersion >= '1.60'":         {Attribute: "node:tsVersion", Operator: ">=", Values: []string{"1.60"}},
+		"falcon:ztaScore > 70":             {Attribute: "falcon:ztaScore", Operator: ">", Values: []string{"70"}},
+		"node:tsReleaseTrack == 'stable'":  {Attribute: "node:tsReleaseTrack", Operator: "==", Values: []string{"stable"}},
+		" custom:managed IS SET ":          {Attribute: "custom:managed", Operator: "IS SET"},
+		"intune:complianceState NOT SET":   {Attribute: "intune:complianceState", Operator: "NOT SET"},
+		"node:tsAutoUpdate != false":       {Attribute: "node:tsAutoUpdate", Operator: "!=", Values: []string{"false"}},
+		"custom:tier <= '3'":               {Attribute: "custom:tier", Operator: "<=", Values: []string{"3"}},
+		"node:os IN ['macos','linux' ]":    {Attribute: "node:os", Operator: "IN", Values: []string{"macos", "linux"}},
+		"node:tsVersion < '1.60.1'":        {Attribute: "node:tsVersion", Operator: "<", Values: []string{"1.60.1"}},
+		"node:tsStateEncrypted == true":    {Attribute: "node:tsStateEncrypted", Operator: "==", Values: []string{"true"}},
+		"custom:location != 'home office'": {Attribute: "custom:location", Operator: "!=", Values: []string{"home office"}},
+	} {
+		expr, err := condition.Parse()
+		require.NoError(t, err, condition)
+		assert.Equal(t, expected, expr, condition)
+	}
+
+	for condition, message := range map[tsclient.PostureCondition]string{
+		"os == 'macos'":              `invalid posture condition "os == 'macos'": invalid attribute "os"`,
+		"node:os = 'macos'":          `invalid posture condition "node:os = 'macos'": missing or unknown operator`,
+		"node:os == macos linux":     `invalid posture condition "node:os == macos linux": invalid value "macos linux", strings must be quoted with single quotes`,
+		"node:os IN 'macos'":         `invalid posture condition "node:os IN 'macos'": expected a non-empty list of values in brackets`,
+		"node:os IN []":              `invalid posture condition "node:os IN []": expected a non-empty list of values in brackets`,
+		"custom:managed IS SET true": `invalid posture condition "custom:managed IS SET true": IS SET takes no value`,
+	} {
+		_, err := condition.Parse()
+		assert.EqualError(t, err, message, condition)
+	}
+
+	expr, err := tsclient.PostureCondition("node:os IN ['macos','linux']").Parse()
+	require.NoError(t, err)
+	assert.Equal(t, "node:os IN ['macos', 'linux']", expr.String())
+}
+
+func TestACL_ValidatePostures(t *testing.T) {
+	t.Parallel()
+
+	var acl tsclient.ACL
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"postures": {
+			"posture:latestMac": ["node:os == 'macos'", "node:tsVersion >= '1.60'"],
+			"stable": ["node:tsReleaseTrack = 'stable'"]
+		},
+		"defaultSrcPosture": ["posture:latestMac"],
+		"grants": [{"src": ["*"], "dst": ["*"], "ip": ["*"], "srcPosture": ["posture:missing"]}]
+	}`), &acl))
+	assert.Equal(t, tsclient.ACLPosture{"node:os == 'macos'", "node:tsVersion >= '1.60'"}, acl.Postures["posture:latestMac"])
+
+	assert.EqualError(t, acl.ValidatePostures(), `postures["stable"]: posture names must be of the form posture:name
+postures["stable"][0]: invalid posture condition "node:tsReleaseTrack = 'stable'": missing or unknown operator
+grants[0].srcPosture[0]: posture "posture:missing" is not defined`)
+
+	var findings []string
+	for _, f := range acl.Lint() {
+		if f.Rule == "posture-syntax" {
+			findings = append(findings, f.String())
+		}
+	}
+	assert.Equal(t, []string{
+		`postures["stable"]: posture names must be of the form posture:name (posture-syntax)`,
+		`postures["stable"][0]: invalid posture condition "node:tsReleaseTrack = 'stable'": missing or unknown operator (posture-syntax)`,
+		`grants[0].srcPosture[0]: posture "posture:missing" is not defined (posture-syntax)`,
+	}, findings)
+}
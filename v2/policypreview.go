@@ -0,0 +1,202 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// ACLPreviewType is the type of subject of an [ACLPreview].
+type ACLPreviewType string
+
+const (
+	// ACLPreviewUser previews the destinations that a user can access.
+	ACLPreviewUser ACLPreviewType = "user"
+	// ACLPreviewIPPort previews the users that can access an IP address and port, such as
+	// "100.64.0.1:22".
+	ACLPreviewIPPort ACLPreviewType = "ipport"
+)
+
+// ACLPreview describes the rules of a policy file that match a user or destination, as returned
+// by [PolicyFileResource.Preview].
+type ACLPreview struct {
+	Matches    []ACLPreviewMatch `json:"matches"`
+	Type       ACLPreviewType    `json:"type"`
+	PreviewFor string            `json:"previewFor"`
+}
+
+// ACLPreviewMatch is a rule of a policy file that matches the subject of an [ACLPreview].
+type ACLPreviewMatch struct {
+	// Users are the sources of the rule.
+	Users []string `json:"users"`
+	// Ports are the destinations of the rule, such as "tag:web:443".
+	Ports []string `json:"ports"`
+	// LineNumber is the line of the rule within the policy file.
+	LineNumber int `json:"lineNumber"`
+}
+
+// Preview previews the rules of acl that match previewFor, without setting acl. acl can either be
+// an [ACL], or a HuJSON string. previewFor is a login name for [ACLPreviewUser], or an IP address
+// and port for [ACLPreviewIPPort].
+func (pr *PolicyFileResource) Preview(ctx context.Context, acl any, previewType ACLPreviewType, previewFor string, opts ...WriteOption) (*ACLPreview, error) {
+	reqOpts := []requestOption{
+		requestBody(acl),
+		requestQuery("type", string(previewType)),
+		requestQuery("previewFor", previewFor),
+		writeOptions(opts),
+	}
+	switch v := acl.(type) {
+	case ACL:
+	case string:
+		reqOpts = append(reqOpts, requestContentType("application/hujson"))
+	default:
+		return nil, fmt.Errorf("expected ACL content as a string or as ACL struct; got %T", v)
+	}
+
+	req, err := pr.buildRequest(ctx, http.MethodPost, pr.buildTailnetURL("acl", "preview"), reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return body[ACLPreview](pr, req)
+}
+
+// PolicyImpact describes how a candidate policy file changes access compared with the policy
+// file that is currently set, as reported by [PolicyFileResource.Impact]. Only users and
+// destinations whose access changes are included.
+type PolicyImpact struct {
+	// Users describes, for each user, the destinations that the user gains and loses access to.
+	Users []PolicyAccessChange
+	// Destinations describes, for each IP address and port of a tagged device, the sources that
+	// gain and lose access to it.
+	Destinations []PolicyAccessChange
+}
+
+// Empty reports whether the candidate policy file does not change access.
+func (i PolicyImpact) Empty() bool {
+	return len(i.Users) == 0 && len(i.Destinations) == 0
+}
+
+// PolicyAccessChange describes how the access of a user or to a destination changes.
+type PolicyAccessChange struct {
+	// Subject is the login name of the user, or the IP address and port of the destination.
+	Subject string
+	// Device is the name of the device of the destination, and is empty for users.
+	Device string
+	// Added and Removed are the destinations a user gains and loses access to, or the sources
+	// that gain and lose access to a destination, sorted.
+	Added   []string
+	Removed []string
+}
+
+// PolicyImpactOptions customizes [PolicyFileResource.Impact].
+type PolicyImpactOptions struct {
+	// Ports are the ports of the tagged devices that are previewed. Defaults to 22, 80 and 443.
+	Ports []string
+	// Batch configures how previews are requested; see [Batch].
+	Batch BatchOptions
+}
+
+// impactSubject is a user or destination whose access is compared by [PolicyFileResource.Impact].
+type impactSubject struct {
+	previewType ACLPreviewType
+	previewFor  string
+	device      string
+	change      *PolicyAccessChange
+}
+
+// Impact reports how setting candidate, which can either be an [ACL] or a HuJSON string, would
+// change access for every user of the tailnet, and to every tagged device, compared with the
+// policy file that is currently set. It is a dry run: no policy file is set. Access is compared
+// using [PolicyFileResource.Preview], calling it for the current and the candidate policy file for
+// every user, and for every port of every tagged device. Previews are requested concurrently as
+// configured by opts.Batch.
+func (pr *PolicyFileResource) Impact(ctx context.Context, candidate any, opts PolicyImpactOptions) (*PolicyImpact, error) {
+	ports := opts.Ports
+	if len(ports) == 0 {
+		ports = []string{"22", "80", "443"}
+	}
+
+	live, err := pr.Raw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := pr.Users().List(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := pr.Devices().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []*impactSubject
+	for _, user := range users {
+		subjects = append(subjects, &impactSubject{previewType: ACLPreviewUser, previewFor: user.LoginName})
+	}
+	for _, device := range devices {
+		if len(device.Tags) == 0 || len(device.Addresses) == 0 {
+			continue
+		}
+		for _, port := range ports {
+			subjects = append(subjects, &impactSubject{previewType: ACLPreviewIPPort, previewFor: device.Addresses[0] + ":" + port, device: device.Name})
+		}
+	}
+
+	err = Batch(ctx, subjects, opts.Batch, func(ctx context.Context, subject *impactSubject) error {
+		matched := func(m ACLPreviewMatch) []string { return m.Ports }
+		if subject.previewType == ACLPreviewIPPort {
+			matched = func(m ACLPreviewMatch) []string { return m.Users }
+		}
+		var access [2][]string
+		for i, acl := range []any{live.HuJSON, candidate} {
+			preview, err := pr.Preview(ctx, acl, subject.previewType, subject.previewFor)
+			if err != nil {
+				return fmt.Errorf("previewing %s: %w", subject.previewFor, err)
+			}
+			for _, match := range preview.Matches {
+				access[i] = append(access[i], matched(match)...)
+			}
+		}
+		added, removed := diffSets(access[0], access[1])
+		if len(added) > 0 || len(removed) > 0 {
+			subject.change = &PolicyAccessChange{Subject: subject.previewFor, Device: subject.device, Added: added, Removed: removed}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &PolicyImpact{}
+	for _, subject := range subjects {
+		switch {
+		case subject.change == nil:
+		case subject.previewType == ACLPreviewUser:
+			impact.Users = append(impact.Users, *subject.change)
+		default:
+			impact.Destinations = append(impact.Destinations, *subject.change)
+		}
+	}
+	return impact, nil
+}
+
+// diffSets returns the sorted values that are in b but not a, and in a but not b.
+func diffSets(a, b []string) (added, removed []string) {
+	for _, v := range b {
+		if !slices.Contains(a, v) && !slices.Contains(added, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range a {
+		if !slices.Contains(b, v) && !slices.Contains(removed, v) {
+			removed = append(removed, v)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
@@ -0,0 +1,90 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+)
+
+func TestClient_PreviewACL(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	expected := &tsclient.ACLPreview{
+		Matches:    []tsclient.ACLPreviewMatch{{Users: []string{"*"}, Ports: []string{"*:*"}, LineNumber: 19}},
+		Type:       tsclient.ACLPreviewUser,
+		PreviewFor: "alice@example.com",
+	}
+	server.ResponseBody = expected
+
+	preview, err := client.PolicyFile().Preview(context.Background(), `{"acls": []}`, tsclient.ACLPreviewUser, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, expected, preview)
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/tailnet/example.com/acl/preview", server.Path)
+	assert.Equal(t, url.Values{"type": {"user"}, "previewFor": {"alice@example.com"}}, server.Query)
+	assert.Equal(t, "application/hujson", server.Header.Get("Content-Type"))
+	assert.Equal(t, `{"acls": []}`, server.Body.String())
+}
+
+func TestClient_PolicyImpact(t *testing.T) {
+	t.Parallel()
+
+	const live = `{"acls": [{"action": "accept", "src": ["*"], "dst": ["tag:web:443"]}]} // live`
+	client := apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch r.URL.Path {
+		case "/api/v2/tailnet/example.com/acl":
+			_, _ = w.Write([]byte(live))
+			return
+		case "/api/v2/tailnet/example.com/users":
+			resp = map[string][]tsclient.User{"users": {{LoginName: "alice@example.com"}, {LoginName: "bob@example.com"}}}
+		case "/api/v2/tailnet/example.com/devices":
+			resp = map[string][]tsclient.Device{"devices": {
+				{Name: "web", Tags: []string{"tag:web"}, Addresses: []string{"100.64.0.1"}},
+				{Name: "laptop", Addresses: []string{"100.64.0.2"}},
+			}}
+		case "/api/v2/tailnet/example.com/acl/preview":
+			body, _ := io.ReadAll(r.Body)
+			candidate := !strings.Contains(string(body), "// live")
+			previewFor := r.URL.Query().Get("previewFor")
+			var matches []tsclient.ACLPreviewMatch
+			switch {
+			case previewFor == "100.64.0.1:443" && !candidate:
+				matches = []tsclient.ACLPreviewMatch{{Users: []string{"*"}, Ports: []string{"tag:web:443"}}}
+			case previewFor == "100.64.0.1:443" && candidate:
+				matches = []tsclient.ACLPreviewMatch{{Users: []string{"group:eng"}, Ports: []string{"tag:web:443"}}}
+			case previewFor == "alice@example.com" || (previewFor == "bob@example.com" && !candidate):
+				matches = []tsclient.ACLPreviewMatch{{Users: []string{"*"}, Ports: []string{"tag:web:443"}}}
+			}
+			resp = tsclient.ACLPreview{Matches: matches}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+
+	candidate := tsclient.ACL{ACLs: []tsclient.ACLEntry{{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:web:443"}}}}
+	impact, err := client.PolicyFile().Impact(context.Background(), candidate, tsclient.PolicyImpactOptions{Ports: []string{"22", "443"}, Batch: tsclient.BatchOptions{Concurrency: 2}})
+	require.NoError(t, err)
+	assert.False(t, impact.Empty())
+	assert.Equal(t, &tsclient.PolicyImpact{
+		Users: []tsclient.PolicyAccessChange{
+			{Subject: "bob@example.com", Removed: []string{"tag:web:443"}},
+		},
+		Destinations: []tsclient.PolicyAccessChange{
+			{Subject: "100.64.0.1:443", Device: "web", Added: []string{"group:eng"}, Removed: []string{"*"}},
+		},
+	}, impact)
+}
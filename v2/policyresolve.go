@@ -0,0 +1,155 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// PolicyResolver expands the selectors of a policy file into the users and devices of the
+// tailnet that they currently match, such as to report who can reach a device. Create one using
+// [NewPolicyResolver], or [PolicyFileResource.Resolver] to use the current state of the tailnet.
+type PolicyResolver struct {
+	acl     *ACL
+	users   []User
+	devices []Device
+}
+
+// SelectorMatch holds the users and devices matched by a selector, as resolved by
+// [PolicyResolver.Resolve]. The devices of users are their untagged devices.
+type SelectorMatch struct {
+	Users   []User
+	Devices []Device
+}
+
+// NewPolicyResolver returns a [PolicyResolver] that resolves selectors using the definitions of
+// acl and the given users and devices of the tailnet.
+func NewPolicyResolver(acl *ACL, users []User, devices []Device) *PolicyResolver {
+	return &PolicyResolver{acl: acl, users: users, devices: devices}
+}
+
+// Resolver gets the policy file, users and devices of the tailnet, and returns a
+// [PolicyResolver] using them.
+func (pr *PolicyFileResource) Resolver(ctx context.Context) (*PolicyResolver, error) {
+	acl, err := pr.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := pr.Users().List(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := pr.Devices().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewPolicyResolver(acl, users, devices), nil
+}
+
+// Resolve returns the users and devices currently matched by selector, which may be "*", a
+// group, tag, autogroup, IP set, host alias, login name, IP address or CIDR prefix. Autogroups
+// whose meaning depends on the rule they are used in, such as autogroup:self, cannot be
+// resolved and return an error, as do selectors referring to undefined groups, IP sets or hosts.
+func (r *PolicyResolver) Resolve(selector string) (SelectorMatch, error) {
+	switch {
+	case selector == "*":
+		return SelectorMatch{Users: r.users, Devices: r.devices}, nil
+	case strings.HasPrefix(selector, GroupPrefix):
+		members, ok := r.acl.Groups[selector]
+		if !ok {
+			return SelectorMatch{}, fmt.Errorf("group %q is not defined", selector)
+		}
+		return r.matchUsers(func(u *User) bool { return slices.Contains(members, u.LoginName) }), nil
+	case strings.HasPrefix(selector, TagPrefix):
+		return r.matchDevices(func(d *Device) bool { return slices.Contains(d.Tags, selector) }), nil
+	case strings.HasPrefix(selector, AutogroupPrefix):
+		return r.resolveAutogroup(selector)
+	case strings.HasPrefix(selector, IPSetPrefix):
+		entries, ok := r.acl.IPSets[selector]
+		if !ok {
+			return SelectorMatch{}, fmt.Errorf("IP set %q is not defined", selector)
+		}
+		return r.matchAddresses(selector, entries...)
+	case strings.Contains(selector, "@"):
+		return r.matchUsers(func(u *User) bool { return u.LoginName == selector }), nil
+	case isHostAlias(selector):
+		address, ok := r.acl.Hosts[selector]
+		if !ok {
+			return SelectorMatch{}, fmt.Errorf("host %q is not defined", selector)
+		}
+		return r.matchAddresses(selector, address)
+	default:
+		return r.matchAddresses(selector, selector)
+	}
+}
+
+func (r *PolicyResolver) resolveAutogroup(selector string) (SelectorMatch, error) {
+	switch selector {
+	case AutogroupMember, AutogroupMembers:
+		return r.matchUsers(func(u *User) bool { return u.Type == UserTypeMember }), nil
+	case AutogroupShared:
+		return r.matchUsers(func(u *User) bool { return u.Type == UserTypeShared }), nil
+	case AutogroupTagged:
+		return r.matchDevices(func(d *Device) bool { return len(d.Tags) > 0 }), nil
+	case AutogroupOwner, AutogroupAdmin, AutogroupMemberAdmin, AutogroupNetworkAdmin, AutogroupITAdmin, AutogroupBillingAdmin, AutogroupAuditor:
+		role := UserRole(strings.TrimPrefix(selector, AutogroupPrefix))
+		return r.matchUsers(func(u *User) bool { return u.Role == role }), nil
+	default:
+		return SelectorMatch{}, fmt.Errorf("%s cannot be resolved outside of a rule", selector)
+	}
+}
+
+// matchUsers matches the users for which match returns true, and their untagged devices.
+func (r *PolicyResolver) matchUsers(match func(*User) bool) SelectorMatch {
+	var m SelectorMatch
+	for _, u := range r.users {
+		if match(&u) {
+			m.Users = append(m.Users, u)
+		}
+	}
+	for _, d := range r.devices {
+		if len(d.Tags) == 0 && slices.ContainsFunc(m.Users, func(u User) bool { return u.LoginName == d.User }) {
+			m.Devices = append(m.Devices, d)
+		}
+	}
+	return m
+}
+
+// matchDevices matches the devices for which match returns true.
+func (r *PolicyResolver) matchDevices(match func(*Device) bool) SelectorMatch {
+	var m SelectorMatch
+	for _, d := range r.devices {
+		if match(&d) {
+			m.Devices = append(m.Devices, d)
+		}
+	}
+	return m
+}
+
+// matchAddresses matches the devices with a Tailscale IP address within any of the addresses,
+// which are IP addresses or CIDR prefixes.
+func (r *PolicyResolver) matchAddresses(selector string, addresses ...string) (SelectorMatch, error) {
+	var prefixes []netip.Prefix
+	for _, address := range addresses {
+		prefix, err := netip.ParsePrefix(address)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(address)
+			if addrErr != nil {
+				return SelectorMatch{}, fmt.Errorf("cannot resolve %q: invalid address %q", selector, address)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return r.matchDevices(func(d *Device) bool {
+		ips, _ := d.IPAddresses()
+		return slices.ContainsFunc(ips, func(ip netip.Addr) bool {
+			return slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Contains(ip) })
+		})
+	}), nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestPolicyResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	acl := &tsclient.ACL{
+		Groups: map[string][]string{"group:eng": {"alice@example.com"}},
+		Hosts:  map[string]string{"db": "100.64.0.3", "office": "100.64.0.0/30"},
+		IPSets: map[string][]string{"ipset:servers": {"100.64.0.3", "100.64.0.4"}},
+	}
+	users := []tsclient.User{
+		{LoginName: "alice@example.com", Type: tsclient.UserTypeMember, Role: tsclient.UserRoleAdmin},
+		{LoginName: "bob@example.com", Type: tsclient.UserTypeMember, Role: tsclient.UserRoleMember},
+		{LoginName: "carol@other.com", Type: tsclient.UserTypeShared, Role: tsclient.UserRoleMember},
+	}
+	devices := []tsclient.Device{
+		{ID: "1", User: "alice@example.com", Addresses: []string{"100.64.0.1"}},
+		{ID: "2", User: "bob@example.com", Addresses: []string{"100.64.0.2", "fd7a:115c:a1e0::2"}},
+		{ID: "3", User: "alice@example.com", Tags: []string{"tag:db"}, Addresses: []string{"100.64.0.3"}},
+		{ID: "4", User: "bob@example.com", Tags: []string{"tag:web"}, Addresses: []string{"100.64.0.4"}},
+	}
+	resolver := tsclient.NewPolicyResolver(acl, users, devices)
+
+	for selector, expected := range map[string]struct {
+		users   []string
+		devices []string
+	}{
+		"*":                   {[]string{"alice@example.com", "bob@example.com", "carol@other.com"}, []string{"1", "2", "3", "4"}},
+		"group:eng":           {[]string{"alice@example.com"}, []string{"1"}},
+		"tag:db":              {nil, []string{"3"}},
+		"autogroup:member":    {[]string{"alice@example.com", "bob@example.com"}, []string{"1", "2"}},
+		"autogroup:shared":    {[]string{"carol@other.com"}, nil},
+		"autogroup:admin":     {[]string{"alice@example.com"}, []string{"1"}},
+		"autogroup:tagged":    {nil, []string{"3", "4"}},
+		"bob@example.com":     {[]string{"bob@example.com"}, []string{"2"}},
+		"db":                  {nil, []string{"3"}},
+		"office":              {nil, []string{"1", "2", "3"}},
+		"ipset:servers":       {nil, []string{"3", "4"}},
+		"fd7a:115c:a1e0::/48": {nil, []string{"2"}},
+	} {
+		match, err := resolver.Resolve(selector)
+		require.NoError(t, err, selector)
+		var users, devices []string
+		for _, u := range match.Users {
+			users = append(users, u.LoginName)
+		}
+		for _, d := range match.Devices {
+			devices = append(devices, d.ID)
+		}
+		assert.Equal(t, expected.users, users, selector)
+		assert.Equal(t, expected.devices, devices, selector)
+	}
+
+	_, err := resolver.Resolve("group:ops")
+	assert.EqualError(t, err, `group "group:ops" is not defined`)
+	_, err = resolver.Resolve("autogroup:self")
+	assert.EqualError(t, err, "autogroup:self cannot be resolved outside of a rule")
+	_, err = resolver.Resolve("cache")
+	assert.EqualError(t, err, `host "cache" is not defined`)
+}
+
+func TestClient_PolicyResolver(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]any{
+		"/api/v2/tailnet/example.com/acl":     tsclient.ACL{Groups: map[string][]string{"group:eng": {"alice@example.com"}}},
+		"/api/v2/tailnet/example.com/users":   map[string][]tsclient.User{"users": {{LoginName: "alice@example.com"}}},
+		"/api/v2/tailnet/example.com/devices": map[string][]tsclient.Device{"devices": {{ID: "1", User: "alice@example.com"}}},
+	}
+
+	resolver, err := client.PolicyFile().Resolver(context.Background())
+	require.NoError(t, err)
+	match, err := resolver.Resolve("group:eng")
+	require.NoError(t, err)
+	assert.Len(t, match.Users, 1)
+	assert.Len(t, match.Devices, 1)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PolicySnapshot is the policy file of a tailnet as it was before a new one was set using
+// [PolicyFileResource.Apply], which can be restored using [PolicySnapshot.Restore].
+type PolicySnapshot struct {
+	// Previous is the policy file that was replaced.
+	Previous RawACL
+	// AppliedETag is the ETag of the policy file that replaced Previous, if returned by the API.
+	AppliedETag string
+
+	pr *PolicyFileResource
+}
+
+// Apply sets the policy file of the tailnet to acl, which can either be an [ACL] or a HuJSON
+// string, after taking a snapshot of the current one. If check is not nil, it is called after
+// acl is set, and if it returns an error the snapshot is restored automatically and the returned
+// error wraps the error of check. The returned snapshot can also be restored on demand.
+//
+// The policy file is only set if it is not modified between taking the snapshot and setting acl,
+// as checked using its ETag; otherwise, the returned error satisfies [IsPreconditionFailed].
+func (pr *PolicyFileResource) Apply(ctx context.Context, acl any, check func(ctx context.Context) error, opts ...WriteOption) (*PolicySnapshot, error) {
+	raw, err := pr.Raw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	etag, err := pr.set(ctx, acl, raw.ETag, opts...)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &PolicySnapshot{Previous: *raw, AppliedETag: etag, pr: pr}
+
+	if check == nil {
+		return snapshot, nil
+	}
+	if err := check(ctx); err != nil {
+		if restoreErr := snapshot.Restore(ctx, opts...); restoreErr != nil {
+			return snapshot, errors.Join(fmt.Errorf("policy file check failed: %w", err), fmt.Errorf("restoring policy file: %w", restoreErr))
+		}
+		return snapshot, fmt.Errorf("policy file check failed, restored previous policy file: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Restore sets the policy file of the tailnet back to the one that was replaced by
+// [PolicyFileResource.Apply], preserving its comments and formatting. The snapshot is only
+// restored if the policy file has not been modified since it was applied; otherwise, the returned
+// error satisfies [IsPreconditionFailed].
+//
+// If the API did not return the ETag of the applied policy file, that cannot be checked, and
+// Restore returns [ErrPolicyETagRequired] unless [WithForce] is passed to overwrite the policy file
+// unconditionally.
+func (s *PolicySnapshot) Restore(ctx context.Context, opts ...WriteOption) error {
+	if s.AppliedETag == "" {
+		rp := &requestParams{ctx: ctx}
+		writeOptions(opts)(rp)
+		if !forced(rp.ctx) {
+			return ErrPolicyETagRequired
+		}
+	}
+	_, err := s.pr.set(ctx, s.Previous.HuJSON, s.AppliedETag, opts...)
+	return err
+}
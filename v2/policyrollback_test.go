@@ -0,0 +1,119 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+)
+
+// policyServer serves a policy file, only accepting updates whose If-Match header matches its ETag.
+type policyServer struct {
+	mu      sync.Mutex
+	policy  string
+	version int
+	// omitWriteETag causes updates to be answered without an ETag.
+	omitWriteETag bool
+}
+
+func (s *policyServer) etag() string {
+	return fmt.Sprintf("v%d", s.version)
+}
+
+func (s *policyServer) current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.policy
+}
+
+func (s *policyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Etag", s.etag())
+		_, _ = w.Write([]byte(s.policy))
+	case http.MethodPost:
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != fmt.Sprintf("%q", s.etag()) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte(`{"message":"precondition failed"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.policy = string(body)
+		s.version++
+		if !s.omitWriteETag {
+			w.Header().Set("Etag", s.etag())
+		}
+		_, _ = w.Write(body)
+	}
+}
+
+func newPolicyServer(t *testing.T, policy string) (*tsclient.Client, *policyServer) {
+	t.Helper()
+
+	ps := &policyServer{policy: policy}
+	return apitest.NewClient(t, ps), ps
+}
+
+func TestClient_ApplyACL(t *testing.T) {
+	t.Parallel()
+
+	client, server := newPolicyServer(t, `{"groups": {}} // original`)
+
+	snapshot, err := client.PolicyFile().Apply(context.Background(), `{"groups": {"group:eng": []}}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"groups": {"group:eng": []}}`, server.current())
+	assert.Equal(t, "v0", snapshot.Previous.ETag)
+	assert.Equal(t, "v1", snapshot.AppliedETag)
+
+	require.NoError(t, snapshot.Restore(context.Background()))
+	assert.Equal(t, `{"groups": {}} // original`, server.current())
+
+	// Restoring again fails, as the policy file has changed since it was applied.
+	assert.True(t, tsclient.IsPreconditionFailed(snapshot.Restore(context.Background())))
+}
+
+func TestClient_ApplyACL_CheckFailed(t *testing.T) {
+	t.Parallel()
+
+	client, server := newPolicyServer(t, `{"groups": {}} // original`)
+
+	var checked string
+	_, err := client.PolicyFile().Apply(context.Background(), `{"groups": {"group:eng": []}}`, func(ctx context.Context) error {
+		checked = server.current()
+		return errors.New("devices unreachable")
+	})
+	assert.EqualError(t, err, "policy file check failed, restored previous policy file: devices unreachable")
+	assert.Equal(t, `{"groups": {"group:eng": []}}`, checked)
+	assert.Equal(t, `{"groups": {}} // original`, server.current())
+}
+
+func TestClient_ApplyACL_RestoreWithoutAppliedETag(t *testing.T) {
+	t.Parallel()
+
+	client, server := newPolicyServer(t, `{"groups": {}} // original`)
+	server.omitWriteETag = true
+
+	snapshot, err := client.PolicyFile().Apply(context.Background(), `{"groups": {"group:eng": []}}`, nil)
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.AppliedETag)
+
+	assert.ErrorIs(t, snapshot.Restore(context.Background()), tsclient.ErrPolicyETagRequired)
+	assert.Equal(t, `{"groups": {"group:eng": []}}`, server.current())
+
+	require.NoError(t, snapshot.Restore(context.Background(), tsclient.WithForce()))
+	assert.Equal(t, `{"groups": {}} // original`, server.current())
+}
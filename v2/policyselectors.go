@@ -0,0 +1,164 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Prefixes of the selectors used in policy files to refer to devices and users.
+const (
+	TagPrefix       = "tag:"
+	GroupPrefix     = "group:"
+	AutogroupPrefix = "autogroup:"
+	IPSetPrefix     = "ipset:"
+	ServicePrefix   = "svc:"
+)
+
+// Autogroups that can be used as selectors in policy files. More details:
+// https://tailscale.com/kb/1337/acl-syntax#autogroups
+const (
+	AutogroupMember       = "autogroup:member"
+	AutogroupMembers      = "autogroup:members" // Legacy spelling of autogroup:member.
+	AutogroupTagged       = "autogroup:tagged"
+	AutogroupSelf         = "autogroup:self"
+	AutogroupInternet     = "autogroup:internet"
+	AutogroupShared       = "autogroup:shared"
+	AutogroupNonroot      = "autogroup:nonroot"
+	AutogroupDangerAll    = "autogroup:danger-all"
+	AutogroupOwner        = "autogroup:owner"
+	AutogroupAdmin        = "autogroup:admin"
+	AutogroupMemberAdmin  = "autogroup:member-admin"
+	AutogroupNetworkAdmin = "autogroup:network-admin"
+	AutogroupITAdmin      = "autogroup:it-admin"
+	AutogroupBillingAdmin = "autogroup:billing-admin"
+	AutogroupAuditor      = "autogroup:auditor"
+)
+
+// autogroups are the autogroups accepted by [ValidateSelector].
+var autogroups = []string{
+	AutogroupMember, AutogroupMembers, AutogroupTagged, AutogroupSelf, AutogroupInternet,
+	AutogroupShared, AutogroupNonroot, AutogroupDangerAll, AutogroupOwner, AutogroupAdmin,
+	AutogroupMemberAdmin, AutogroupNetworkAdmin, AutogroupITAdmin, AutogroupBillingAdmin,
+	AutogroupAuditor,
+}
+
+var selectorNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateSelector reports an error if s is not a syntactically valid selector of devices or
+// users, as used in the sources and destinations of policy file rules without ports. Valid
+// selectors are "*", tags, groups, known autogroups, IP sets, services, user login names, IP
+// addresses, CIDR prefixes and host aliases. Host aliases are not checked against the hosts of
+// a policy.
+func ValidateSelector(s string) error {
+	if s == "*" {
+		return nil
+	}
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return nil
+	}
+	if _, err := netip.ParseAddr(s); err == nil {
+		return nil
+	}
+	if strings.HasPrefix(s, AutogroupPrefix) {
+		if !slices.Contains(autogroups, s) {
+			return fmt.Errorf("invalid selector %q: unknown autogroup", s)
+		}
+		return nil
+	}
+	for _, prefix := range []string{TagPrefix, GroupPrefix, IPSetPrefix, ServicePrefix} {
+		if name, ok := strings.CutPrefix(s, prefix); ok {
+			if !selectorNamePattern.MatchString(name) {
+				return fmt.Errorf("invalid selector %q: invalid name %q after %q", s, name, prefix)
+			}
+			return nil
+		}
+	}
+	if user, domain, ok := strings.Cut(s, "@"); ok {
+		if user == "" || strings.ContainsAny(user+domain, " :") {
+			return fmt.Errorf("invalid selector %q: invalid login name", s)
+		}
+		return nil
+	}
+	if prefix, _, ok := strings.Cut(s, ":"); ok {
+		return fmt.Errorf("invalid selector %q: unknown prefix %q", s, prefix+":")
+	}
+	if !selectorNamePattern.MatchString(s) {
+		return fmt.Errorf("invalid selector %q", s)
+	}
+	return nil
+}
+
+// ValidateDestination reports an error if s is not a syntactically valid destination of an
+// [ACLEntry], which is a selector as accepted by [ValidateSelector] followed by a colon and
+// ports, such as "tag:web:80,443", "group:eng:*" or "[fd7a:115c:a1e0::1]:22".
+func ValidateDestination(s string) error {
+	selector, ports, ok := splitDestination(s)
+	if !ok {
+		return fmt.Errorf("invalid destination %q: missing ports", s)
+	}
+	if err := ValidateSelector(selector); err != nil {
+		return fmt.Errorf("invalid destination %q: %w", s, err)
+	}
+	if ports == "*" {
+		return nil
+	}
+	for _, r := range strings.Split(ports, ",") {
+		if !validPortRange(r) {
+			return fmt.Errorf("invalid destination %q: invalid ports %q", s, r)
+		}
+	}
+	return nil
+}
+
+// validPorts reports whether ports is "*" or a comma-separated list of ports and port ranges.
+func validPorts(ports string) bool {
+	if ports == "*" {
+		return true
+	}
+	for _, r := range strings.Split(ports, ",") {
+		if !validPortRange(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func validPortRange(r string) bool {
+	low, high, isRange := strings.Cut(r, "-")
+	if !isRange {
+		high = low
+	}
+	return validPort(low) && validPort(high)
+}
+
+// splitDestination splits the destination s into its selector and ports, removing the brackets
+// around IPv6 addresses.
+func splitDestination(s string) (selector, ports string, ok bool) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	selector, ports = s[:i], s[i+1:]
+	if strings.HasPrefix(selector, "[") && strings.HasSuffix(selector, "]") {
+		selector = selector[1 : len(selector)-1]
+	}
+	return selector, ports, true
+}
+
+// isHostAlias reports whether the selector s refers to a host alias defined in the hosts section
+// of a policy file, rather than being a special, prefixed or address selector.
+func isHostAlias(s string) bool {
+	return s != "*" && !strings.ContainsAny(s, ":@") && !looksLikeAddress(s)
+}
+
+func validPort(s string) bool {
+	n, err := strconv.ParseUint(s, 10, 16)
+	return err == nil && n > 0
+}
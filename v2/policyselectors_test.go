@@ -0,0 +1,96 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestValidateSelector(t *testing.T) {
+	t.Parallel()
+
+	for _, valid := range []string{
+		"*",
+		tsclient.AutogroupMember,
+		tsclient.AutogroupMembers,
+		tsclient.AutogroupInternet,
+		"tag:prod",
+		"group:eng-team",
+		"ipset:office",
+		"svc:web",
+		"alice@example.com",
+		"alice@github",
+		"100.64.0.1",
+		"10.0.0.0/8",
+		"fd7a:115c:a1e0::/48",
+		"my-host",
+	} {
+		assert.NoError(t, tsclient.ValidateSelector(valid), valid)
+	}
+
+	for invalid, message := range map[string]string{
+		"autogroup:memebers": `invalid selector "autogroup:memebers": unknown autogroup`,
+		"tags:prod":          `invalid selector "tags:prod": unknown prefix "tags:"`,
+		"tag:":               `invalid selector "tag:": invalid name "" after "tag:"`,
+		"group:eng team":     `invalid selector "group:eng team": invalid name "eng team" after "group:"`,
+		"@example.com":       `invalid selector "@example.com": invalid login name`,
+		"":                   `invalid selector ""`,
+	} {
+		assert.EqualError(t, tsclient.ValidateSelector(invalid), message, invalid)
+	}
+}
+
+func TestValidateDestination(t *testing.T) {
+	t.Parallel()
+
+	for _, valid := range []string{
+		"*:*",
+		"autogroup:self:*",
+		"tag:web:80,443",
+		"group:eng:1000-2000",
+		"10.0.0.0/8:22",
+		"[fd7a:115c:a1e0::1]:22",
+	} {
+		assert.NoError(t, tsclient.ValidateDestination(valid), valid)
+	}
+
+	for invalid, message := range map[string]string{
+		"tag:web":            `invalid destination "tag:web": invalid ports "web"`,
+		"my-host":            `invalid destination "my-host": missing ports`,
+		"tag:web:0":          `invalid destination "tag:web:0": invalid ports "0"`,
+		"tag:web:80-":        `invalid destination "tag:web:80-": invalid ports "80-"`,
+		"autogroup:selff:22": `invalid destination "autogroup:selff:22": invalid selector "autogroup:selff": unknown autogroup`,
+	} {
+		assert.EqualError(t, tsclient.ValidateDestination(invalid), message, invalid)
+	}
+}
+
+func TestACL_Lint_SelectorSyntax(t *testing.T) {
+	t.Parallel()
+
+	acl := &tsclient.ACL{
+		Groups:    map[string][]string{"group:eng": {"alice@example.com"}},
+		TagOwners: map[string][]string{"tag:web": {"group:eng"}, "tag:db": {"group:eng"}},
+		ACLs: []tsclient.ACLEntry{
+			{Action: "accept", Source: []string{"autogroup:member"}, Destination: []string{"autogroup:self:*"}},
+			{Action: "accept", Source: []string{"grup:eng"}, Destination: []string{"tag:web:80", "tag:db"}},
+		},
+		Grants: []tsclient.ACLGrant{
+			{Source: []string{"group:eng"}, Destination: []string{"autogroup:internt"}, IP: []string{"*"}},
+		},
+	}
+
+	var findings []string
+	for _, f := range acl.Lint() {
+		findings = append(findings, f.String())
+	}
+	assert.Equal(t, []string{
+		`acls[1].src[0]: invalid selector "grup:eng": unknown prefix "grup:" (selector-syntax)`,
+		`acls[1].dst[1]: invalid destination "tag:db": invalid ports "db" (selector-syntax)`,
+		`grants[0].dst[0]: invalid selector "autogroup:internt": unknown autogroup (selector-syntax)`,
+	}, findings)
+}
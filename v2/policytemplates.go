@@ -0,0 +1,109 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// StarterPreset identifies a starter policy generated by [StarterPolicy].
+type StarterPreset string
+
+const (
+	// StarterDefaultDeny allows no traffic at all. Admins own all tags, so that access can be
+	// granted incrementally.
+	StarterDefaultDeny StarterPreset = "default-deny"
+	// StarterAdminOnly allows admins to access all devices, and to SSH into tagged devices.
+	// Other users can only access their own devices.
+	StarterAdminOnly StarterPreset = "admin-only"
+	// StarterTagSegmentation allows devices with the same tag to access each other, admins to
+	// access all tagged devices, and users to access their own devices.
+	StarterTagSegmentation StarterPreset = "tag-segmentation"
+)
+
+// StarterAdminGroup is the group of admins used by starter policies.
+const StarterAdminGroup = "group:admin"
+
+// StarterPolicyOptions customizes the policies generated by [StarterPolicy].
+type StarterPolicyOptions struct {
+	// Admins lists the login names of the members of [StarterAdminGroup].
+	Admins []string
+	// Tags lists the tags to define, such as "tag:prod", each owned by [StarterAdminGroup]. With
+	// [StarterTagSegmentation], each tag is a separate segment.
+	Tags []string
+}
+
+// StarterPolicy generates a well-formed starter policy from preset, which can be set using
+// [PolicyFileResource.Set] to bootstrap a new tailnet.
+func StarterPolicy(preset StarterPreset, opts StarterPolicyOptions) (ACL, error) {
+	for _, admin := range opts.Admins {
+		if err := ValidateSelector(admin); err != nil || !strings.Contains(admin, "@") {
+			return ACL{}, fmt.Errorf("invalid admin %q, must be a login name", admin)
+		}
+	}
+	for _, tag := range opts.Tags {
+		if err := ValidateSelector(tag); err != nil || !strings.HasPrefix(tag, TagPrefix) {
+			return ACL{}, fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+
+	acl := ACL{
+		Groups:    map[string][]string{StarterAdminGroup: append([]string{}, opts.Admins...)},
+		TagOwners: make(map[string][]string),
+	}
+	for _, tag := range opts.Tags {
+		acl.TagOwners[tag] = []string{StarterAdminGroup}
+	}
+
+	switch preset {
+	case StarterDefaultDeny:
+	case StarterAdminOnly:
+		acl.ACLs = []ACLEntry{
+			{Action: "accept", Source: []string{StarterAdminGroup}, Destination: []string{"*:*"}},
+			{Action: "accept", Source: []string{AutogroupMember}, Destination: []string{AutogroupSelf + ":*"}},
+		}
+		acl.SSH = []ACLSSH{
+			{Action: ACLSSHActionCheck, Source: []string{StarterAdminGroup}, Destination: []string{AutogroupTagged}, Users: []string{AutogroupNonroot, "root"}},
+			{Action: ACLSSHActionCheck, Source: []string{AutogroupMember}, Destination: []string{AutogroupSelf}, Users: []string{AutogroupNonroot}},
+		}
+	case StarterTagSegmentation:
+		acl.ACLs = []ACLEntry{
+			{Action: "accept", Source: []string{AutogroupMember}, Destination: []string{AutogroupSelf + ":*"}},
+		}
+		if len(opts.Tags) > 0 {
+			admin := ACLEntry{Action: "accept", Source: []string{StarterAdminGroup}}
+			for _, tag := range opts.Tags {
+				admin.Destination = append(admin.Destination, tag+":*")
+				acl.ACLs = append(acl.ACLs, ACLEntry{Action: "accept", Source: []string{tag}, Destination: []string{tag + ":*"}})
+			}
+			acl.ACLs = append(acl.ACLs, admin)
+		}
+	default:
+		return ACL{}, fmt.Errorf("unknown starter preset %q", preset)
+	}
+	return acl, nil
+}
+
+// StarterPolicyHuJSON generates a starter policy like [StarterPolicy], formatted as HuJSON with
+// a comment describing the preset it was generated from.
+func StarterPolicyHuJSON(preset StarterPreset, opts StarterPolicyOptions) (string, error) {
+	acl, err := StarterPolicy(preset, opts)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(acl)
+	if err != nil {
+		return "", err
+	}
+	value, err := hujson.Parse(data)
+	if err != nil {
+		return "", err
+	}
+	value.Format()
+	return fmt.Sprintf("// Starter policy generated from the %s preset.\n// More details: https://tailscale.com/kb/1018/acls\n%s", preset, value), nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestStarterPolicy(t *testing.T) {
+	t.Parallel()
+
+	opts := tsclient.StarterPolicyOptions{Admins: []string{"alice@example.com"}, Tags: []string{"tag:prod", "tag:dev"}}
+	for _, preset := range []tsclient.StarterPreset{tsclient.StarterDefaultDeny, tsclient.StarterAdminOnly, tsclient.StarterTagSegmentation} {
+		acl, err := tsclient.StarterPolicy(preset, opts)
+		require.NoError(t, err, preset)
+		assert.Empty(t, acl.Lint(), preset)
+	}
+
+	acl, err := tsclient.StarterPolicy(tsclient.StarterTagSegmentation, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []tsclient.ACLEntry{
+		{Action: "accept", Source: []string{"autogroup:member"}, Destination: []string{"autogroup:self:*"}},
+		{Action: "accept", Source: []string{"tag:prod"}, Destination: []string{"tag:prod:*"}},
+		{Action: "accept", Source: []string{"tag:dev"}, Destination: []string{"tag:dev:*"}},
+		{Action: "accept", Source: []string{"group:admin"}, Destination: []string{"tag:prod:*", "tag:dev:*"}},
+	}, acl.ACLs)
+
+	_, err = tsclient.StarterPolicy("allow-all", opts)
+	assert.EqualError(t, err, `unknown starter preset "allow-all"`)
+	_, err = tsclient.StarterPolicy(tsclient.StarterDefaultDeny, tsclient.StarterPolicyOptions{Tags: []string{"prod"}})
+	assert.EqualError(t, err, `invalid tag "prod"`)
+}
+
+func TestStarterPolicyHuJSON(t *testing.T) {
+	t.Parallel()
+
+	huJSON, err := tsclient.StarterPolicyHuJSON(tsclient.StarterDefaultDeny, tsclient.StarterPolicyOptions{Admins: []string{"alice@example.com"}, Tags: []string{"tag:prod"}})
+	require.NoError(t, err)
+	assert.Equal(t, `// Starter policy generated from the default-deny preset.
+// More details: https://tailscale.com/kb/1018/acls
+{
+	"groups":    {"group:admin": ["alice@example.com"]},
+	"tagOwners": {"tag:prod": ["group:admin"]}
+}
+`, huJSON)
+
+	acl, err := tsclient.ParseACL(huJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"group:admin"}, acl.TagOwners["tag:prod"])
+}
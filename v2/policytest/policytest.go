@@ -0,0 +1,149 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Package policytest runs the tests of tailnet policy files using the validate endpoint of the
+// Tailscale API, for use in CI pipelines that check policy files before they are applied.
+//
+// [Run] runs the tests embedded in a policy file, optionally along with extra tests supplied in
+// code, and returns a [Report] that can be turned into Go subtests using [Report.Test], or
+// written as JUnit XML using [Report.WriteJUnit].
+package policytest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+// Result is the outcome of a single [tsclient.ACLTest].
+type Result struct {
+	// Name identifies the test, such as "tests[0] (src alice@example.com)".
+	Name string
+	// Index is the index of the test within the policy's tests followed by the extra tests, or
+	// -1 for failures that the API reported but that could not be matched to a test.
+	Index int
+	Test  tsclient.ACLTest
+	// Failures lists the failed assertions of the test, and is empty if the test passed.
+	Failures []string
+}
+
+// Passed reports whether the test passed.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Report holds the results of the tests run by [Run], in the order of the tests.
+type Report struct {
+	Results  []Result
+	Duration time.Duration
+}
+
+// Failed reports whether any test failed.
+func (r *Report) Failed() bool {
+	return slices.ContainsFunc(r.Results, func(result Result) bool { return !result.Passed() })
+}
+
+// Run validates acl using the API, running its tests followed by extra, and reports the result
+// of every test. The error is only non-nil if the tests could not be run, such as when acl is
+// invalid; failing tests are reported in the [Report].
+func Run(ctx context.Context, client *tsclient.Client, acl tsclient.ACL, extra ...tsclient.ACLTest) (*Report, error) {
+	acl.Tests = append(slices.Clone(acl.Tests), extra...)
+
+	start := time.Now()
+	failures, err := client.PolicyFile().ValidateTests(ctx, acl)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Duration: time.Since(start)}
+	for i, test := range acl.Tests {
+		report.Results = append(report.Results, Result{Name: testName(i, test), Index: i, Test: test})
+	}
+	for _, failure := range failures {
+		if failure.Index < 0 {
+			report.Results = append(report.Results, Result{
+				Name:     fmt.Sprintf("tests[?] (src %s)", failure.Source),
+				Index:    -1,
+				Failures: failure.Errors,
+			})
+			continue
+		}
+		report.Results[failure.Index].Failures = append(report.Results[failure.Index].Failures, failure.Errors...)
+	}
+	return report, nil
+}
+
+func testName(i int, test tsclient.ACLTest) string {
+	src := test.Source
+	if src == "" {
+		src = test.User
+	}
+	return fmt.Sprintf("tests[%d] (src %s)", i, src)
+}
+
+// Test reports every result as a subtest of t, failing the subtests of failed tests.
+func (r *Report) Test(t *testing.T) {
+	t.Helper()
+	for _, result := range r.Results {
+		t.Run(result.Name, func(t *testing.T) {
+			for _, failure := range result.Failures {
+				t.Error(failure)
+			}
+		})
+	}
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name     string        `xml:"name,attr"`
+	Failures []junitResult `xml:"failure,omitempty"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report to w as a JUnit XML test suite named name, with a test case for
+// every result.
+func (r *Report) WriteJUnit(w io.Writer, name string) error {
+	suite := junitTestSuite{
+		Name: name,
+		Time: fmt.Sprintf("%.3f", r.Duration.Seconds()),
+	}
+	for _, result := range r.Results {
+		testCase := junitTestCase{Name: result.Name}
+		for _, failure := range result.Failures {
+			testCase.Failures = append(testCase.Failures, junitResult{Message: failure})
+		}
+		if !result.Passed() {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
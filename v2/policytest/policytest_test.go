@@ -0,0 +1,77 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package policytest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+	"github.com/tailscale/tailscale-client-go/v2/policytest"
+)
+
+func newClient(t *testing.T, response tsclient.APIError) (*tsclient.Client, *[]tsclient.ACLTest) {
+	t.Helper()
+
+	var submitted []tsclient.ACLTest
+	return apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/tailnet/example.com/acl/validate", r.URL.Path)
+		var acl tsclient.ACL
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&acl))
+		submitted = acl.Tests
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	})), &submitted
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	client, submitted := newClient(t, tsclient.APIError{
+		Message: "test(s) failed",
+		Data: []tsclient.APIErrorData{
+			{User: "bob@example.com", Errors: []string{`address "100.64.0.1:22": want: Accept, got: Drop`}},
+		},
+	})
+	acl := tsclient.ACL{
+		Tests: []tsclient.ACLTest{{Source: "alice@example.com", Accept: []string{"tag:web:443"}}},
+	}
+
+	report, err := policytest.Run(context.Background(), client, acl, tsclient.ACLTest{Source: "bob@example.com", Accept: []string{"tag:db:22"}})
+	require.NoError(t, err)
+	assert.Len(t, *submitted, 2)
+	assert.Len(t, acl.Tests, 1)
+	assert.True(t, report.Failed())
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "tests[0] (src alice@example.com)", report.Results[0].Name)
+	assert.True(t, report.Results[0].Passed())
+	assert.Equal(t, "tests[1] (src bob@example.com)", report.Results[1].Name)
+	assert.Equal(t, []string{`address "100.64.0.1:22": want: Accept, got: Drop`}, report.Results[1].Failures)
+
+	var junit strings.Builder
+	require.NoError(t, report.WriteJUnit(&junit, "policy"))
+	assert.Contains(t, junit.String(), `<testsuite name="policy" tests="2" failures="1"`)
+	assert.Contains(t, junit.String(), `<testcase name="tests[0] (src alice@example.com)"></testcase>`)
+	assert.Contains(t, junit.String(), `<failure message="address &#34;100.64.0.1:22&#34;: want: Accept, got: Drop"></failure>`)
+}
+
+func TestRun_Passing(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newClient(t, tsclient.APIError{})
+	acl := tsclient.ACL{
+		Tests: []tsclient.ACLTest{{Source: "alice@example.com", Accept: []string{"tag:web:443"}}},
+	}
+
+	report, err := policytest.Run(context.Background(), client, acl)
+	require.NoError(t, err)
+	assert.False(t, report.Failed())
+	report.Test(t)
+}
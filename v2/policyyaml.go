@@ -0,0 +1,110 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalACLYAML marshals acl to YAML, using the same field names as the policy file, such as
+// "acls", "src" and "tagOwners", in the order they are marshalled to JSON.
+func MarshalACLYAML(acl ACL) ([]byte, error) {
+	data, err := json.Marshal(acl)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, so decoding it into a node preserves the order of fields. The node is
+	// restyled so that it is marshalled in block style, rather than as JSON.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// ParseACLYAML parses a policy file in YAML format, using the same field names as the policy
+// file, into an [ACL].
+func ParseACLYAML(data []byte) (*ACL, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	value, err := yamlNodeValue(&node)
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var acl ACL
+	if err := json.Unmarshal(data, &acl); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
+// yamlNodeValue converts node to a value that can be marshalled to JSON. Unlike decoding into
+// an any, keys of mappings are always strings, as required by JSON.
+func yamlNodeValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return map[string]any{}, nil
+		}
+		return yamlNodeValue(node.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[node.Content[i].Value] = value
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := yamlNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, value)
+		}
+		return s, nil
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.ScalarNode:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+	}
+}
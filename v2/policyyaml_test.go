@@ -0,0 +1,73 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestACL_YAML(t *testing.T) {
+	t.Parallel()
+
+	acl := tsclient.ACL{
+		ACLs: []tsclient.ACLEntry{
+			{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:web:443"}},
+		},
+		Groups:    map[string][]string{"group:eng": {"alice@example.com"}},
+		TagOwners: map[string][]string{"tag:web": {"group:eng"}},
+		DERPMap: &tsclient.ACLDERPMap{Regions: map[int]*tsclient.ACLDERPRegion{
+			900: {RegionID: 900, RegionCode: "home", RegionName: "Home", Nodes: []*tsclient.ACLDERPNode{{Name: "1", RegionID: 900, HostName: "derp.example.com"}}},
+		}},
+		DisableIPv4: true,
+	}
+
+	data, err := tsclient.MarshalACLYAML(acl)
+	require.NoError(t, err)
+	assert.Equal(t, `acls:
+  - action: accept
+    src:
+      - group:eng
+    dst:
+      - tag:web:443
+groups:
+  group:eng:
+    - alice@example.com
+tagOwners:
+  tag:web:
+    - group:eng
+derpMap:
+  regions:
+    "900":
+      regionID: 900
+      regionCode: home
+      regionName: Home
+      nodes:
+        - name: "1"
+          regionID: 900
+          hostName: derp.example.com
+disableIPv4: true
+`, string(data))
+
+	parsed, err := tsclient.ParseACLYAML(data)
+	require.NoError(t, err)
+	assert.Equal(t, acl, *parsed)
+
+	// Unquoted numeric keys, and anchors and aliases, are supported.
+	parsed, err = tsclient.ParseACLYAML([]byte(`
+groups:
+  group:eng: &eng [alice@example.com]
+tagOwners:
+  tag:web: *eng
+derpMap:
+  regions:
+    900: {regionID: 900}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, parsed.TagOwners["tag:web"])
+	assert.Equal(t, 900, parsed.DERPMap.Regions[900].RegionID)
+}
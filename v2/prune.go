@@ -0,0 +1,66 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PruneOptions configures [DevicesResource.PruneStale].
+type PruneOptions struct {
+	// MaxAge is the time since a device was last seen after which it is stale. Required, and
+	// must be positive.
+	MaxAge time.Duration
+	// IncludeTagged includes tagged devices, such as servers, which are excluded by default.
+	IncludeTagged bool
+	// IncludeExternal includes devices shared into the tailnet, which are excluded by default.
+	IncludeExternal bool
+	// DryRun reports stale devices without deleting them.
+	DryRun bool
+	// Batch configures how devices are deleted; see [Batch].
+	Batch BatchOptions
+}
+
+// PruneStale finds the devices of the tailnet that have not been seen for longer than
+// opts.MaxAge and, unless opts.DryRun is set, deletes them. Devices that have never been seen are
+// stale once they were created longer than opts.MaxAge ago. It returns the stale devices, ordered
+// by ID, and the result of deleting them, which is nil for a dry run.
+func (dr *DevicesResource) PruneStale(ctx context.Context, opts PruneOptions) ([]Device, BulkResult, error) {
+	if opts.MaxAge <= 0 {
+		return nil, nil, fmt.Errorf("invalid MaxAge %v, must be positive", opts.MaxAge)
+	}
+	devices, err := dr.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	stale := staleDevices(devices, opts, time.Now())
+	if opts.DryRun || len(stale) == 0 {
+		return stale, nil, nil
+	}
+
+	deviceIDs := make([]string, len(stale))
+	for i, d := range stale {
+		deviceIDs[i] = d.ID
+	}
+	return stale, dr.BulkDelete(ctx, deviceIDs, opts.Batch), nil
+}
+
+func staleDevices(devices []Device, opts PruneOptions, now time.Time) []Device {
+	var stale []Device
+	for _, d := range devices {
+		if (len(d.Tags) > 0 && !opts.IncludeTagged) || (d.IsExternal && !opts.IncludeExternal) {
+			continue
+		}
+		seen := d.LastSeen.Time
+		if seen.IsZero() {
+			seen = d.Created.Time
+		}
+		if !seen.IsZero() && now.Sub(seen) > opts.MaxAge {
+			stale = append(stale, d)
+		}
+	}
+	return stale
+}
@@ -0,0 +1,74 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Devices_PruneStale(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	old := tsclient.Time{Time: time.Now().Add(-60 * 24 * time.Hour)}
+	recent := tsclient.Time{Time: time.Now().Add(-time.Hour)}
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", LastSeen: old},
+			{ID: "2", LastSeen: recent},
+			{ID: "3", LastSeen: old, Tags: []string{"tag:server"}},
+			{ID: "4", LastSeen: old, IsExternal: true},
+			{ID: "5", Created: old},
+			{ID: "6"},
+		},
+	}
+	ids := func(devices []tsclient.Device) []string {
+		var ids []string
+		for _, d := range devices {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	stale, result, err := client.Devices().PruneStale(context.Background(), tsclient.PruneOptions{MaxAge: 30 * 24 * time.Hour, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "5"}, ids(stale))
+	assert.Nil(t, result)
+	assert.Equal(t, http.MethodGet, server.Method)
+
+	stale, result, err = client.Devices().PruneStale(context.Background(), tsclient.PruneOptions{
+		MaxAge:          30 * 24 * time.Hour,
+		IncludeTagged:   true,
+		IncludeExternal: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "3", "4", "5"}, ids(stale))
+	assert.Equal(t, tsclient.BulkResult{"1": nil, "3": nil, "4": nil, "5": nil}, result)
+	assert.Equal(t, http.MethodDelete, server.Method)
+}
+
+func TestClient_Devices_PruneStaleRequiresMaxAge(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {{ID: "1", LastSeen: tsclient.Time{Time: time.Now()}}}}
+
+	for _, maxAge := range []time.Duration{0, -time.Hour} {
+		stale, result, err := client.Devices().PruneStale(context.Background(), tsclient.PruneOptions{MaxAge: maxAge})
+		assert.ErrorContains(t, err, "must be positive")
+		assert.Nil(t, stale)
+		assert.Nil(t, result)
+	}
+	assert.Empty(t, server.Method)
+}
@@ -0,0 +1,362 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Package reconcile provides a small framework for building controllers that reconcile state
+// using the Tailscale API.
+//
+// A [Reconciler] processes keys from a de-duplicating work queue using a fixed number of workers.
+// Work is paced by a shared, weighted rate limit, and the whole queue backs off when the API
+// reports that it is rate limiting requests, rather than every worker retrying independently.
+// Keys can be periodically resynced with jitter, and the outcome of the last reconciliation of
+// every key is available through [Reconciler.Status].
+package reconcile
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+const defaultWorkers = 4
+const defaultRetryBackoff = time.Second
+const defaultMaxRetryBackoff = 5 * time.Minute
+const defaultRateLimitBackoff = 10 * time.Second
+
+// Func reconciles the object identified by key.
+type Func func(ctx context.Context, key string) error
+
+// Options configures a [Reconciler].
+type Options struct {
+	// Workers is the number of keys reconciled concurrently. Defaults to 4.
+	Workers int
+
+	// RatePerSecond is the number of rate limit tokens that can be consumed per second, shared by
+	// all workers. Zero means that reconciliations are not rate limited.
+	RatePerSecond float64
+	// Burst is the maximum number of tokens that can be consumed at once. Defaults to the larger
+	// of 1 and RatePerSecond.
+	Burst int
+	// Weight returns the number of rate limit tokens consumed by reconciling key, which should
+	// reflect the number of API calls it makes. Defaults to 1 for every key.
+	Weight func(key string) int
+
+	// RetryBackoff is the time to wait before retrying a failed key. It doubles with every
+	// consecutive failure, up to MaxRetryBackoff. Defaults to 1 second.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff is the maximum time to wait before retrying a failed key. Defaults to 5 minutes.
+	MaxRetryBackoff time.Duration
+	// RateLimitBackoff is the time for which all workers pause when a reconciliation fails because
+	// the API is rate limiting requests (see [tsclient.IsRateLimited]). Defaults to 10 seconds.
+	RateLimitBackoff time.Duration
+
+	// Resync is the interval at which every key is enqueued again. Zero disables resyncing.
+	Resync time.Duration
+	// ResyncJitter randomizes each resync interval by up to the given fraction of Resync in either
+	// direction, so that controllers started together do not resync in lockstep. Defaults to 0.1.
+	ResyncJitter float64
+	// Keys optionally lists the keys to enqueue on every resync. Defaults to every key that has
+	// been enqueued before.
+	Keys func(ctx context.Context) ([]string, error)
+}
+
+// Status describes the last reconciliation of a key.
+type Status struct {
+	Key string
+	// LastReconciled is the time at which the key was last reconciled, successfully or not.
+	LastReconciled time.Time
+	// LastSuccess is the time at which the key was last reconciled successfully.
+	LastSuccess time.Time
+	// Err is the error returned by the last reconciliation, or nil if it succeeded.
+	Err error
+	// Failures is the number of consecutive failed reconciliations.
+	Failures int
+}
+
+// Reconciler reconciles keys using a [Func]. Create one using [New].
+type Reconciler struct {
+	fn      Func
+	opts    Options
+	limiter *tokenBucket
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	queue       []string
+	queued      map[string]bool
+	processing  map[string]bool
+	dirty       map[string]bool
+	status      map[string]*Status
+	pausedUntil time.Time
+	stopped     bool
+}
+
+// New returns a [Reconciler] reconciling keys using fn.
+func New(fn Func, opts Options) *Reconciler {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.Weight == nil {
+		opts.Weight = func(string) int { return 1 }
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	if opts.MaxRetryBackoff <= 0 {
+		opts.MaxRetryBackoff = defaultMaxRetryBackoff
+	}
+	if opts.RateLimitBackoff <= 0 {
+		opts.RateLimitBackoff = defaultRateLimitBackoff
+	}
+	if opts.ResyncJitter == 0 {
+		opts.ResyncJitter = 0.1
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = max(1, int(opts.RatePerSecond))
+	}
+
+	r := &Reconciler{
+		fn:         fn,
+		opts:       opts,
+		limiter:    newTokenBucket(opts.RatePerSecond, opts.Burst),
+		queued:     make(map[string]bool),
+		processing: make(map[string]bool),
+		dirty:      make(map[string]bool),
+		status:     make(map[string]*Status),
+	}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// Enqueue adds key to the work queue. A key is only queued once at a time; if it is being
+// reconciled, it is reconciled again once the current reconciliation finishes.
+func (r *Reconciler) Enqueue(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enqueueLocked(key)
+}
+
+func (r *Reconciler) enqueueLocked(key string) {
+	if _, ok := r.status[key]; !ok {
+		r.status[key] = &Status{Key: key}
+	}
+	if r.queued[key] {
+		return
+	}
+	if r.processing[key] {
+		r.dirty[key] = true
+		return
+	}
+	r.queued[key] = true
+	r.queue = append(r.queue, key)
+	r.cond.Signal()
+}
+
+// Status returns the status of every key that has been enqueued, ordered by key.
+func (r *Reconciler) Status() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(r.status))
+	for _, s := range r.status {
+		statuses = append(statuses, *s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Key < statuses[j].Key })
+	return statuses
+}
+
+// Run processes the work queue until ctx is done. It returns once all in-flight
+// reconciliations have finished.
+func (r *Reconciler) Run(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = false
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for range r.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+	if r.opts.Resync > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.resync(ctx)
+		}()
+	}
+
+	<-ctx.Done()
+	r.mu.Lock()
+	r.stopped = true
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	wg.Wait()
+	return nil
+}
+
+func (r *Reconciler) work(ctx context.Context) {
+	for {
+		key, ok := r.next()
+		if !ok {
+			return
+		}
+		r.process(ctx, key)
+	}
+}
+
+// next blocks until a key is available or the reconciler is stopped.
+func (r *Reconciler) next() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.queue) == 0 && !r.stopped {
+		r.cond.Wait()
+	}
+	if r.stopped {
+		return "", false
+	}
+	key := r.queue[0]
+	r.queue = r.queue[1:]
+	delete(r.queued, key)
+	r.processing[key] = true
+	return key, true
+}
+
+func (r *Reconciler) process(ctx context.Context, key string) {
+	err := r.waitForCapacity(ctx, key)
+	if err == nil {
+		err = r.fn(ctx, key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.processing, key)
+
+	now := time.Now()
+	status := r.status[key]
+	status.LastReconciled = now
+	status.Err = err
+	if err == nil {
+		status.LastSuccess = now
+		status.Failures = 0
+	} else {
+		status.Failures++
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if r.dirty[key] {
+		delete(r.dirty, key)
+		r.enqueueLocked(key)
+		return
+	}
+	if err != nil {
+		if tsclient.IsRateLimited(err) {
+			r.pausedUntil = now.Add(r.opts.RateLimitBackoff)
+		}
+		backoff := min(r.opts.RetryBackoff<<min(status.Failures-1, 30), r.opts.MaxRetryBackoff)
+		time.AfterFunc(backoff, func() {
+			if ctx.Err() == nil {
+				r.Enqueue(key)
+			}
+		})
+	}
+}
+
+// waitForCapacity waits until the queue is not paused because of rate limiting, and until
+// enough rate limit tokens are available to reconcile key.
+func (r *Reconciler) waitForCapacity(ctx context.Context, key string) error {
+	r.mu.Lock()
+	pause := time.Until(r.pausedUntil)
+	r.mu.Unlock()
+	if err := sleep(ctx, pause); err != nil {
+		return err
+	}
+	return r.limiter.wait(ctx, r.opts.Weight(key))
+}
+
+func (r *Reconciler) resync(ctx context.Context) {
+	for {
+		jitter := (rand.Float64()*2 - 1) * r.opts.ResyncJitter
+		if err := sleep(ctx, time.Duration(float64(r.opts.Resync)*(1+jitter))); err != nil {
+			return
+		}
+
+		var keys []string
+		if r.opts.Keys != nil {
+			var err error
+			if keys, err = r.opts.Keys(ctx); err != nil {
+				continue
+			}
+		} else {
+			r.mu.Lock()
+			for key := range r.status {
+				keys = append(keys, key)
+			}
+			r.mu.Unlock()
+			sort.Strings(keys)
+		}
+		for _, key := range keys {
+			r.Enqueue(key)
+		}
+	}
+}
+
+// tokenBucket is a rate limiter allowing rate tokens per second, with bursts of up to burst tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until n tokens have been consumed. Requests for more tokens than burst are
+// allowed, leaving the bucket in debt.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	if b.rate <= 0 {
+		return ctx.Err()
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	return sleep(ctx, delay)
+}
+
+// sleep waits for the given duration, returning early with the context's error if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
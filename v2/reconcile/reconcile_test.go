@@ -0,0 +1,162 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/reconcile"
+)
+
+func TestReconciler_ReconcilesEnqueuedKeys(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	done := make(chan struct{})
+	r := reconcile.New(func(ctx context.Context, key string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[key]++
+		if len(seen) == 3 {
+			close(done)
+		}
+		return nil
+	}, reconcile.Options{Workers: 2})
+
+	r.Enqueue("a")
+	r.Enqueue("b")
+	r.Enqueue("c")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+	require.NoError(t, r.Run(ctx))
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, seen)
+	statuses := r.Status()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "a", statuses[0].Key)
+	assert.NoError(t, statuses[0].Err)
+	assert.False(t, statuses[0].LastSuccess.IsZero())
+}
+
+func TestReconciler_RetriesFailures(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	r := reconcile.New(func(ctx context.Context, key string) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("boom")
+		}
+		cancel()
+		return nil
+	}, reconcile.Options{RetryBackoff: time.Millisecond})
+
+	r.Enqueue("a")
+	require.NoError(t, r.Run(ctx))
+
+	assert.EqualValues(t, 3, attempts.Load())
+	statuses := r.Status()
+	require.Len(t, statuses, 1)
+	assert.NoError(t, statuses[0].Err)
+	assert.Zero(t, statuses[0].Failures)
+}
+
+func TestReconciler_PausesWhenRateLimited(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"rate limited"}`))
+	}))
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client := &tsclient.Client{BaseURL: baseURL, APIKey: "not-a-real-key"}
+
+	var times []time.Time
+	ctx, cancel := context.WithCancel(context.Background())
+	r := reconcile.New(func(ctx context.Context, key string) error {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			_, err := client.Devices().List(ctx)
+			return err
+		}
+		cancel()
+		return nil
+	}, reconcile.Options{
+		Workers:          1,
+		RetryBackoff:     time.Millisecond,
+		RateLimitBackoff: 50 * time.Millisecond,
+	})
+
+	r.Enqueue("a")
+	require.NoError(t, r.Run(ctx))
+
+	require.Len(t, times, 2)
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), 50*time.Millisecond)
+}
+
+func TestReconciler_WeightedRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var count atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	r := reconcile.New(func(ctx context.Context, key string) error {
+		if count.Add(1) == 3 {
+			cancel()
+		}
+		return nil
+	}, reconcile.Options{
+		RatePerSecond: 100,
+		Burst:         1,
+		Weight:        func(string) int { return 5 },
+	})
+
+	r.Enqueue("a")
+	r.Enqueue("b")
+	r.Enqueue("c")
+	start := time.Now()
+	require.NoError(t, r.Run(ctx))
+
+	// The first key uses the burst, and each further key waits for 5 tokens at 100 tokens per second.
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+}
+
+func TestReconciler_Resync(t *testing.T) {
+	t.Parallel()
+
+	var count atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	r := reconcile.New(func(ctx context.Context, key string) error {
+		if count.Add(1) == 3 {
+			cancel()
+		}
+		return nil
+	}, reconcile.Options{
+		Resync: 10 * time.Millisecond,
+		Keys: func(ctx context.Context) ([]string, error) {
+			return []string{"a"}, nil
+		},
+	})
+
+	require.NoError(t, r.Run(ctx))
+	assert.GreaterOrEqual(t, count.Load(), int32(3))
+}
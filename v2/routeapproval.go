@@ -0,0 +1,106 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+)
+
+// RouteApprovalPolicy decides whether route, advertised by device but not yet enabled, should be
+// enabled by [DevicesResource.ApproveRoutes].
+type RouteApprovalPolicy func(device *Device, route string) bool
+
+// AllowRoutesForTags returns a [RouteApprovalPolicy] approving routes advertised by tagged
+// devices. A route is approved if it is contained in one of the prefixes allowed for any of the
+// device's tags. Routes advertised by untagged devices, and routes that are not valid prefixes,
+// are never approved.
+func AllowRoutesForTags(allowed map[string][]netip.Prefix) RouteApprovalPolicy {
+	return func(device *Device, route string) bool {
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			return false
+		}
+		for _, tag := range device.Tags {
+			for _, allowedPrefix := range allowed[tag] {
+				if allowedPrefix.Bits() <= prefix.Bits() && allowedPrefix.Contains(prefix.Addr()) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// RouteApproval reports the outcome of [DevicesResource.ApproveRoutes] for a single pending route.
+type RouteApproval struct {
+	DeviceID   string
+	DeviceName string
+	Route      string
+	// Approved is true if the policy approved the route.
+	Approved bool
+	// Enabled is true if the route was enabled. It is always false for dry runs.
+	Enabled bool
+	// Err is the error encountered while enabling the route, if any.
+	Err error
+}
+
+// ApproveRoutes finds every subnet route that is advertised by a device in the tailnet but not
+// yet enabled, and enables the routes approved by policy. If dryRun is true, no routes are
+// enabled, and the returned report only describes which routes would be.
+//
+// The report contains an entry for every pending route, ordered by device ID. Failures to enable
+// a device's routes are reported in the entries for that device, and the returned error is nil
+// unless the devices could not be listed.
+func (dr *DevicesResource) ApproveRoutes(ctx context.Context, policy RouteApprovalPolicy, dryRun bool) ([]RouteApproval, error) {
+	devices, err := dr.List(ctx, WithAllFields())
+	if err != nil {
+		return nil, err
+	}
+
+	var report []RouteApproval
+	// pending holds, for every device with approved routes, the indexes of its entries in report.
+	pending := make(map[*Device][]int)
+	var toUpdate []*Device
+	for i := range devices {
+		d := &devices[i]
+		for _, route := range d.AdvertisedRoutes {
+			if slices.Contains(d.EnabledRoutes, route) {
+				continue
+			}
+			approved := policy(d, route)
+			report = append(report, RouteApproval{DeviceID: d.ID, DeviceName: d.Name, Route: route, Approved: approved})
+			if approved {
+				if len(pending[d]) == 0 {
+					toUpdate = append(toUpdate, d)
+				}
+				pending[d] = append(pending[d], len(report)-1)
+			}
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	err = Batch(ctx, toUpdate, BatchOptions{}, func(ctx context.Context, d *Device) error {
+		routes := slices.Clone(d.EnabledRoutes)
+		for _, i := range pending[d] {
+			routes = append(routes, report[i].Route)
+		}
+		return dr.SetSubnetRoutes(ctx, d.ID, routes)
+	})
+	var batchErr BatchError
+	errors.As(err, &batchErr)
+	for i, d := range toUpdate {
+		err := batchErr.Errors[i]
+		for _, j := range pending[d] {
+			report[j].Enabled = err == nil
+			report[j].Err = err
+		}
+	}
+
+	return report, nil
+}
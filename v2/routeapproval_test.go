@@ -0,0 +1,84 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func routeApprovalDevices() map[string][]tsclient.Device {
+	return map[string][]tsclient.Device{
+		"devices": {
+			{
+				ID:               "router",
+				Name:             "router.example.com",
+				Tags:             []string{"tag:router"},
+				AdvertisedRoutes: []string{"10.0.0.0/24", "10.1.0.0/16", "192.168.0.0/24"},
+				EnabledRoutes:    []string{"10.0.0.0/24"},
+			},
+			{
+				ID:               "laptop",
+				Name:             "laptop.example.com",
+				AdvertisedRoutes: []string{"10.2.0.0/24"},
+			},
+		},
+	}
+}
+
+func TestClient_Devices_ApproveRoutes(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/devices": routeApprovalDevices(),
+	}
+
+	policy := tsclient.AllowRoutesForTags(map[string][]netip.Prefix{
+		"tag:router": {netip.MustParsePrefix("10.0.0.0/8")},
+	})
+	report, err := client.Devices().ApproveRoutes(context.Background(), policy, false)
+	require.NoError(t, err)
+	assert.Equal(t, []tsclient.RouteApproval{
+		{DeviceID: "laptop", DeviceName: "laptop.example.com", Route: "10.2.0.0/24"},
+		{DeviceID: "router", DeviceName: "router.example.com", Route: "10.1.0.0/16", Approved: true, Enabled: true},
+		{DeviceID: "router", DeviceName: "router.example.com", Route: "192.168.0.0/24"},
+	}, report)
+
+	assert.Equal(t, http.MethodPost, server.Method)
+	assert.Equal(t, "/api/v2/device/router/routes", server.Path)
+	body := make(map[string][]string)
+	assert.NoError(t, json.Unmarshal(server.Body.Bytes(), &body))
+	assert.Equal(t, []string{"10.0.0.0/24", "10.1.0.0/16"}, body["routes"])
+}
+
+func TestClient_Devices_ApproveRoutes_DryRun(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = routeApprovalDevices()
+
+	approveAll := func(*tsclient.Device, string) bool { return true }
+	report, err := client.Devices().ApproveRoutes(context.Background(), approveAll, true)
+	require.NoError(t, err)
+	require.Len(t, report, 3)
+	for _, approval := range report {
+		assert.True(t, approval.Approved)
+		assert.False(t, approval.Enabled)
+	}
+
+	assert.Equal(t, http.MethodGet, server.Method)
+	assert.Equal(t, "/api/v2/tailnet/example.com/devices", server.Path)
+	assert.Equal(t, "all", server.Query.Get("fields"))
+}
@@ -0,0 +1,123 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+const (
+	SearchHitDevice SearchHitKind = "device"
+	SearchHitUser   SearchHitKind = "user"
+	SearchHitKey    SearchHitKind = "key"
+)
+
+// SearchHitKind identifies the type of resource matched by [Client.Search].
+type SearchHitKind string
+
+// SearchHit is a single resource matched by [Client.Search]. Exactly one of Device, User and Key
+// is set, depending on Kind.
+type SearchHit struct {
+	Kind SearchHitKind
+	// ID is the identifier of the matched resource.
+	ID string
+	// Field is the name of the field that matched the query, such as "hostname" or "loginName".
+	Field string
+	// Value is the value of the field that matched the query.
+	Value string
+
+	Device *Device
+	User   *User
+	Key    *Key
+}
+
+// Search finds devices, users and keys in the tailnet that match query. Matching is a
+// case-insensitive substring match against device names, hostnames, addresses and tags,
+// user login and display names, and key descriptions. Each resource is returned at most once,
+// for the first of its fields that matched.
+//
+// Since the API only returns key identifiers when listing keys, Search fetches every key
+// individually, which may be slow for tailnets with many keys. Keys that are deleted before they
+// are fetched are skipped.
+func (c *Client) Search(ctx context.Context, query string) ([]SearchHit, error) {
+	query = strings.ToLower(query)
+	var hits []SearchHit
+
+	devices, err := c.Devices().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		d := &devices[i]
+		fields := []string{"name", d.Name, "hostname", d.Hostname}
+		for _, address := range d.Addresses {
+			fields = append(fields, "addresses", address)
+		}
+		for _, tag := range d.Tags {
+			fields = append(fields, "tags", tag)
+		}
+		if field, value, ok := searchMatch(query, fields...); ok {
+			hits = append(hits, SearchHit{Kind: SearchHitDevice, ID: d.ID, Field: field, Value: value, Device: d})
+		}
+	}
+
+	users, err := c.Users().List(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		u := &users[i]
+		if field, value, ok := searchMatch(query, "loginName", u.LoginName, "displayName", u.DisplayName); ok {
+			hits = append(hits, SearchHit{Kind: SearchHitUser, ID: u.ID, Field: field, Value: value, User: u})
+		}
+	}
+
+	keyIDs, err := c.Keys().List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	keys := make(map[string]*Key, len(keyIDs))
+	err = Batch(ctx, keyIDs, BatchOptions{}, func(ctx context.Context, k Key) error {
+		key, err := c.Keys().Get(ctx, k.ID)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys[k.ID] = key
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Iterate over keyIDs rather than keys to keep the results in a stable order.
+	for _, k := range keyIDs {
+		key, ok := keys[k.ID]
+		if !ok {
+			continue
+		}
+		if field, value, ok := searchMatch(query, "description", key.Description); ok {
+			hits = append(hits, SearchHit{Kind: SearchHitKey, ID: key.ID, Field: field, Value: value, Key: key})
+		}
+	}
+
+	return hits, nil
+}
+
+// searchMatch reports the first of the given field name and value pairs whose value contains
+// the lowercase query.
+func searchMatch(query string, fieldsAndValues ...string) (field, value string, ok bool) {
+	for i := 0; i+1 < len(fieldsAndValues); i += 2 {
+		if v := fieldsAndValues[i+1]; v != "" && strings.Contains(strings.ToLower(v), query) {
+			return fieldsAndValues[i], v, true
+		}
+	}
+	return "", "", false
+}
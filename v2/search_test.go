@@ -0,0 +1,94 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/devices": map[string][]tsclient.Device{
+			"devices": {
+				{ID: "device1", Name: "build.example.ts.net", Hostname: "build"},
+				{ID: "device2", Name: "web.example.ts.net", Hostname: "web", Tags: []string{"tag:build"}},
+				{ID: "device3", Name: "db.example.ts.net", Hostname: "db", Addresses: []string{"100.64.0.1"}},
+			},
+		},
+		"/api/v2/tailnet/example.com/users": map[string][]tsclient.User{
+			"users": {
+				{ID: "user1", LoginName: "builder@example.com", DisplayName: "Bob"},
+				{ID: "user2", LoginName: "alice@example.com", DisplayName: "Alice"},
+			},
+		},
+		"/api/v2/tailnet/example.com/keys": map[string][]tsclient.Key{
+			"keys": {{ID: "key1"}, {ID: "key2"}},
+		},
+		"/api/v2/tailnet/example.com/keys/key1": tsclient.Key{ID: "key1", Description: "CI build runners"},
+		"/api/v2/tailnet/example.com/keys/key2": tsclient.Key{ID: "key2", Description: "laptops"},
+	}
+
+	hits, err := client.Search(context.Background(), "BUILD")
+	assert.NoError(t, err)
+
+	type hit struct {
+		kind  tsclient.SearchHitKind
+		id    string
+		field string
+		value string
+	}
+	var actual []hit
+	for _, h := range hits {
+		actual = append(actual, hit{h.Kind, h.ID, h.Field, h.Value})
+	}
+	assert.Equal(t, []hit{
+		{tsclient.SearchHitDevice, "device1", "name", "build.example.ts.net"},
+		{tsclient.SearchHitDevice, "device2", "tags", "tag:build"},
+		{tsclient.SearchHitUser, "user1", "loginName", "builder@example.com"},
+		{tsclient.SearchHitKey, "key1", "description", "CI build runners"},
+	}, actual)
+	assert.Equal(t, "build", hits[0].Device.Hostname)
+	assert.Equal(t, "Bob", hits[2].User.DisplayName)
+	assert.Equal(t, "key1", hits[3].Key.ID)
+
+	hits, err = client.Search(context.Background(), "100.64.0.1")
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "device3", hits[0].ID)
+}
+
+func TestClient_SearchDeletedKey(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/devices": map[string][]tsclient.Device{"devices": {}},
+		"/api/v2/tailnet/example.com/users":   map[string][]tsclient.User{"users": {}},
+		"/api/v2/tailnet/example.com/keys": map[string][]tsclient.Key{
+			"keys": {{ID: "key1"}, {ID: "key2"}},
+		},
+		"/api/v2/tailnet/example.com/keys/key1": map[string]string{"message": "not found"},
+		"/api/v2/tailnet/example.com/keys/key2": tsclient.Key{ID: "key2", Description: "CI build runners"},
+	}
+	server.ResponseCodes = map[string]int{"/api/v2/tailnet/example.com/keys/key1": http.StatusNotFound}
+
+	hits, err := client.Search(context.Background(), "build")
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "key2", hits[0].ID)
+
+	server.ResponseCodes["/api/v2/tailnet/example.com/keys/key1"] = http.StatusInternalServerError
+	_, err = client.Search(context.Background(), "build")
+	assert.Error(t, err)
+}
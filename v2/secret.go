@@ -0,0 +1,66 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const redactedSecret = "[REDACTED]"
+
+// Secret is a secret value returned by the API, such as the key returned by [Key.Secret]. It is
+// redacted when formatted or logged, so that it is not accidentally leaked. Use [Secret.Reveal] to
+// access the secret value. Secrets are not redacted when marshalled to JSON.
+type Secret string
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String returns a redacted representation of the secret, or the empty string if the secret is
+// empty.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redactedSecret
+}
+
+// GoString implements [fmt.GoStringer], redacting the secret when formatted with %#v.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// LogValue implements [slog.LogValuer], redacting the secret when logged.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// redactedKey is a [Key] whose secret has been redacted. It has none of the methods of Key, so that
+// it can be formatted without recursing into them.
+type redactedKey Key
+
+func (k Key) redacted() redactedKey {
+	k.Key = Secret(k.Key).String()
+	return redactedKey(k)
+}
+
+// String returns a representation of the key with its secret redacted, so that formatting a [Key]
+// with %s, %v or %+v does not leak it.
+func (k Key) String() string {
+	return fmt.Sprintf("%+v", k.redacted())
+}
+
+// GoString implements [fmt.GoStringer], redacting the secret when formatted with %#v.
+func (k Key) GoString() string {
+	return "tsclient.Key" + strings.TrimPrefix(fmt.Sprintf("%#v", k.redacted()), "tsclient.redactedKey")
+}
+
+// LogValue implements [slog.LogValuer], redacting the secret when logged.
+func (k Key) LogValue() slog.Value {
+	return slog.AnyValue(k.redacted())
+}
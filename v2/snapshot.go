@@ -0,0 +1,163 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TailnetSnapshot captures the configuration of a tailnet at a point in time.
+type TailnetSnapshot struct {
+	Tailnet string    `json:"tailnet"`
+	Created time.Time `json:"created"`
+
+	// PolicyFile is the tailnet policy file as a HuJSON string, preserving comments and formatting.
+	PolicyFile     string `json:"policyFile"`
+	PolicyFileETag string `json:"policyFileETag,omitempty"`
+
+	DNSNameservers  []string         `json:"dnsNameservers"`
+	DNSSearchPaths  []string         `json:"dnsSearchPaths"`
+	DNSSplitDNS     SplitDNSResponse `json:"dnsSplitDNS"`
+	DNSPreferences  *DNSPreferences  `json:"dnsPreferences"`
+	TailnetSettings *TailnetSettings `json:"tailnetSettings"`
+	Webhooks        []Webhook        `json:"webhooks"`
+	Devices         []Device         `json:"devices"`
+}
+
+// Snapshot captures a [TailnetSnapshot] of the tailnet's current configuration.
+func (c *Client) Snapshot(ctx context.Context) (*TailnetSnapshot, error) {
+	c.init()
+	snapshot := &TailnetSnapshot{
+		Tailnet: c.Tailnet,
+		Created: time.Now().UTC(),
+	}
+	if snapshot.Tailnet == "" {
+		snapshot.Tailnet = defaultTailnet
+	}
+
+	raw, err := c.PolicyFile().Raw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.PolicyFile = raw.HuJSON
+	snapshot.PolicyFileETag = raw.ETag
+
+	if snapshot.DNSNameservers, err = c.DNS().Nameservers(ctx); err != nil {
+		return nil, err
+	}
+	if snapshot.DNSSearchPaths, err = c.DNS().SearchPaths(ctx); err != nil {
+		return nil, err
+	}
+	if snapshot.DNSSplitDNS, err = c.DNS().SplitDNS(ctx); err != nil {
+		return nil, err
+	}
+	if snapshot.DNSPreferences, err = c.DNS().Preferences(ctx); err != nil {
+		return nil, err
+	}
+	if snapshot.TailnetSettings, err = c.TailnetSettings().Get(ctx); err != nil {
+		return nil, err
+	}
+	if snapshot.Webhooks, err = c.Webhooks().List(ctx); err != nil {
+		return nil, err
+	}
+	if snapshot.Devices, err = c.Devices().List(ctx); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotStorage persists snapshots written by [SaveSnapshot] and read by [LoadSnapshot].
+// Implementations can store snapshots anywhere, such as in an object store like S3, and may
+// encrypt them at rest.
+type SnapshotStorage interface {
+	// Write stores the content read from r under the given name, replacing any existing content.
+	Write(ctx context.Context, name string, r io.Reader) error
+	// Read returns the content stored under the given name.
+	Read(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// FileSnapshotStorage is a [SnapshotStorage] storing snapshots as files in a directory.
+type FileSnapshotStorage struct {
+	// Dir is the directory in which snapshots are stored. It must already exist.
+	Dir string
+}
+
+// Write implements [SnapshotStorage]. The file is written atomically by writing to a temporary
+// file first and renaming it once complete.
+func (fs FileSnapshotStorage) Write(ctx context.Context, name string, r io.Reader) error {
+	f, err := os.CreateTemp(fs.Dir, "."+filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), fs.path(name))
+}
+
+// Read implements [SnapshotStorage].
+func (fs FileSnapshotStorage) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(fs.path(name))
+}
+
+func (fs FileSnapshotStorage) path(name string) string {
+	return filepath.Join(fs.Dir, filepath.Base(name))
+}
+
+// SaveSnapshot writes the gzip-compressed JSON encoding of snapshot to storage under the given name.
+func SaveSnapshot(ctx context.Context, storage SnapshotStorage, name string, snapshot *TailnetSnapshot) error {
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		err := json.NewEncoder(zw).Encode(snapshot)
+		pw.CloseWithError(errors.Join(err, zw.Close()))
+	}()
+
+	err := storage.Write(ctx, name, pr)
+	// Unblock the encoder if storage returned without consuming all of the content.
+	pr.CloseWithError(io.ErrClosedPipe)
+	return err
+}
+
+// LoadSnapshot reads the snapshot stored in storage under the given name. It accepts both
+// gzip-compressed and uncompressed JSON content.
+func LoadSnapshot(ctx context.Context, storage SnapshotStorage, name string) (*TailnetSnapshot, error) {
+	rc, err := storage.Read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	br := bufio.NewReader(rc)
+	var r io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	var snapshot TailnetSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseHeader.Add("ETag", "myetag")
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/acl":             huJSONACL,
+		"/api/v2/tailnet/example.com/dns/nameservers": map[string][]string{"dns": {"8.8.8.8"}},
+		"/api/v2/tailnet/example.com/dns/searchpaths": map[string][]string{"searchPaths": {"example.com"}},
+		"/api/v2/tailnet/example.com/dns/split-dns":   tsclient.SplitDNSResponse{"example.com": {"1.1.1.1"}},
+		"/api/v2/tailnet/example.com/dns/preferences": tsclient.DNSPreferences{MagicDNS: true},
+		"/api/v2/tailnet/example.com/settings":        tsclient.TailnetSettings{DevicesApprovalOn: true},
+		"/api/v2/tailnet/example.com/webhooks":        map[string][]tsclient.Webhook{"webhooks": {{EndpointID: "webhook1"}}},
+		"/api/v2/tailnet/example.com/devices":         map[string][]tsclient.Device{"devices": {{ID: "device1"}}},
+	}
+
+	snapshot, err := client.Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", snapshot.Tailnet)
+	assert.False(t, snapshot.Created.IsZero())
+	assert.Equal(t, string(huJSONACL), snapshot.PolicyFile)
+	assert.Equal(t, "myetag", snapshot.PolicyFileETag)
+	assert.Equal(t, []string{"8.8.8.8"}, snapshot.DNSNameservers)
+	assert.Equal(t, []string{"example.com"}, snapshot.DNSSearchPaths)
+	assert.Equal(t, tsclient.SplitDNSResponse{"example.com": {"1.1.1.1"}}, snapshot.DNSSplitDNS)
+	assert.True(t, snapshot.DNSPreferences.MagicDNS)
+	assert.True(t, snapshot.TailnetSettings.DevicesApprovalOn)
+	assert.Equal(t, "webhook1", snapshot.Webhooks[0].EndpointID)
+	assert.Equal(t, "device1", snapshot.Devices[0].ID)
+}
+
+func TestSnapshot_SaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	storage := tsclient.FileSnapshotStorage{Dir: t.TempDir()}
+	expected := &tsclient.TailnetSnapshot{
+		Tailnet:        "example.com",
+		PolicyFile:     string(huJSONACL),
+		DNSNameservers: []string{"8.8.8.8"},
+	}
+
+	require.NoError(t, tsclient.SaveSnapshot(context.Background(), storage, "snapshot.json.gz", expected))
+
+	content, err := os.ReadFile(filepath.Join(storage.Dir, "snapshot.json.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x1f, 0x8b}, content[:2], "snapshot should be gzip compressed")
+
+	actual, err := tsclient.LoadSnapshot(context.Background(), storage, "snapshot.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestSnapshot_LoadUncompressed(t *testing.T) {
+	t.Parallel()
+
+	storage := tsclient.FileSnapshotStorage{Dir: t.TempDir()}
+	require.NoError(t, os.WriteFile(filepath.Join(storage.Dir, "snapshot.json"), []byte(`{"tailnet":"example.com"}`), 0o600))
+
+	actual, err := tsclient.LoadSnapshot(context.Background(), storage, "snapshot.json")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", actual.Tailnet)
+}
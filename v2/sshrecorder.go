@@ -0,0 +1,107 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SSHRecorderError is returned by [ACL.CheckRecorders] and [PolicyFileResource.CheckRecorders]
+// when the session recording configuration of SSH rules cannot work as intended.
+type SSHRecorderError struct {
+	Problems []SSHRecorderProblem
+}
+
+// SSHRecorderProblem describes a problem with the session recording configuration of an SSH rule.
+type SSHRecorderProblem struct {
+	// Rule is the index of the SSH rule within the policy file.
+	Rule int
+	// Recorder is the recorder the problem is about, or empty if it is about the rule as a whole.
+	Recorder string
+	Message  string
+}
+
+func (p SSHRecorderProblem) String() string {
+	if p.Recorder == "" {
+		return fmt.Sprintf("ssh[%d]: %s", p.Rule, p.Message)
+	}
+	return fmt.Sprintf("ssh[%d]: recorder %s: %s", p.Rule, p.Recorder, p.Message)
+}
+
+func (err SSHRecorderError) Error() string {
+	problems := make([]string, len(err.Problems))
+	for i, p := range err.Problems {
+		problems[i] = p.String()
+	}
+	return fmt.Sprintf("invalid SSH session recording: %s", strings.Join(problems, "; "))
+}
+
+// CheckRecorders reports an [SSHRecorderError] if the session recording configuration of the SSH
+// rules of acl cannot work with the given devices of the tailnet: if a recorder is not a tag
+// defined in tagOwners, if no device has a recorder tag, if none of the recorders of a rule is
+// online, or if a rule enforces recording without any recorders.
+func (acl *ACL) CheckRecorders(devices []Device) error {
+	var problems []SSHRecorderProblem
+	for i, rule := range acl.SSH {
+		if len(rule.Recorder) == 0 {
+			if rule.EnforceRecorder {
+				problems = append(problems, SSHRecorderProblem{Rule: i, Message: "enforceRecorder requires at least one recorder"})
+			}
+			continue
+		}
+
+		online := false
+		for _, recorder := range rule.Recorder {
+			problem := SSHRecorderProblem{Rule: i, Recorder: recorder}
+			if !strings.HasPrefix(recorder, TagPrefix) {
+				problem.Message = "recorders must be tags"
+				problems = append(problems, problem)
+				continue
+			}
+			if _, ok := acl.TagOwners[recorder]; !ok {
+				problem.Message = "tag is not defined in tagOwners"
+				problems = append(problems, problem)
+				continue
+			}
+
+			found := false
+			for _, device := range devices {
+				if slices.Contains(device.Tags, recorder) {
+					found = true
+					online = online || deviceOnline(&device, 0)
+				}
+			}
+			if !found {
+				problem.Message = "no device has this tag"
+				problems = append(problems, problem)
+			}
+		}
+
+		if !online {
+			message := "no recorder is online, so sessions will not be recorded"
+			if rule.EnforceRecorder {
+				message = "no recorder is online, so sessions will fail because enforceRecorder is set"
+			}
+			problems = append(problems, SSHRecorderProblem{Rule: i, Message: message})
+		}
+	}
+	if len(problems) > 0 {
+		return SSHRecorderError{Problems: problems}
+	}
+	return nil
+}
+
+// CheckRecorders lists the devices of the tailnet and checks the session recording
+// configuration of the SSH rules of acl against them, as described for [ACL.CheckRecorders],
+// so that problems can be reported before acl is set.
+func (pr *PolicyFileResource) CheckRecorders(ctx context.Context, acl ACL, opts ...ListOption) error {
+	devices, err := pr.Devices().List(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	return acl.CheckRecorders(devices)
+}
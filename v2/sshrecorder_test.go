@@ -0,0 +1,62 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestACL_CheckRecorders(t *testing.T) {
+	t.Parallel()
+
+	acl := &tsclient.ACL{
+		TagOwners: map[string][]string{"tag:recorder": {"group:eng"}, "tag:recorder-backup": {"group:eng"}},
+		SSH: []tsclient.ACLSSH{
+			{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:prod"}, Users: []string{"root"}, Recorder: []string{"tag:recorder"}, EnforceRecorder: true},
+			{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:dev"}, Users: []string{"root"}, EnforceRecorder: true},
+			{Action: "accept", Source: []string{"group:eng"}, Destination: []string{"tag:ci"}, Users: []string{"root"}, Recorder: []string{"recorder", "tag:unknown", "tag:recorder-backup"}},
+		},
+	}
+	devices := []tsclient.Device{
+		{ID: "1", Tags: []string{"tag:recorder"}, LastSeen: tsclient.Time{Time: time.Now()}},
+		{ID: "2", Tags: []string{"tag:recorder-backup"}, LastSeen: tsclient.Time{Time: time.Now().Add(-time.Hour)}},
+	}
+
+	err := acl.CheckRecorders(devices)
+	var recorderErr tsclient.SSHRecorderError
+	assert.ErrorAs(t, err, &recorderErr)
+	assert.Equal(t, []tsclient.SSHRecorderProblem{
+		{Rule: 1, Message: "enforceRecorder requires at least one recorder"},
+		{Rule: 2, Recorder: "recorder", Message: "recorders must be tags"},
+		{Rule: 2, Recorder: "tag:unknown", Message: "tag is not defined in tagOwners"},
+		{Rule: 2, Message: "no recorder is online, so sessions will not be recorded"},
+	}, recorderErr.Problems)
+	assert.ErrorContains(t, err, "invalid SSH session recording: ssh[1]: enforceRecorder requires at least one recorder; ssh[2]: recorder recorder: recorders must be tags")
+
+	acl.SSH = acl.SSH[:1]
+	assert.NoError(t, acl.CheckRecorders(devices))
+	assert.EqualError(t, acl.CheckRecorders(nil), "invalid SSH session recording: ssh[0]: recorder tag:recorder: no device has this tag; "+
+		"ssh[0]: no recorder is online, so sessions will fail because enforceRecorder is set")
+}
+
+func TestClient_CheckRecorders(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {{ID: "1", Tags: []string{"tag:recorder"}, LastSeen: tsclient.Time{Time: time.Now()}}}}
+
+	acl := tsclient.ACL{
+		TagOwners: map[string][]string{"tag:recorder": {"group:eng"}},
+		SSH:       []tsclient.ACLSSH{{Action: "accept", Recorder: []string{"tag:recorder"}, EnforceRecorder: true}},
+	}
+	assert.NoError(t, client.PolicyFile().CheckRecorders(context.Background(), acl))
+	assert.Equal(t, "/api/v2/tailnet/example.com/devices", server.Path)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TagOwnershipError is returned by [ACL.CheckTagOwnership] and [DevicesResource.SetOwnedTags] when
+// the caller does not own some of the tags that it wants to apply.
+type TagOwnershipError struct {
+	// Identities are the identities of the caller that were checked.
+	Identities []string
+	// Problems explains, for each tag that the caller cannot apply, which tagOwners rule is missing.
+	Problems []TagOwnershipProblem
+}
+
+// TagOwnershipProblem explains why a tag cannot be applied.
+type TagOwnershipProblem struct {
+	Tag string
+	// Owners are the owners of Tag in the policy file. It is nil if Tag is not defined.
+	Owners []string
+}
+
+func (p TagOwnershipProblem) String() string {
+	if p.Owners == nil {
+		return fmt.Sprintf("%s is not defined in tagOwners", p.Tag)
+	}
+	return fmt.Sprintf("%s is owned by %s", p.Tag, strings.Join(p.Owners, ", "))
+}
+
+func (err TagOwnershipError) Error() string {
+	problems := make([]string, len(err.Problems))
+	for i, p := range err.Problems {
+		problems[i] = p.String()
+	}
+	return fmt.Sprintf("%s may not apply tags: %s", strings.Join(err.Identities, ", "), strings.Join(problems, "; "))
+}
+
+// tagAdminAutogroups are the autogroups of the roles that may apply any tag.
+var tagAdminAutogroups = []string{AutogroupOwner, AutogroupAdmin, AutogroupNetworkAdmin}
+
+// CheckTagOwnership reports a [TagOwnershipError] if a caller with the given identities may not
+// apply every one of tags according to the tagOwners of acl. identities are the login name of the
+// caller, the autogroups of its role such as "autogroup:admin", or for OAuth clients, their tags.
+// Groups in tagOwners are expanded using the groups of acl. Owners, admins and network admins may
+// apply every tag defined in tagOwners, whether or not they are listed as its owners.
+func (acl *ACL) CheckTagOwnership(identities []string, tags []string) error {
+	tagAdmin := slices.ContainsFunc(identities, func(identity string) bool {
+		return slices.Contains(tagAdminAutogroups, identity)
+	})
+	var problems []TagOwnershipProblem
+	for _, tag := range tags {
+		owners, ok := acl.TagOwners[tag]
+		if !ok {
+			problems = append(problems, TagOwnershipProblem{Tag: tag})
+			continue
+		}
+		if !tagAdmin && !slices.ContainsFunc(owners, func(owner string) bool { return acl.ownerMatches(owner, identities) }) {
+			problems = append(problems, TagOwnershipProblem{Tag: tag, Owners: slices.Clip(owners)})
+		}
+	}
+	if len(problems) > 0 {
+		return TagOwnershipError{Identities: identities, Problems: problems}
+	}
+	return nil
+}
+
+// ownerMatches reports whether owner, an entry of tagOwners, refers to any of identities.
+func (acl *ACL) ownerMatches(owner string, identities []string) bool {
+	if slices.Contains(identities, owner) {
+		return true
+	}
+	if strings.HasPrefix(owner, "group:") {
+		return slices.ContainsFunc(acl.Groups[owner], func(member string) bool {
+			return slices.Contains(identities, member)
+		})
+	}
+	return false
+}
+
+// SetOwnedTags is like [DevicesResource.SetTags], but first gets the policy file of the tailnet
+// and checks that a caller with the given identities owns every one of tags, as described for
+// [ACL.CheckTagOwnership]. This turns the generic error returned by the API into a
+// [TagOwnershipError] explaining which tagOwners rule is missing.
+func (dr *DevicesResource) SetOwnedTags(ctx context.Context, deviceID string, identities []string, tags []string, opts ...WriteOption) error {
+	acl, err := dr.PolicyFile().Get(ctx)
+	if err != nil {
+		return err
+	}
+	if err := acl.CheckTagOwnership(identities, tags); err != nil {
+		return err
+	}
+	return dr.SetTags(ctx, deviceID, tags, opts...)
+}
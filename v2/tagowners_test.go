@@ -0,0 +1,67 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestACL_CheckTagOwnership(t *testing.T) {
+	t.Parallel()
+
+	acl := &tsclient.ACL{
+		Groups: map[string][]string{"group:eng": {"alice@example.com"}},
+		TagOwners: map[string][]string{
+			"tag:server": {"group:eng"},
+			"tag:ci":     {"tag:ci-runner", "autogroup:admin"},
+		},
+	}
+
+	assert.NoError(t, acl.CheckTagOwnership([]string{"alice@example.com"}, []string{"tag:server"}))
+	assert.NoError(t, acl.CheckTagOwnership([]string{"tag:ci-runner"}, []string{"tag:ci"}))
+	assert.NoError(t, acl.CheckTagOwnership([]string{"bob@example.com", "autogroup:admin"}, []string{"tag:ci"}))
+
+	assert.NoError(t, acl.CheckTagOwnership([]string{"bob@example.com", "autogroup:admin"}, []string{"tag:server", "tag:ci"}))
+	assert.NoError(t, acl.CheckTagOwnership([]string{"autogroup:network-admin"}, []string{"tag:server"}))
+	assert.Error(t, acl.CheckTagOwnership([]string{"autogroup:admin"}, []string{"tag:unknown"}))
+	assert.Error(t, acl.CheckTagOwnership([]string{"autogroup:auditor"}, []string{"tag:server"}))
+
+	err := acl.CheckTagOwnership([]string{"bob@example.com"}, []string{"tag:server", "tag:ci", "tag:unknown"})
+	var ownershipErr tsclient.TagOwnershipError
+	assert.ErrorAs(t, err, &ownershipErr)
+	assert.Equal(t, []tsclient.TagOwnershipProblem{
+		{Tag: "tag:server", Owners: []string{"group:eng"}},
+		{Tag: "tag:ci", Owners: []string{"tag:ci-runner", "autogroup:admin"}},
+		{Tag: "tag:unknown"},
+	}, ownershipErr.Problems)
+	assert.EqualError(t, err, "bob@example.com may not apply tags: tag:server is owned by group:eng; "+
+		"tag:ci is owned by tag:ci-runner, autogroup:admin; tag:unknown is not defined in tagOwners")
+}
+
+func TestClient_SetOwnedDeviceTags(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/acl": tsclient.ACL{TagOwners: map[string][]string{"tag:server": {"alice@example.com"}}},
+	}
+
+	err := client.Devices().SetOwnedTags(context.Background(), "test", []string{"bob@example.com"}, []string{"tag:server"})
+	assert.ErrorAs(t, err, &tsclient.TagOwnershipError{})
+	assert.Equal(t, "/api/v2/tailnet/example.com/acl", server.Path)
+
+	assert.NoError(t, client.Devices().SetOwnedTags(context.Background(), "test", []string{"alice@example.com"}, []string{"tag:server"}))
+	assert.Equal(t, "/api/v2/device/test/tags", server.Path)
+	assert.JSONEq(t, `{"tags":["tag:server"]}`, server.Body.String())
+
+	assert.NoError(t, client.Devices().SetOwnedTags(context.Background(), "test", []string{"carol@example.com", "autogroup:admin"}, []string{"tag:server"}))
+	assert.Equal(t, "/api/v2/device/test/tags", server.Path)
+}
@@ -5,9 +5,18 @@ package tsclient
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
+const settingsVerifyInterval = time.Second
+const settingsVerifyAttempts = 10
+
 // TailnetSettingsResource provides access to https://tailscale.com/api#tag/tailnetsettings.
 type TailnetSettingsResource struct {
 	*Client
@@ -55,8 +64,8 @@ const (
 
 // Get retrieves the current [TailnetSettings].
 // See https://tailscale.com/api#tag/tailnetsettings/GET/tailnet/{tailnet}/settings.
-func (tsr *TailnetSettingsResource) Get(ctx context.Context) (*TailnetSettings, error) {
-	req, err := tsr.buildRequest(ctx, http.MethodGet, tsr.buildTailnetURL("settings"))
+func (tsr *TailnetSettingsResource) Get(ctx context.Context, opts ...GetOption) (*TailnetSettings, error) {
+	req, err := tsr.buildRequest(ctx, http.MethodGet, tsr.buildTailnetURL("settings"), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -66,11 +75,114 @@ func (tsr *TailnetSettingsResource) Get(ctx context.Context) (*TailnetSettings,
 
 // Update updates the tailnet settings.
 // See https://tailscale.com/api#tag/tailnetsettings/PATCH/tailnet/{tailnet}/settings.
-func (tsr *TailnetSettingsResource) Update(ctx context.Context, request UpdateTailnetSettingsRequest) error {
-	req, err := tsr.buildRequest(ctx, http.MethodPatch, tsr.buildTailnetURL("settings"), requestBody(request))
+func (tsr *TailnetSettingsResource) Update(ctx context.Context, request UpdateTailnetSettingsRequest, opts ...WriteOption) error {
+	req, err := tsr.buildRequest(ctx, http.MethodPatch, tsr.buildTailnetURL("settings"), requestBody(request), writeOptions(opts))
 	if err != nil {
 		return err
 	}
 
 	return tsr.do(req, nil)
 }
+
+// Validate checks that the request only contains values accepted by the API.
+func (r UpdateTailnetSettingsRequest) Validate() error {
+	if r.DevicesKeyDurationDays != nil && (*r.DevicesKeyDurationDays < 1 || *r.DevicesKeyDurationDays > 180) {
+		return fmt.Errorf("devicesKeyDurationDays must be between 1 and 180, got %d", *r.DevicesKeyDurationDays)
+	}
+	if role := r.UsersRoleAllowedToJoinExternalTailnets; role != nil {
+		switch *role {
+		case RoleAllowedToJoinExternalTailnetsNone, RoleAllowedToJoinExternalTailnetsAdmin, RoleAllowedToJoinExternalTailnetsMember:
+		default:
+			return fmt.Errorf("invalid usersRoleAllowedToJoinExternalTailnets %q", *role)
+		}
+	}
+	return nil
+}
+
+// SettingsNotAppliedError is returned by [TailnetSettingsResource.UpdateAndVerify] when the
+// updated settings could not be read back from the API.
+type SettingsNotAppliedError struct {
+	// Fields are the JSON names of the settings that did not have the requested values.
+	Fields []string
+}
+
+func (err SettingsNotAppliedError) Error() string {
+	return "tailnet settings not applied: " + strings.Join(err.Fields, ", ")
+}
+
+// UpdateAndVerify validates request, updates the tailnet settings, and then reads the settings back
+// until they reflect the update. Since settings updates are eventually consistent, the settings are
+// read up to 10 times, once per second, before giving up with a [SettingsNotAppliedError].
+// It returns the settings that were read back.
+func (tsr *TailnetSettingsResource) UpdateAndVerify(ctx context.Context, request UpdateTailnetSettingsRequest, opts ...WriteOption) (*TailnetSettings, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if err := tsr.Update(ctx, request, opts...); err != nil {
+		return nil, err
+	}
+
+	var settings *TailnetSettings
+	var attempts int
+	err := Poll(ctx, settingsVerifyInterval, func(ctx context.Context) (bool, error) {
+		var err error
+		if settings, err = tsr.Get(ctx); err != nil {
+			return false, err
+		}
+		mismatched, err := settingsMismatches(request, settings)
+		if err != nil || len(mismatched) == 0 {
+			return true, err
+		}
+		if attempts++; attempts == settingsVerifyAttempts {
+			return false, SettingsNotAppliedError{Fields: mismatched}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// EnableRegionalRouting enables regional routing for the tailnet, verifying that the change was applied.
+func (tsr *TailnetSettingsResource) EnableRegionalRouting(ctx context.Context, opts ...WriteOption) error {
+	return tsr.setVerified(ctx, UpdateTailnetSettingsRequest{RegionalRoutingOn: PointerTo(true)}, opts)
+}
+
+// DisableRegionalRouting disables regional routing for the tailnet, verifying that the change was applied.
+func (tsr *TailnetSettingsResource) DisableRegionalRouting(ctx context.Context, opts ...WriteOption) error {
+	return tsr.setVerified(ctx, UpdateTailnetSettingsRequest{RegionalRoutingOn: PointerTo(false)}, opts)
+}
+
+func (tsr *TailnetSettingsResource) setVerified(ctx context.Context, request UpdateTailnetSettingsRequest, opts []WriteOption) error {
+	_, err := tsr.UpdateAndVerify(ctx, request, opts...)
+	return err
+}
+
+// settingsMismatches returns the JSON names of the fields set in request whose values differ in settings.
+func settingsMismatches(request UpdateTailnetSettingsRequest, settings *TailnetSettings) ([]string, error) {
+	var requested, actual map[string]any
+	if err := remarshal(request, &requested); err != nil {
+		return nil, err
+	}
+	if err := remarshal(settings, &actual); err != nil {
+		return nil, err
+	}
+
+	var mismatched []string
+	for field, value := range requested {
+		if !reflect.DeepEqual(value, actual[field]) {
+			mismatched = append(mismatched, field)
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched, nil
+}
+
+func remarshal(in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	tsclient "github.com/tailscale/tailscale-client-go/v2"
@@ -64,3 +65,59 @@ func TestClient_TailnetSettings_Update(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, updateRequest, receivedRequest)
 }
+
+func TestClient_TailnetSettings_EnableRegionalRouting(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = tsclient.TailnetSettings{RegionalRoutingOn: true}
+
+	assert.NoError(t, client.TailnetSettings().EnableRegionalRouting(context.Background()))
+	// The last request reads back the settings to verify the update.
+	assert.Equal(t, http.MethodGet, server.Method)
+	assert.Equal(t, "/api/v2/tailnet/example.com/settings", server.Path)
+}
+
+func TestClient_TailnetSettings_UpdateAndVerify_NotApplied(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = tsclient.TailnetSettings{RegionalRoutingOn: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := client.TailnetSettings().UpdateAndVerify(ctx, tsclient.UpdateTailnetSettingsRequest{
+		RegionalRoutingOn: tsclient.PointerTo(false),
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_TailnetSettings_UpdateAndVerify_NotAppliedError(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = tsclient.TailnetSettings{RegionalRoutingOn: false, DevicesKeyDurationDays: 90}
+
+	_, err := client.TailnetSettings().UpdateAndVerify(context.Background(), tsclient.UpdateTailnetSettingsRequest{
+		RegionalRoutingOn:      tsclient.PointerTo(true),
+		DevicesApprovalOn:      tsclient.PointerTo(false),
+		DevicesKeyDurationDays: tsclient.PointerTo(90),
+	})
+	var notApplied tsclient.SettingsNotAppliedError
+	assert.ErrorAs(t, err, &notApplied)
+	assert.Equal(t, []string{"regionalRoutingOn"}, notApplied.Fields)
+	assert.EqualError(t, err, "tailnet settings not applied: regionalRoutingOn")
+}
+
+func TestUpdateTailnetSettingsRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, tsclient.UpdateTailnetSettingsRequest{DevicesKeyDurationDays: tsclient.PointerTo(180)}.Validate())
+	assert.Error(t, tsclient.UpdateTailnetSettingsRequest{DevicesKeyDurationDays: tsclient.PointerTo(0)}.Validate())
+	assert.Error(t, tsclient.UpdateTailnetSettingsRequest{
+		UsersRoleAllowedToJoinExternalTailnets: tsclient.PointerTo(tsclient.RoleAllowedToJoinExternalTailnets("everyone")),
+	}.Validate())
+}
@@ -0,0 +1,44 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ResolveTailnet returns the name of the tailnet the Client operates on, such as
+// "tail1234.ts.net", resolving the default tailnet "-" used when [Client.Tailnet] is empty to the
+// tailnet of the credential.
+//
+// The name is read from the MagicDNS names of the devices of the tailnet, as listed by
+// [DevicesResource.List], so the credential needs read access to devices, and the tailnet must have
+// at least one device of its own.
+func (c *Client) ResolveTailnet(ctx context.Context) (string, error) {
+	devices, err := c.Devices().List(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, device := range devices {
+		if device.IsExternal {
+			continue
+		}
+		if _, tailnet, ok := strings.Cut(strings.TrimSuffix(device.Name, "."), "."); ok && tailnet != "" {
+			return tailnet, nil
+		}
+	}
+	return "", errors.New("unable to resolve tailnet: no devices with a MagicDNS name")
+}
+
+// Tailnets returns the names of the tailnets accessible to the credential of the Client, regardless
+// of [Client.Tailnet]. API keys and OAuth clients are scoped to a single tailnet, which is
+// resolved as described for [Client.ResolveTailnet].
+func (c *Client) Tailnets(ctx context.Context) ([]string, error) {
+	tailnet, err := c.ForTailnet(defaultTailnet).ResolveTailnet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []string{tailnet}, nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestClient_ResolveTailnet(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBody = map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", Name: "shared.other.ts.net", IsExternal: true},
+			{ID: "2", Name: "laptop.tail1234.ts.net."},
+		},
+	}
+
+	tailnet, err := client.ResolveTailnet(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "tail1234.ts.net", tailnet)
+	assert.Equal(t, "/api/v2/tailnet/example.com/devices", server.Path)
+
+	tailnets, err := client.Tailnets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tail1234.ts.net"}, tailnets)
+	assert.Equal(t, "/api/v2/tailnet/-/devices", server.Path)
+
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {{ID: "1", Name: "shared.other.ts.net", IsExternal: true}}}
+	_, err = client.ResolveTailnet(context.Background())
+	assert.EqualError(t, err, "unable to resolve tailnet: no devices with a MagicDNS name")
+
+	server.ResponseBody = map[string][]tsclient.Device{"devices": {}}
+	_, err = client.Tailnets(context.Background())
+	assert.Error(t, err)
+}
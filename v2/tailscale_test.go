@@ -12,6 +12,8 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,7 +21,8 @@ import (
 )
 
 type TestServer struct {
-	t *testing.T
+	t  *testing.T
+	mu sync.Mutex
 
 	BaseURL *url.URL
 
@@ -32,6 +35,20 @@ type TestServer struct {
 	ResponseCode   int
 	ResponseBody   interface{}
 	ResponseHeader http.Header
+
+	// ResponseBodies optionally overrides ResponseBody for requests to specific paths,
+	// for tests that exercise several endpoints.
+	ResponseBodies map[string]interface{}
+	// ResponseCodes optionally overrides ResponseCode for requests to specific paths.
+	ResponseCodes map[string]int
+
+	// Strict enables checks of every request received by the server. Requests fail the test if
+	// they are not authenticated, lack a User-Agent, lack a Content-Type for requests with a body
+	// or an Accept header otherwise, use a method other than ExpectMethod (if set), or carry query
+	// parameters other than ExpectQuery.
+	Strict       bool
+	ExpectMethod string
+	ExpectQuery  url.Values
 }
 
 func NewTestHarness(t *testing.T) (*tsclient.Client, *TestServer) {
@@ -74,6 +91,9 @@ func NewTestHarness(t *testing.T) (*tsclient.Client, *TestServer) {
 }
 
 func (t *TestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.Method = r.Method
 	t.Path = r.URL.Path
 	t.Query = r.URL.Query()
@@ -83,10 +103,24 @@ func (t *TestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_, err := io.Copy(t.Body, r.Body)
 	assert.NoError(t.t, err)
 
+	if t.Strict {
+		t.checkRequest(r, t.Body.Len() > 0)
+	}
+
+	responseBody := t.ResponseBody
+	if b, ok := t.ResponseBodies[r.URL.Path]; ok {
+		responseBody = b
+	}
+
+	responseCode := t.ResponseCode
+	if code, ok := t.ResponseCodes[r.URL.Path]; ok {
+		responseCode = code
+	}
+
 	maps.Copy(w.Header(), t.ResponseHeader)
-	w.WriteHeader(t.ResponseCode)
-	if t.ResponseBody != nil {
-		switch body := t.ResponseBody.(type) {
+	w.WriteHeader(responseCode)
+	if responseBody != nil {
+		switch body := responseBody.(type) {
 		case []byte:
 			_, err := w.Write(body)
 			assert.NoError(t.t, err)
@@ -95,3 +129,27 @@ func (t *TestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// checkRequest implements the checks enabled by TestServer.Strict.
+func (t *TestServer) checkRequest(r *http.Request, hasBody bool) {
+	t.t.Helper()
+
+	if _, _, ok := r.BasicAuth(); !ok && !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		t.t.Errorf("%s %s: request is not authenticated", r.Method, r.URL.Path)
+	}
+	if r.Header.Get("User-Agent") == "" {
+		t.t.Errorf("%s %s: request has no User-Agent", r.Method, r.URL.Path)
+	}
+	if hasBody && r.Header.Get("Content-Type") == "" {
+		t.t.Errorf("%s %s: request has a body but no Content-Type", r.Method, r.URL.Path)
+	}
+	if !hasBody && r.Header.Get("Accept") == "" {
+		t.t.Errorf("%s %s: request has no Accept header", r.Method, r.URL.Path)
+	}
+	if t.ExpectMethod != "" && r.Method != t.ExpectMethod {
+		t.t.Errorf("%s %s: expected method %s", r.Method, r.URL.Path, t.ExpectMethod)
+	}
+	if query := r.URL.Query(); len(query) > 0 || len(t.ExpectQuery) > 0 {
+		assert.Equal(t.t, t.ExpectQuery, query, "%s %s: unexpected query parameters", r.Method, r.URL.Path)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Timeouts configures the timeouts of requests made by a [Client]. Requests to a resource use
+// the timeout configured for that resource if it is non-zero, and Default otherwise.
+//
+// Timeouts are applied in addition to any Timeout configured on the [Client].HTTP, so they
+// cannot extend beyond it.
+type Timeouts struct {
+	// Default is the timeout of requests to resources without a specific timeout. Defaults to
+	// 1 minute, unless Timeouts is the zero value and the [Client].HTTP was supplied by the caller,
+	// in which case requests are only limited by the Timeout of the [http.Client].
+	Default time.Duration
+
+	Contacts        time.Duration
+	DeviceInvites   time.Duration
+	DevicePosture   time.Duration
+	Devices         time.Duration
+	DNS             time.Duration
+	Keys            time.Duration
+	Logging         time.Duration
+	PolicyFile      time.Duration
+	TailnetSettings time.Duration
+	Users           time.Duration
+	Webhooks        time.Duration
+}
+
+// timeoutFor returns the timeout for a request to the given API URL, or zero if the request should
+// only be limited by the Timeout of c.HTTP.
+func (c *Client) timeoutFor(u *url.URL) time.Duration {
+	if timeout := c.Timeouts.forURL(u); timeout > 0 {
+		return timeout
+	}
+	if c.ownsHTTP || c.Timeouts != (Timeouts{}) {
+		return defaultHttpClientTimeout
+	}
+	return 0
+}
+
+// forURL returns the timeout configured for a request to the given API URL, or zero if there is none.
+func (t Timeouts) forURL(u *url.URL) time.Duration {
+	var timeout time.Duration
+	switch apiResource(u) {
+	case "contacts":
+		timeout = t.Contacts
+	case "device-invites":
+		timeout = t.DeviceInvites
+	case "posture":
+		timeout = t.DevicePosture
+	case "device", "devices":
+		timeout = t.Devices
+	case "dns":
+		timeout = t.DNS
+	case "keys":
+		timeout = t.Keys
+	case "logging", "aws-external-id":
+		timeout = t.Logging
+	case "acl":
+		timeout = t.PolicyFile
+	case "settings":
+		timeout = t.TailnetSettings
+	case "users":
+		timeout = t.Users
+	case "webhooks":
+		timeout = t.Webhooks
+	}
+	if timeout == 0 {
+		timeout = t.Default
+	}
+	return timeout
+}
+
+// apiResource returns the first path element identifying the resource of a URL built by
+// [Client.buildURL] or [Client.buildTailnetURL], such as "devices" for /api/v2/tailnet/-/devices.
+func apiResource(u *url.URL) string {
+	_, path, ok := strings.Cut(u.EscapedPath(), "api/v2/")
+	if !ok {
+		return ""
+	}
+	elems := strings.Split(path, "/")
+	if elems[0] == "tailnet" {
+		elems = elems[min(2, len(elems)):]
+	}
+	if len(elems) == 0 {
+		return ""
+	}
+	return elems[0]
+}
@@ -0,0 +1,59 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// TransportConfig configures connection pooling and protocol settings of an [http.Transport].
+// Zero values keep the defaults of [http.DefaultTransport].
+//
+// The defaults keep at most 2 idle connections per host, which forces automation making many
+// concurrent calls to repeatedly establish new connections. Raising MaxIdleConnsPerHost to the
+// expected concurrency avoids that.
+type TransportConfig struct {
+	// MaxIdleConns is the maximum number of idle connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the total number of connections per host, including connections
+	// in use. Requests beyond the limit wait for a connection to become available.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept before being closed.
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 disables HTTP/2, forcing HTTP/1.1 connections. With HTTP/2, concurrent requests
+	// are multiplexed over a single connection, which is usually preferable.
+	DisableHTTP2 bool
+}
+
+// Transport returns a new [http.Transport] based on [http.DefaultTransport] with the configured settings applied.
+func (tc TransportConfig) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if tc.MaxIdleConns != 0 {
+		t.MaxIdleConns = tc.MaxIdleConns
+	}
+	if tc.MaxIdleConnsPerHost != 0 {
+		t.MaxIdleConnsPerHost = tc.MaxIdleConnsPerHost
+	}
+	if tc.MaxConnsPerHost != 0 {
+		t.MaxConnsPerHost = tc.MaxConnsPerHost
+	}
+	if tc.IdleConnTimeout != 0 {
+		t.IdleConnTimeout = tc.IdleConnTimeout
+	}
+	if tc.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
+	}
+	return t
+}
+
+// HTTPClient returns a new [http.Client] using a transport with the configured settings, for use
+// as [Client].HTTP. Requests made with it time out after 1 minute.
+func (tc TransportConfig) HTTPClient() *http.Client {
+	return &http.Client{Transport: tc.Transport(), Timeout: defaultHttpClientTimeout}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func TestTransportConfig(t *testing.T) {
+	t.Parallel()
+
+	transport := tsclient.TransportConfig{
+		MaxIdleConns:        50,
+		MaxIdleConnsPerHost: 20,
+		MaxConnsPerHost:     30,
+		IdleConnTimeout:     time.Minute,
+		DisableHTTP2:        true,
+	}.Transport()
+	assert.Equal(t, 50, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 30, transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+
+	defaults := tsclient.TransportConfig{}.Transport()
+	assert.Equal(t, http.DefaultTransport.(*http.Transport).MaxIdleConns, defaults.MaxIdleConns)
+	assert.True(t, defaults.ForceAttemptHTTP2)
+
+	assert.Equal(t, time.Minute, tsclient.TransportConfig{}.HTTPClient().Timeout)
+}
+
+// BenchmarkTransportConfig compares making concurrent requests using the default transport
+// settings with making them using a transport that keeps enough idle connections around.
+func BenchmarkTransportConfig(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	b.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(b, err)
+
+	for _, bb := range []struct {
+		name   string
+		config tsclient.TransportConfig
+	}{
+		{"Default", tsclient.TransportConfig{}},
+		{"MaxIdleConnsPerHost=64", tsclient.TransportConfig{MaxIdleConns: 64, MaxIdleConnsPerHost: 64}},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			client := &tsclient.Client{
+				BaseURL: baseURL,
+				HTTP:    bb.config.HTTPClient(),
+			}
+			b.SetParallelism(16)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := client.Devices().Delete(context.Background(), "test"); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
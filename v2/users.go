@@ -63,8 +63,8 @@ type User struct {
 }
 
 // List lists every [User] of the tailnet. If userType and/or role are provided,
-// the list of users will be filtered by those.
-func (ur *UsersResource) List(ctx context.Context, userType *UserType, role *UserRole) ([]User, error) {
+// the list of users will be filtered by those. Users are ordered by ID.
+func (ur *UsersResource) List(ctx context.Context, userType *UserType, role *UserRole, opts ...ListOption) ([]User, error) {
 	u := ur.buildTailnetURL("users")
 	q := u.Query()
 	if userType != nil {
@@ -75,7 +75,7 @@ func (ur *UsersResource) List(ctx context.Context, userType *UserType, role *Use
 	}
 	u.RawQuery = q.Encode()
 
-	req, err := ur.buildRequest(ctx, http.MethodGet, u)
+	req, err := ur.buildRequest(ctx, http.MethodGet, u, listOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -85,15 +85,59 @@ func (ur *UsersResource) List(ctx context.Context, userType *UserType, role *Use
 		return nil, err
 	}
 
-	return resp["users"], nil
+	return sortByID(resp["users"], func(u User) string { return u.ID }), nil
 }
 
 // Get retrieves the [User] identified by the given id.
-func (ur *UsersResource) Get(ctx context.Context, id string) (*User, error) {
-	req, err := ur.buildRequest(ctx, http.MethodGet, ur.buildURL("users", id))
+func (ur *UsersResource) Get(ctx context.Context, id string, opts ...GetOption) (*User, error) {
+	req, err := ur.buildRequest(ctx, http.MethodGet, ur.buildURL("users", id), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
 
 	return body[User](ur, req)
 }
+
+// UserDeviceCountDiscrepancy describes a [User] whose DeviceCount does not match the number of
+// devices listed for it in the tailnet.
+type UserDeviceCountDiscrepancy struct {
+	User User
+	// Devices are the devices listed for the user.
+	Devices []Device
+}
+
+// DeviceCountDiscrepancies cross-checks the DeviceCount of every [User] in the tailnet against
+// the devices listed for that user by [DevicesResource.List], and returns every user for which
+// they differ. Devices are attributed to users by login name, excluding devices shared in from
+// other tailnets.
+//
+// Discrepancies can indicate stale or stuck devices, or issues with accounting for shared devices.
+func (ur *UsersResource) DeviceCountDiscrepancies(ctx context.Context) ([]UserDeviceCountDiscrepancy, error) {
+	users, err := ur.List(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := ur.Devices().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devicesByUser := make(map[string][]Device)
+	for _, d := range devices {
+		if d.IsExternal {
+			continue
+		}
+		devicesByUser[d.User] = append(devicesByUser[d.User], d)
+	}
+
+	var discrepancies []UserDeviceCountDiscrepancy
+	for _, u := range users {
+		if userDevices := devicesByUser[u.LoginName]; len(userDevices) != u.DeviceCount {
+			discrepancies = append(discrepancies, UserDeviceCountDiscrepancy{
+				User:    u,
+				Devices: userDevices,
+			})
+		}
+	}
+	return discrepancies, nil
+}
@@ -93,3 +93,32 @@ func TestClient_Users_Get(t *testing.T) {
 	assert.Equal(t, "/api/v2/users/12345", server.Path)
 	assert.Equal(t, expectedUser, actualUser)
 }
+
+func TestClient_Users_DeviceCountDiscrepancies(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/users": map[string][]tsclient.User{
+			"users": {
+				{ID: "1", LoginName: "alice@example.com", DeviceCount: 2},
+				{ID: "2", LoginName: "bob@example.com", DeviceCount: 2},
+			},
+		},
+		"/api/v2/tailnet/example.com/devices": map[string][]tsclient.Device{
+			"devices": {
+				{ID: "a1", User: "alice@example.com"},
+				{ID: "a2", User: "alice@example.com"},
+				{ID: "b1", User: "bob@example.com"},
+				{ID: "b2", User: "bob@example.com", IsExternal: true},
+			},
+		},
+	}
+
+	discrepancies, err := client.Users().DeviceCountDiscrepancies(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, discrepancies, 1)
+	assert.Equal(t, "bob@example.com", discrepancies[0].User.LoginName)
+	assert.Equal(t, []tsclient.Device{{ID: "b1", User: "bob@example.com"}}, discrepancies[0].Devices)
+}
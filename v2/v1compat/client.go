@@ -0,0 +1,334 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Package v1compat provides the method set of the deprecated v1 tailscale.Client on top of
+// [tsclient.Client], so that codebases can migrate to v2 incrementally.
+//
+// Methods have the same names and parameters as their v1 counterparts, but use the types of
+// the v2 package. The underlying [tsclient.Client] is available through [Client.V2], allowing
+// call sites to be migrated one at a time.
+package v1compat
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+// Client implements the method set of the v1 tailscale.Client.
+type Client struct {
+	v2 *tsclient.Client
+}
+
+// ClientOption is a function that is used to modify a [Client] created by [NewClient].
+type ClientOption func(c *tsclient.Client) error
+
+// NewClient returns a new [Client] that performs operations against the given tailnet, authenticating
+// using apiKey. To use OAuth client credentials, pass an empty apiKey and [WithOAuthClientCredentials].
+func NewClient(apiKey, tailnet string, options ...ClientOption) (*Client, error) {
+	c := &tsclient.Client{
+		APIKey:  apiKey,
+		Tailnet: tailnet,
+	}
+
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.APIKey == "" && c.HTTP == nil {
+		return nil, errors.New("no authentication credentials provided")
+	}
+
+	return &Client{v2: c}, nil
+}
+
+// WithBaseURL sets a custom base URL for the Tailscale API, this is primarily used for testing purposes.
+// When combined with [WithOAuthClientCredentials], it must be specified first.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *tsclient.Client) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+
+		c.BaseURL = u
+		return nil
+	}
+}
+
+// WithOAuthClientCredentials sets the OAuth client credentials to use for the Tailscale API.
+func WithOAuthClientCredentials(clientID, clientSecret string, scopes []string) ClientOption {
+	return func(c *tsclient.Client) error {
+		oauthConfig := tsclient.OAuthConfig{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Scopes:       scopes,
+		}
+		if c.BaseURL != nil {
+			oauthConfig.BaseURL = c.BaseURL.String()
+		}
+		c.HTTP = oauthConfig.HTTPClient()
+		return nil
+	}
+}
+
+// WithUserAgent sets a custom User-Agent header in HTTP requests.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *tsclient.Client) error {
+		c.UserAgent = ua
+		return nil
+	}
+}
+
+// V2 returns the underlying [tsclient.Client].
+func (c *Client) V2() *tsclient.Client {
+	return c.v2
+}
+
+// SetDNSSearchPaths replaces the list of search paths for the tailnet.
+func (c *Client) SetDNSSearchPaths(ctx context.Context, searchPaths []string) error {
+	return c.v2.DNS().SetSearchPaths(ctx, searchPaths)
+}
+
+// DNSSearchPaths retrieves the list of search paths that is currently set for the tailnet.
+func (c *Client) DNSSearchPaths(ctx context.Context) ([]string, error) {
+	return c.v2.DNS().SearchPaths(ctx)
+}
+
+// SetDNSNameservers replaces the list of DNS nameservers for the tailnet.
+func (c *Client) SetDNSNameservers(ctx context.Context, dns []string) error {
+	return c.v2.DNS().SetNameservers(ctx, dns)
+}
+
+// DNSNameservers lists the DNS nameservers for the tailnet.
+func (c *Client) DNSNameservers(ctx context.Context) ([]string, error) {
+	return c.v2.DNS().Nameservers(ctx)
+}
+
+// UpdateSplitDNS partially updates the split DNS settings for the tailnet.
+func (c *Client) UpdateSplitDNS(ctx context.Context, request tsclient.SplitDNSRequest) (tsclient.SplitDNSResponse, error) {
+	return c.v2.DNS().UpdateSplitDNS(ctx, request)
+}
+
+// SetSplitDNS replaces the split DNS settings for the tailnet.
+func (c *Client) SetSplitDNS(ctx context.Context, request tsclient.SplitDNSRequest) error {
+	return c.v2.DNS().SetSplitDNS(ctx, request)
+}
+
+// SplitDNS retrieves the split DNS configuration for the tailnet.
+func (c *Client) SplitDNS(ctx context.Context) (tsclient.SplitDNSResponse, error) {
+	return c.v2.DNS().SplitDNS(ctx)
+}
+
+// ACL retrieves the ACL that is currently set for the tailnet.
+func (c *Client) ACL(ctx context.Context) (*tsclient.ACL, error) {
+	return c.v2.PolicyFile().Get(ctx)
+}
+
+// RawACL retrieves the ACL that is currently set for the tailnet as a HuJSON string.
+func (c *Client) RawACL(ctx context.Context) (string, error) {
+	raw, err := c.v2.PolicyFile().Raw(ctx)
+	if err != nil {
+		return "", err
+	}
+	return raw.HuJSON, nil
+}
+
+type setACLParams struct {
+	etag string
+}
+
+// SetACLOption is a function that is used to modify a call to [Client.SetACL].
+type SetACLOption func(p *setACLParams)
+
+// WithETag sets the ETag used in the "If-Match" HTTP request header of [Client.SetACL].
+func WithETag(etag string) SetACLOption {
+	return func(p *setACLParams) {
+		p.etag = etag
+	}
+}
+
+// SetACL sets the ACL for the tailnet. acl can either be a [tsclient.ACL], or a HuJSON string.
+func (c *Client) SetACL(ctx context.Context, acl any, opts ...SetACLOption) error {
+	var p setACLParams
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return c.v2.PolicyFile().Set(ctx, acl, p.etag)
+}
+
+// ValidateACL validates the provided ACL via the API. acl can either be a [tsclient.ACL], or a HuJSON string.
+func (c *Client) ValidateACL(ctx context.Context, acl any) error {
+	return c.v2.PolicyFile().Validate(ctx, acl)
+}
+
+// DNSPreferences retrieves the DNS preferences that are currently set for the tailnet.
+func (c *Client) DNSPreferences(ctx context.Context) (*tsclient.DNSPreferences, error) {
+	return c.v2.DNS().Preferences(ctx)
+}
+
+// SetDNSPreferences replaces the DNS preferences for the tailnet.
+func (c *Client) SetDNSPreferences(ctx context.Context, preferences tsclient.DNSPreferences) error {
+	return c.v2.DNS().SetPreferences(ctx, preferences)
+}
+
+// SetDeviceSubnetRoutes replaces the enabled subnet routes of a device.
+func (c *Client) SetDeviceSubnetRoutes(ctx context.Context, deviceID string, routes []string) error {
+	return c.v2.Devices().SetSubnetRoutes(ctx, deviceID, routes)
+}
+
+// DeviceSubnetRoutes retrieves the subnet routes that a device is advertising and that are enabled for it.
+func (c *Client) DeviceSubnetRoutes(ctx context.Context, deviceID string) (*tsclient.DeviceRoutes, error) {
+	return c.v2.Devices().SubnetRoutes(ctx, deviceID)
+}
+
+// Devices lists the devices in the tailnet.
+func (c *Client) Devices(ctx context.Context) ([]tsclient.Device, error) {
+	return c.v2.Devices().List(ctx)
+}
+
+// AuthorizeDevice marks the specified device as authorized.
+func (c *Client) AuthorizeDevice(ctx context.Context, deviceID string) error {
+	return c.v2.Devices().SetAuthorized(ctx, deviceID, true)
+}
+
+// SetDeviceAuthorized marks the specified device as authorized or not.
+func (c *Client) SetDeviceAuthorized(ctx context.Context, deviceID string, authorized bool) error {
+	return c.v2.Devices().SetAuthorized(ctx, deviceID, authorized)
+}
+
+// DeleteDevice deletes the device given its deviceID.
+func (c *Client) DeleteDevice(ctx context.Context, deviceID string) error {
+	return c.v2.Devices().Delete(ctx, deviceID)
+}
+
+// CreateKeyOption is a function that is used to modify a [tsclient.CreateKeyRequest].
+type CreateKeyOption func(c *tsclient.CreateKeyRequest) error
+
+// WithKeyExpiry sets how long the key is valid for.
+func WithKeyExpiry(e time.Duration) CreateKeyOption {
+	return func(c *tsclient.CreateKeyRequest) error {
+		c.ExpirySeconds = int64(e.Seconds())
+		return nil
+	}
+}
+
+// WithKeyDescription sets the description for the key.
+func WithKeyDescription(desc string) CreateKeyOption {
+	return func(c *tsclient.CreateKeyRequest) error {
+		c.Description = desc
+		return nil
+	}
+}
+
+// CreateKey creates a new authentication key with the given capabilities.
+func (c *Client) CreateKey(ctx context.Context, capabilities tsclient.KeyCapabilities, opts ...CreateKeyOption) (tsclient.Key, error) {
+	ckr := tsclient.CreateKeyRequest{
+		Capabilities: capabilities,
+	}
+	for _, opt := range opts {
+		if err := opt(&ckr); err != nil {
+			return tsclient.Key{}, err
+		}
+	}
+
+	key, err := c.v2.Keys().Create(ctx, ckr)
+	if err != nil {
+		return tsclient.Key{}, err
+	}
+	return *key, nil
+}
+
+// GetKey returns the metadata of the key identified by id.
+func (c *Client) GetKey(ctx context.Context, id string) (tsclient.Key, error) {
+	key, err := c.v2.Keys().Get(ctx, id)
+	if err != nil {
+		return tsclient.Key{}, err
+	}
+	return *key, nil
+}
+
+// Keys returns the identifiers of the keys owned by the user of the API key used to authenticate.
+func (c *Client) Keys(ctx context.Context) ([]tsclient.Key, error) {
+	return c.v2.Keys().List(ctx, false)
+}
+
+// DeleteKey removes an authentication key from the tailnet.
+func (c *Client) DeleteKey(ctx context.Context, id string) error {
+	return c.v2.Keys().Delete(ctx, id)
+}
+
+// SetDeviceTags updates the tags of a target device.
+func (c *Client) SetDeviceTags(ctx context.Context, deviceID string, tags []string) error {
+	return c.v2.Devices().SetTags(ctx, deviceID, tags)
+}
+
+// SetDeviceKey updates the properties of a device's key.
+func (c *Client) SetDeviceKey(ctx context.Context, deviceID string, key tsclient.DeviceKey) error {
+	return c.v2.Devices().SetKey(ctx, deviceID, key)
+}
+
+// SetDeviceIPv4Address sets the Tailscale IPv4 address of the device.
+func (c *Client) SetDeviceIPv4Address(ctx context.Context, deviceID string, ipv4Address string) error {
+	return c.v2.Devices().SetIPv4Address(ctx, deviceID, ipv4Address)
+}
+
+// CreateWebhook creates a new webhook.
+func (c *Client) CreateWebhook(ctx context.Context, request tsclient.CreateWebhookRequest) (*tsclient.Webhook, error) {
+	return c.v2.Webhooks().Create(ctx, request)
+}
+
+// Webhooks lists the webhooks in the tailnet.
+func (c *Client) Webhooks(ctx context.Context) ([]tsclient.Webhook, error) {
+	return c.v2.Webhooks().List(ctx)
+}
+
+// Webhook retrieves a specific webhook.
+func (c *Client) Webhook(ctx context.Context, endpointID string) (*tsclient.Webhook, error) {
+	return c.v2.Webhooks().Get(ctx, endpointID)
+}
+
+// UpdateWebhook updates an existing webhook's subscriptions.
+func (c *Client) UpdateWebhook(ctx context.Context, endpointID string, subscriptions []tsclient.WebhookSubscriptionType) (*tsclient.Webhook, error) {
+	return c.v2.Webhooks().Update(ctx, endpointID, subscriptions)
+}
+
+// DeleteWebhook deletes a specific webhook.
+func (c *Client) DeleteWebhook(ctx context.Context, endpointID string) error {
+	return c.v2.Webhooks().Delete(ctx, endpointID)
+}
+
+// TestWebhook queues a test event to be sent to a specific webhook.
+func (c *Client) TestWebhook(ctx context.Context, endpointID string) error {
+	return c.v2.Webhooks().Test(ctx, endpointID)
+}
+
+// RotateWebhookSecret rotates the secret associated with a webhook.
+func (c *Client) RotateWebhookSecret(ctx context.Context, endpointID string) (*tsclient.Webhook, error) {
+	return c.v2.Webhooks().RotateSecret(ctx, endpointID)
+}
+
+// Contacts retrieves the contact information for the tailnet.
+func (c *Client) Contacts(ctx context.Context) (*tsclient.Contacts, error) {
+	return c.v2.Contacts().Get(ctx)
+}
+
+// UpdateContact updates the email for the specified contact type within the tailnet.
+func (c *Client) UpdateContact(ctx context.Context, contactType tsclient.ContactType, contact tsclient.UpdateContactRequest) error {
+	return c.v2.Contacts().Update(ctx, contactType, contact)
+}
+
+// IsNotFound returns true if the provided error is an API error with a status of 404.
+func IsNotFound(err error) bool {
+	return tsclient.IsNotFound(err)
+}
+
+// ErrorData returns the data of the API error err, if any.
+func ErrorData(err error) []tsclient.APIErrorData {
+	return tsclient.ErrorData(err)
+}
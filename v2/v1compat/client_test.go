@@ -0,0 +1,68 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package v1compat_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/v1compat"
+)
+
+func TestNewClient_RequiresCredentials(t *testing.T) {
+	t.Parallel()
+
+	_, err := v1compat.NewClient("", "example.com")
+	assert.EqualError(t, err, "no authentication credentials provided")
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	var method, path, ifMatch string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, ifMatch = r.Method, r.URL.Path, r.Header.Get("If-Match")
+		body, _ = io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/api/v2/tailnet/example.com/devices":
+			_ = json.NewEncoder(w).Encode(map[string][]tsclient.Device{"devices": {{ID: "test"}}})
+		case "/api/v2/tailnet/example.com/keys":
+			_ = json.NewEncoder(w).Encode(tsclient.Key{ID: "key"})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := v1compat.NewClient("not a real key", "example.com", v1compat.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	devices, err := client.Devices(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []tsclient.Device{{ID: "test"}}, devices)
+
+	assert.NoError(t, client.AuthorizeDevice(context.Background(), "test"))
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/api/v2/device/test/authorized", path)
+	assert.JSONEq(t, `{"authorized":true}`, string(body))
+
+	assert.NoError(t, client.SetACL(context.Background(), "{}", v1compat.WithETag("etag")))
+	assert.Equal(t, "/api/v2/tailnet/example.com/acl", path)
+	assert.Equal(t, `"etag"`, ifMatch)
+
+	key, err := client.CreateKey(context.Background(), tsclient.KeyCapabilities{}, v1compat.WithKeyDescription("description"))
+	assert.NoError(t, err)
+	assert.Equal(t, "key", key.ID)
+	var ckr tsclient.CreateKeyRequest
+	assert.NoError(t, json.Unmarshal(body, &ckr))
+	assert.Equal(t, "description", ckr.Description)
+
+	assert.Equal(t, "example.com", client.V2().Tailnet)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"context"
+	"time"
+)
+
+const defaultWatchInterval = time.Minute
+
+const (
+	DeviceEventAdded       DeviceEventType = "added"
+	DeviceEventRemoved     DeviceEventType = "removed"
+	DeviceEventAuthorized  DeviceEventType = "authorized"
+	DeviceEventTagsChanged DeviceEventType = "tagsChanged"
+	DeviceEventCameOnline  DeviceEventType = "cameOnline"
+)
+
+// DeviceEventType identifies the kind of change described by a [DeviceEvent].
+type DeviceEventType string
+
+// DeviceEvent describes a change to a device observed by [DevicesResource.Watch].
+type DeviceEvent struct {
+	Type DeviceEventType
+	// Device is the device after the change, or the last observed state of removed devices.
+	Device Device
+	// Previous is the device before the change. It is nil for added devices.
+	Previous *Device
+}
+
+// DeviceWatchOptions configures [DevicesResource.Watch].
+type DeviceWatchOptions struct {
+	// Interval is the time between two successive lists of devices. Defaults to 1 minute.
+	Interval time.Duration
+	// OnlineThreshold is the maximum time since a device was last seen for it to be considered
+	// online. Defaults to 5 minutes.
+	OnlineThreshold time.Duration
+	// OnError, if set, is called with the error of every list of devices that failed. Watching
+	// continues after errors.
+	OnError func(error)
+}
+
+// Watch lists devices every interval and emits an event on the returned channel for every change
+// between two successive lists. The first list only establishes the initial state, without
+// emitting events. Events of a single list are ordered by device ID.
+//
+// The channel is closed once ctx is done. Callers must receive events promptly, as listing is
+// paused while events are waiting to be received.
+func (dr *DevicesResource) Watch(ctx context.Context, opts DeviceWatchOptions) <-chan DeviceEvent {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	events := make(chan DeviceEvent)
+	go func() {
+		defer close(events)
+
+		var previous []Device
+		initialized := false
+		for {
+			devices, err := dr.List(ctx)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return
+			case err != nil:
+				if opts.OnError != nil {
+					opts.OnError(err)
+				}
+			case !initialized:
+				previous, initialized = devices, true
+			default:
+				for _, event := range deviceEvents(previous, devices, opts.OnlineThreshold) {
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				previous = devices
+			}
+
+			if err := sleep(ctx, interval); err != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// deviceEvents returns the events describing the changes from previous to current, both of which
+// are ordered by device ID.
+func deviceEvents(previous, current []Device, onlineThreshold time.Duration) []DeviceEvent {
+	byID := make(map[string]*Device, len(previous))
+	for i := range previous {
+		byID[previous[i].ID] = &previous[i]
+	}
+
+	var events []DeviceEvent
+	seen := make(map[string]bool, len(current))
+	for i := range current {
+		d := &current[i]
+		seen[d.ID] = true
+		prev, ok := byID[d.ID]
+		if !ok {
+			events = append(events, DeviceEvent{Type: DeviceEventAdded, Device: *d})
+			continue
+		}
+		if d.Authorized && !prev.Authorized {
+			events = append(events, DeviceEvent{Type: DeviceEventAuthorized, Device: *d, Previous: prev})
+		}
+		if !sameSet(d.Tags, prev.Tags) {
+			events = append(events, DeviceEvent{Type: DeviceEventTagsChanged, Device: *d, Previous: prev})
+		}
+		if deviceOnline(d, onlineThreshold) && !deviceOnline(prev, onlineThreshold) {
+			events = append(events, DeviceEvent{Type: DeviceEventCameOnline, Device: *d, Previous: prev})
+		}
+	}
+	for i := range previous {
+		if !seen[previous[i].ID] {
+			events = append(events, DeviceEvent{Type: DeviceEventRemoved, Device: previous[i], Previous: &previous[i]})
+		}
+	}
+	return events
+}
@@ -0,0 +1,69 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/internal/apitest"
+)
+
+func TestClient_Devices_Watch(t *testing.T) {
+	t.Parallel()
+
+	offline := tsclient.Time{Time: time.Now().Add(-time.Hour)}
+	initial := map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", LastSeen: offline},
+			{ID: "2", Tags: []string{"tag:a"}, LastSeen: offline},
+		},
+	}
+	changed := map[string][]tsclient.Device{
+		"devices": {
+			{ID: "1", Authorized: true, LastSeen: tsclient.Time{Time: time.Now()}},
+			{ID: "3"},
+		},
+	}
+
+	// The first list returns the initial devices, and every following list the changed devices.
+	var lists atomic.Int32
+	client := apitest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := changed
+		if lists.Add(1) == 1 {
+			body = initial
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.Devices().Watch(ctx, tsclient.DeviceWatchOptions{Interval: time.Millisecond})
+
+	var types []tsclient.DeviceEventType
+	var ids []string
+	for range 4 {
+		event := <-events
+		types = append(types, event.Type)
+		ids = append(ids, event.Device.ID)
+	}
+	assert.Equal(t, []tsclient.DeviceEventType{
+		tsclient.DeviceEventAuthorized,
+		tsclient.DeviceEventCameOnline,
+		tsclient.DeviceEventAdded,
+		tsclient.DeviceEventRemoved,
+	}, types)
+	assert.Equal(t, []string{"1", "1", "3", "2"}, ids)
+
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader is the header carrying the signature of webhook event deliveries.
+const WebhookSignatureHeader = "Tailscale-Webhook-Signature"
+
+// ErrInvalidWebhookSignature is returned by [VerifyWebhookSignature] when a signature does not
+// match the delivered events.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookEvent is a single event delivered to a webhook endpoint.
+type WebhookEvent struct {
+	Timestamp time.Time               `json:"timestamp"`
+	Version   int                     `json:"version"`
+	Type      WebhookSubscriptionType `json:"type"`
+	Tailnet   string                  `json:"tailnet"`
+	Message   string                  `json:"message"`
+	// Data contains details specific to the type of event.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// VerifyWebhookSignature verifies that signature, the value of the [WebhookSignatureHeader] of a
+// webhook delivery, was computed from body using secret, the secret of the receiving webhook.
+// Signatures older than maxAge are rejected to prevent replay attacks, unless maxAge is zero.
+func VerifyWebhookSignature(signature string, body []byte, secret string, maxAge time.Duration) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed signature header", ErrInvalidWebhookSignature)
+	}
+
+	if maxAge > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: malformed timestamp", ErrInvalidWebhookSignature)
+		}
+		if time.Since(time.Unix(unix, 0)) > maxAge {
+			return fmt.Errorf("%w: signature is older than %v", ErrInvalidWebhookSignature, maxAge)
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	for _, s := range signatures {
+		if actual, err := hex.DecodeString(s); err == nil && hmac.Equal(actual, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
+
+// ParseWebhookEvents parses the body of a webhook delivery, which contains one or more events.
+func ParseWebhookEvents(body []byte) ([]WebhookEvent, error) {
+	var events []WebhookEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
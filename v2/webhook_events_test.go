@@ -0,0 +1,45 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package tsclient_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+func signWebhook(secret string, t time.Time, body []byte) string {
+	timestamp := fmt.Sprint(t.Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`[{"timestamp":"2022-09-21T17:40:05Z","version":1,"type":"nodeCreated","tailnet":"example.com","message":"Node created","data":{"nodeID":"n1"}}]`)
+
+	assert.NoError(t, tsclient.VerifyWebhookSignature(signWebhook("secret", time.Now(), body), body, "secret", time.Minute))
+	assert.ErrorIs(t, tsclient.VerifyWebhookSignature(signWebhook("other", time.Now(), body), body, "secret", time.Minute), tsclient.ErrInvalidWebhookSignature)
+	assert.ErrorIs(t, tsclient.VerifyWebhookSignature(signWebhook("secret", time.Now().Add(-time.Hour), body), body, "secret", time.Minute), tsclient.ErrInvalidWebhookSignature)
+	assert.NoError(t, tsclient.VerifyWebhookSignature(signWebhook("secret", time.Now().Add(-time.Hour), body), body, "secret", 0))
+	assert.ErrorIs(t, tsclient.VerifyWebhookSignature("garbage", body, "secret", 0), tsclient.ErrInvalidWebhookSignature)
+
+	events, err := tsclient.ParseWebhookEvents(body)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, tsclient.WebhookNodeCreated, events[0].Type)
+	assert.Equal(t, "example.com", events[0].Tailnet)
+	assert.JSONEq(t, `{"nodeID":"n1"}`, string(events[0].Data))
+}
@@ -0,0 +1,193 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+// Package webhookarchive receives Tailscale webhook events and archives them to a pluggable
+// [Sink], allowing events to be retained beyond the retention period of Tailscale.
+//
+// An [Archiver] is an [http.Handler] to be used as the endpoint of a webhook. It verifies the
+// signature of every delivery, and only acknowledges a delivery once its events have been written
+// to the sink. Since Tailscale retries deliveries that were not acknowledged, every event is
+// archived at least once, but it may be archived several times.
+package webhookarchive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+)
+
+const defaultBatchSize = 100
+const defaultFlushInterval = time.Second
+const defaultMaxAge = 5 * time.Minute
+const maxBodySize = 10 << 20
+
+// Sink stores archived events. Implementations may write to files, object stores like S3, or
+// message queues like Kafka.
+type Sink interface {
+	// WriteEvents durably stores events. The events must not be considered archived before
+	// WriteEvents returns nil.
+	WriteEvents(ctx context.Context, events []tsclient.WebhookEvent) error
+}
+
+// SinkFunc adapts a function to a [Sink].
+type SinkFunc func(ctx context.Context, events []tsclient.WebhookEvent) error
+
+// WriteEvents implements [Sink].
+func (f SinkFunc) WriteEvents(ctx context.Context, events []tsclient.WebhookEvent) error {
+	return f(ctx, events)
+}
+
+// FileSink is a [Sink] appending events to a file as newline-delimited JSON.
+type FileSink struct {
+	// Path is the file to which events are appended. It is created if it does not exist.
+	Path string
+
+	mu sync.Mutex
+}
+
+// WriteEvents implements [Sink]. The file is synced to disk before returning.
+func (fs *FileSink) WriteEvents(ctx context.Context, events []tsclient.WebhookEvent) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := os.OpenFile(fs.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Archiver is an [http.Handler] receiving webhook deliveries and writing their events to Sink.
+// Events of concurrent deliveries are written to Sink in batches.
+type Archiver struct {
+	// Secret is the secret of the webhook, used to verify the signature of deliveries.
+	Secret string
+	// Sink is where events are archived.
+	Sink Sink
+	// BatchSize is the number of events after which a batch is written. Defaults to 100.
+	BatchSize int
+	// FlushInterval is the maximum time events wait for a batch to fill up. Defaults to 1 second.
+	FlushInterval time.Duration
+	// MaxAge is the maximum age of delivery signatures. Defaults to 5 minutes.
+	MaxAge time.Duration
+
+	mu    sync.Mutex
+	batch *batch
+}
+
+// batch is a set of events written to the sink together. done is closed once the write completed,
+// after which err holds its result.
+type batch struct {
+	events []tsclient.WebhookEvent
+	once   sync.Once
+	timer  *time.Timer
+	done   chan struct{}
+	err    error
+}
+
+// ServeHTTP implements [http.Handler]. It responds with 200 OK once the delivered events have been
+// archived, 401 Unauthorized if the signature of the delivery is invalid, and 500 Internal Server
+// Error if the events could not be archived, causing Tailscale to retry the delivery.
+func (a *Archiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	maxAge := a.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultMaxAge
+	}
+	if err := tsclient.VerifyWebhookSignature(r.Header.Get(tsclient.WebhookSignatureHeader), body, a.Secret, maxAge); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	events, err := tsclient.ParseWebhookEvents(body)
+	if err != nil {
+		http.Error(w, "invalid events", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.archive(r.Context(), events); err != nil {
+		http.Error(w, "failed to archive events", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// archive adds events to the current batch and waits until the batch has been written.
+func (a *Archiver) archive(ctx context.Context, events []tsclient.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batchSize := a.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	flushInterval := a.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	a.mu.Lock()
+	b := a.batch
+	if b == nil {
+		b = &batch{done: make(chan struct{})}
+		b.timer = time.AfterFunc(flushInterval, func() { a.flush(b) })
+		a.batch = b
+	}
+	b.events = append(b.events, events...)
+	full := len(b.events) >= batchSize
+	a.mu.Unlock()
+
+	if full {
+		go a.flush(b)
+	}
+
+	select {
+	case <-b.done:
+		return b.err
+	case <-ctx.Done():
+		return errors.Join(errors.New("delivery abandoned before events were archived"), ctx.Err())
+	}
+}
+
+// flush writes b to the sink, once.
+func (a *Archiver) flush(b *batch) {
+	b.once.Do(func() {
+		// Detach the batch, so that no more events are added to it while it is written.
+		a.mu.Lock()
+		if a.batch == b {
+			a.batch = nil
+		}
+		b.timer.Stop()
+		a.mu.Unlock()
+
+		b.err = a.Sink.WriteEvents(context.Background(), b.events)
+		close(b.done)
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright (c) David Bond, Tailscale Inc, & Contributors
+// SPDX-License-Identifier: MIT
+
+package webhookarchive_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tsclient "github.com/tailscale/tailscale-client-go/v2"
+	"github.com/tailscale/tailscale-client-go/v2/webhookarchive"
+)
+
+func deliver(t *testing.T, handler http.Handler, secret, body string) int {
+	t.Helper()
+
+	timestamp := fmt.Sprint(time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(tsclient.WebhookSignatureHeader, "t="+timestamp+",v1="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestArchiver_FileSink(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	archiver := &webhookarchive.Archiver{
+		Secret:        "secret",
+		Sink:          &webhookarchive.FileSink{Path: path},
+		BatchSize:     2,
+		FlushInterval: 10 * time.Millisecond,
+	}
+
+	var wg sync.WaitGroup
+	for i := range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`[{"type":"nodeCreated","tailnet":"example.com","message":"event %d"}]`, i)
+			assert.Equal(t, http.StatusOK, deliver(t, archiver, "secret", body))
+		}()
+	}
+	wg.Wait()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var messages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event tsclient.WebhookEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		messages = append(messages, event.Message)
+	}
+	assert.ElementsMatch(t, []string{"event 0", "event 1", "event 2"}, messages)
+}
+
+func TestArchiver_Errors(t *testing.T) {
+	t.Parallel()
+
+	archiver := &webhookarchive.Archiver{
+		Secret: "secret",
+		Sink: webhookarchive.SinkFunc(func(ctx context.Context, events []tsclient.WebhookEvent) error {
+			return errors.New("unavailable")
+		}),
+		FlushInterval: time.Millisecond,
+	}
+
+	body := `[{"type":"nodeCreated"}]`
+	assert.Equal(t, http.StatusUnauthorized, deliver(t, archiver, "wrong", body))
+	// Failing to archive events must not acknowledge the delivery, so that it is retried.
+	assert.Equal(t, http.StatusInternalServerError, deliver(t, archiver, "secret", body))
+}
@@ -5,6 +5,7 @@ package tsclient
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -80,8 +81,8 @@ type CreateWebhookRequest struct {
 
 // Create creates a new [Webhook] with the specifications provided in the [CreateWebhookRequest].
 // Returns the created [Webhook] if successful.
-func (wr *WebhooksResource) Create(ctx context.Context, request CreateWebhookRequest) (*Webhook, error) {
-	req, err := wr.buildRequest(ctx, http.MethodPost, wr.buildTailnetURL("webhooks"), requestBody(request))
+func (wr *WebhooksResource) Create(ctx context.Context, request CreateWebhookRequest, opts ...WriteOption) (*Webhook, error) {
+	req, err := wr.buildRequest(ctx, http.MethodPost, wr.buildTailnetURL("webhooks"), requestBody(request), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -89,9 +90,9 @@ func (wr *WebhooksResource) Create(ctx context.Context, request CreateWebhookReq
 	return body[Webhook](wr, req)
 }
 
-// List lists every [Webhook] in the tailnet.
-func (wr *WebhooksResource) List(ctx context.Context) ([]Webhook, error) {
-	req, err := wr.buildRequest(ctx, http.MethodGet, wr.buildTailnetURL("webhooks"))
+// List lists every [Webhook] in the tailnet, ordered by endpoint ID.
+func (wr *WebhooksResource) List(ctx context.Context, opts ...ListOption) ([]Webhook, error) {
+	req, err := wr.buildRequest(ctx, http.MethodGet, wr.buildTailnetURL("webhooks"), listOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -101,12 +102,12 @@ func (wr *WebhooksResource) List(ctx context.Context) ([]Webhook, error) {
 		return nil, err
 	}
 
-	return resp["webhooks"], nil
+	return sortByID(resp["webhooks"], func(w Webhook) string { return w.EndpointID }), nil
 }
 
 // Get retrieves a specific [Webhook].
-func (wr *WebhooksResource) Get(ctx context.Context, endpointID string) (*Webhook, error) {
-	req, err := wr.buildRequest(ctx, http.MethodGet, wr.buildURL("webhooks", endpointID))
+func (wr *WebhooksResource) Get(ctx context.Context, endpointID string, opts ...GetOption) (*Webhook, error) {
+	req, err := wr.buildRequest(ctx, http.MethodGet, wr.buildURL("webhooks", endpointID), getOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -115,10 +116,10 @@ func (wr *WebhooksResource) Get(ctx context.Context, endpointID string) (*Webhoo
 }
 
 // Update updates an existing webhook's subscriptions. Returns the updated [Webhook] on success.
-func (wr *WebhooksResource) Update(ctx context.Context, endpointID string, subscriptions []WebhookSubscriptionType) (*Webhook, error) {
+func (wr *WebhooksResource) Update(ctx context.Context, endpointID string, subscriptions []WebhookSubscriptionType, opts ...WriteOption) (*Webhook, error) {
 	req, err := wr.buildRequest(ctx, http.MethodPatch, wr.buildURL("webhooks", endpointID), requestBody(map[string][]WebhookSubscriptionType{
 		"subscriptions": subscriptions,
-	}))
+	}), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -127,8 +128,8 @@ func (wr *WebhooksResource) Update(ctx context.Context, endpointID string, subsc
 }
 
 // Delete deletes a specific webhook.
-func (wr *WebhooksResource) Delete(ctx context.Context, endpointID string) error {
-	req, err := wr.buildRequest(ctx, http.MethodDelete, wr.buildURL("webhooks", endpointID))
+func (wr *WebhooksResource) Delete(ctx context.Context, endpointID string, opts ...WriteOption) error {
+	req, err := wr.buildRequest(ctx, http.MethodDelete, wr.buildURL("webhooks", endpointID), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -138,9 +139,10 @@ func (wr *WebhooksResource) Delete(ctx context.Context, endpointID string) error
 
 // Test queues a test event to be sent to a specific webhook.
 // Sending the test event is an asynchronous operation which will
-// typically happen a few seconds after using this method.
-func (wr *WebhooksResource) Test(ctx context.Context, endpointID string) error {
-	req, err := wr.buildRequest(ctx, http.MethodPost, wr.buildURL("webhooks", endpointID, "test"))
+// typically happen a few seconds after using this method. Use [Client.StartAsync]
+// to obtain a handle to the operation confirming that the API accepted the test event.
+func (wr *WebhooksResource) Test(ctx context.Context, endpointID string, opts ...WriteOption) error {
+	req, err := wr.buildRequest(ctx, http.MethodPost, wr.buildURL("webhooks", endpointID, "test"), writeOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -150,11 +152,37 @@ func (wr *WebhooksResource) Test(ctx context.Context, endpointID string) error {
 
 // RotateSecret rotates the secret associated with a webhook.
 // A new secret will be generated and set on the returned [Webhook].
-func (wr *WebhooksResource) RotateSecret(ctx context.Context, endpointID string) (*Webhook, error) {
-	req, err := wr.buildRequest(ctx, http.MethodPost, wr.buildURL("webhooks", endpointID, "rotate"))
+func (wr *WebhooksResource) RotateSecret(ctx context.Context, endpointID string, opts ...WriteOption) (*Webhook, error) {
+	req, err := wr.buildRequest(ctx, http.MethodPost, wr.buildURL("webhooks", endpointID, "rotate"), writeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
 
 	return body[Webhook](wr, req)
 }
+
+// RotateAll rotates the secret of every webhook in the tailnet, calling distribute with each
+// webhook and its new secret, for example to update the configuration of the receiving endpoint.
+// Webhooks are rotated one at a time.
+//
+// RotateAll returns the webhooks of the tailnet. If rotating a secret or distributing it failed for
+// some webhooks, it also returns a [BatchError] whose indexes refer to the returned webhooks. Note
+// that when distribute fails, the secret has already been rotated.
+func (wr *WebhooksResource) RotateAll(ctx context.Context, distribute func(webhook Webhook, newSecret string) error) ([]Webhook, error) {
+	webhooks, err := wr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = Batch(ctx, webhooks, BatchOptions{Concurrency: 1}, func(ctx context.Context, webhook Webhook) error {
+		rotated, err := wr.RotateSecret(ctx, webhook.EndpointID)
+		if err != nil {
+			return err
+		}
+		if rotated.Secret == nil {
+			return fmt.Errorf("no secret returned when rotating webhook %s", webhook.EndpointID)
+		}
+		return distribute(*rotated, *rotated.Secret)
+	})
+	return webhooks, err
+}
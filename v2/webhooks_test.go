@@ -5,6 +5,7 @@ package tsclient_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -156,6 +157,19 @@ func TestClient_TestWebhook(t *testing.T) {
 	assert.Equal(t, "/api/v2/webhooks/54321/test", server.Path)
 }
 
+func TestClient_TestWebhook_ResponseInfo(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusAccepted
+
+	var info tsclient.ResponseInfo
+	err := client.Webhooks().Test(tsclient.WithResponseInfo(context.Background(), &info), "54321")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, info.StatusCode)
+	assert.True(t, info.Accepted())
+}
+
 func TestClient_RotateWebhookSecret(t *testing.T) {
 	t.Parallel()
 
@@ -181,3 +195,33 @@ func TestClient_RotateWebhookSecret(t *testing.T) {
 	assert.Equal(t, "/api/v2/webhooks/54321/rotate", server.Path)
 	assert.Equal(t, expectedWebhook, actualWebhook)
 }
+
+func TestClient_RotateAllWebhookSecrets(t *testing.T) {
+	t.Parallel()
+
+	client, server := NewTestHarness(t)
+	server.ResponseCode = http.StatusOK
+	server.ResponseBodies = map[string]interface{}{
+		"/api/v2/tailnet/example.com/webhooks": map[string][]tsclient.Webhook{
+			"webhooks": {{EndpointID: "1"}, {EndpointID: "2"}},
+		},
+		"/api/v2/webhooks/1/rotate": tsclient.Webhook{EndpointID: "1", Secret: tsclient.PointerTo("secret-1")},
+		"/api/v2/webhooks/2/rotate": tsclient.Webhook{EndpointID: "2", Secret: tsclient.PointerTo("secret-2")},
+	}
+
+	distributed := make(map[string]string)
+	webhooks, err := client.Webhooks().RotateAll(context.Background(), func(webhook tsclient.Webhook, newSecret string) error {
+		distributed[webhook.EndpointID] = newSecret
+		if webhook.EndpointID == "2" {
+			return errors.New("failed to distribute")
+		}
+		return nil
+	})
+	assert.Len(t, webhooks, 2)
+	assert.Equal(t, map[string]string{"1": "secret-1", "2": "secret-2"}, distributed)
+
+	var batchErr tsclient.BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Errors, 1)
+	assert.ErrorContains(t, batchErr.Errors[1], "failed to distribute")
+}